@@ -0,0 +1,206 @@
+package gonl
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"sync"
+)
+
+// ErrQueueFull is returned by an AsyncLineWriter using the QueueError
+// policy when its queue has no room for a line.
+var ErrQueueFull = errors.New("gonl: queue full")
+
+// QueuePolicy determines what an AsyncLineWriter does with a line
+// written to it when its queue is full.
+type QueuePolicy int
+
+const (
+	// QueueBlock causes Write to wait until there is room in the
+	// queue.
+	QueueBlock QueuePolicy = iota
+
+	// QueueDropOldest discards the oldest line in the queue to make
+	// room for the new line.
+	QueueDropOldest
+
+	// QueueDropNewest discards the new line.
+	QueueDropNewest
+
+	// QueueError causes Write to return ErrQueueFull, along with the
+	// number of bytes preceding the line that did not fit.
+	QueueError
+)
+
+// AsyncLineWriter is an io.WriteCloser that queues the complete lines
+// written to it, up to a bounded number of lines, and writes them to
+// the underlying io.WriteCloser from a background goroutine, so bursty
+// producers are not slowed down by a slow destination. Whatever lines
+// are queued each time the goroutine is ready are written with a
+// single Write. When the queue is full, lines are handled according
+// to its QueuePolicy. A trailing partial line is held until its
+// newline arrives, or until Close.
+//
+// Write may be called concurrently by multiple goroutines, provided
+// each call writes complete lines, otherwise partial lines from
+// different goroutines would be joined. Once a Write to the underlying
+// io.WriteCloser fails, queued lines are discarded, and every later
+// call returns that error.
+//
+//	aw, err := gonl.NewAsyncLineWriter(lw, 10000, gonl.QueueDropOldest)
+type AsyncLineWriter struct {
+	wc       io.WriteCloser
+	maxLines int
+	policy   QueuePolicy
+
+	mu       sync.Mutex
+	notEmpty sync.Cond
+	notFull  sync.Cond
+	la       lineAssembler
+	queue    [][]byte
+	dropped  int64
+	err      error // first error writing to wc
+	closed   bool
+	done     chan struct{}
+}
+
+// NewAsyncLineWriter returns a new AsyncLineWriter that queues up to
+// maxLines lines to be written to wc, and starts its background
+// goroutine, which stops upon Close.
+func NewAsyncLineWriter(wc io.WriteCloser, maxLines int, policy QueuePolicy) (*AsyncLineWriter, error) {
+	if maxLines <= 0 {
+		return nil, fmt.Errorf("cannot create AsyncLineWriter when maxLines less than or equal to 0: %d", maxLines)
+	}
+	aw := &AsyncLineWriter{wc: wc, maxLines: maxLines, policy: policy, done: make(chan struct{})}
+	aw.notEmpty.L = &aw.mu
+	aw.notFull.L = &aw.mu
+	go aw.drain()
+	return aw, nil
+}
+
+// Close queues any partial line, waits for all queued lines to be
+// written, then closes the underlying io.WriteCloser. It returns the
+// first error writing to the underlying io.WriteCloser, if any, or the
+// error from closing it. Calling Close more than once returns
+// ErrClosed.
+func (aw *AsyncLineWriter) Close() error {
+	aw.mu.Lock()
+	if aw.closed {
+		aw.mu.Unlock()
+		return ErrClosed
+	}
+	_ = aw.la.close(aw.enqueue) // only fails with QueueError
+	aw.closed = true
+	aw.notEmpty.Signal()
+	aw.notFull.Broadcast()
+	aw.mu.Unlock()
+
+	<-aw.done
+
+	err := aw.err
+	if cerr := aw.wc.Close(); err == nil {
+		err = cerr
+	}
+	return err
+}
+
+// Dropped returns the number of lines discarded because the queue was
+// full.
+func (aw *AsyncLineWriter) Dropped() int64 {
+	aw.mu.Lock()
+	defer aw.mu.Unlock()
+	return aw.dropped
+}
+
+// Queued returns the number of lines waiting to be written.
+func (aw *AsyncLineWriter) Queued() int {
+	aw.mu.Lock()
+	defer aw.mu.Unlock()
+	return len(aw.queue)
+}
+
+// Write queues each line completed by p, returning the number of bytes
+// of p consumed, including the bytes of lines dropped because the
+// queue was full. After Close has been called, it returns ErrClosed.
+func (aw *AsyncLineWriter) Write(p []byte) (int, error) {
+	aw.mu.Lock()
+	defer aw.mu.Unlock()
+
+	if aw.closed {
+		return 0, ErrClosed
+	}
+	if aw.err != nil {
+		return 0, aw.err
+	}
+	return aw.la.write(p, aw.enqueue)
+}
+
+// enqueue adds a copy of line to the queue, applying the QueuePolicy
+// when the queue is full. It is called with mu held.
+func (aw *AsyncLineWriter) enqueue(line []byte) error {
+	if aw.err != nil {
+		return aw.err
+	}
+	for len(aw.queue) >= aw.maxLines {
+		switch aw.policy {
+		case QueueDropOldest:
+			aw.queue[0] = nil
+			aw.queue = aw.queue[1:]
+			aw.dropped++
+		case QueueDropNewest:
+			aw.dropped++
+			return nil
+		case QueueError:
+			return ErrQueueFull
+		default:
+			aw.notFull.Wait()
+			if aw.err != nil {
+				return aw.err
+			}
+		}
+	}
+	aw.queue = append(aw.queue, append([]byte(nil), line...))
+	aw.notEmpty.Signal()
+	return nil
+}
+
+// drain is the background goroutine, which writes all queued lines
+// with each Write to the underlying io.WriteCloser until closed.
+func (aw *AsyncLineWriter) drain() {
+	defer close(aw.done)
+	var batch []byte
+
+	aw.mu.Lock()
+	for {
+		for len(aw.queue) == 0 && !aw.closed {
+			aw.notEmpty.Wait()
+		}
+		if len(aw.queue) == 0 {
+			aw.mu.Unlock()
+			return // closed
+		}
+
+		batch = batch[:0]
+		for i, line := range aw.queue {
+			batch = append(batch, line...)
+			aw.queue[i] = nil
+		}
+		aw.queue = aw.queue[:0]
+		aw.notFull.Broadcast()
+		aw.mu.Unlock()
+
+		nw, err := aw.wc.Write(batch)
+		if err == nil && nw < len(batch) {
+			err = io.ErrShortWrite
+		}
+
+		aw.mu.Lock()
+		if err != nil {
+			aw.err = err
+			aw.queue = nil
+			aw.notFull.Broadcast()
+			aw.mu.Unlock()
+			return
+		}
+	}
+}
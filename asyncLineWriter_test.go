@@ -0,0 +1,140 @@
+package gonl
+
+import (
+	"sync"
+	"testing"
+)
+
+// gateWriter is an io.WriteCloser whose Write calls block until
+// released, recording what is written.
+type gateWriter struct {
+	mu      sync.Mutex
+	writes  []string
+	started chan struct{}
+	release chan struct{}
+}
+
+func newGateWriter() *gateWriter {
+	return &gateWriter{started: make(chan struct{}, 100), release: make(chan struct{})}
+}
+
+func (gw *gateWriter) Close() error { return nil }
+
+func (gw *gateWriter) Write(p []byte) (int, error) {
+	gw.started <- struct{}{}
+	<-gw.release
+	gw.mu.Lock()
+	defer gw.mu.Unlock()
+	gw.writes = append(gw.writes, string(p))
+	return len(p), nil
+}
+
+func TestAsyncLineWriter(t *testing.T) {
+	t.Run("writes queued lines in batches", func(t *testing.T) {
+		gw := newGateWriter()
+		close(gw.release)
+		aw, err := NewAsyncLineWriter(gw, 10, QueueBlock)
+		ensureErrorNil(t, err)
+
+		ensureWrite(t, aw, "line 1\nli")
+		ensureWrite(t, aw, "ne 2\nline 3")
+		ensureErrorNil(t, aw.Close())
+
+		var all string
+		for _, w := range gw.writes {
+			all += w
+		}
+		if got, want := all, "line 1\nline 2\nline 3"; got != want {
+			t.Errorf("GOT: %q; WANT: %q", got, want)
+		}
+		ensureError(t, aw.Close(), "already closed")
+		_, err = aw.Write([]byte("line 4\n"))
+		ensureError(t, err, "already closed")
+	})
+
+	for _, tc := range []struct {
+		name    string
+		policy  QueuePolicy
+		want    []string
+		dropped int64
+	}{
+		{"drop oldest", QueueDropOldest, []string{"line 1\n", "line 3\nline 4\n"}, 1},
+		{"drop newest", QueueDropNewest, []string{"line 1\n", "line 2\nline 3\n"}, 1},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			gw := newGateWriter()
+			aw, err := NewAsyncLineWriter(gw, 2, tc.policy)
+			ensureErrorNil(t, err)
+
+			ensureWrite(t, aw, "line 1\n")
+			<-gw.started // line 1 is being written
+			ensureWrite(t, aw, "line 2\nline 3\nline 4\n")
+			if got, want := aw.Queued(), 2; got != want {
+				t.Errorf("GOT: %v; WANT: %v", got, want)
+			}
+			close(gw.release)
+			ensureErrorNil(t, aw.Close())
+
+			ensureWrites(t, gw.writes, tc.want)
+			if got, want := aw.Dropped(), tc.dropped; got != want {
+				t.Errorf("GOT: %v; WANT: %v", got, want)
+			}
+		})
+	}
+
+	t.Run("error when full", func(t *testing.T) {
+		gw := newGateWriter()
+		aw, err := NewAsyncLineWriter(gw, 1, QueueError)
+		ensureErrorNil(t, err)
+
+		ensureWrite(t, aw, "line 1\n")
+		<-gw.started
+		n, err := aw.Write([]byte("line 2\nline 3\n"))
+		if got, want := err, ErrQueueFull; got != want {
+			t.Errorf("GOT: %v; WANT: %v", got, want)
+		}
+		if got, want := n, 7; got != want {
+			t.Errorf("GOT: %v; WANT: %v", got, want)
+		}
+		close(gw.release)
+		ensureErrorNil(t, aw.Close())
+		ensureWrites(t, gw.writes, []string{"line 1\n", "line 2\n"})
+	})
+
+	t.Run("block when full", func(t *testing.T) {
+		gw := newGateWriter()
+		aw, err := NewAsyncLineWriter(gw, 1, QueueBlock)
+		ensureErrorNil(t, err)
+
+		ensureWrite(t, aw, "line 1\n")
+		<-gw.started
+		done := make(chan struct{})
+		go func() {
+			defer close(done)
+			ensureWrite(t, aw, "line 2\nline 3\n") // blocks on line 3
+		}()
+		close(gw.release)
+		<-done
+		ensureErrorNil(t, aw.Close())
+
+		var all string
+		for _, w := range gw.writes {
+			all += w
+		}
+		if got, want := all, "line 1\nline 2\nline 3\n"; got != want {
+			t.Errorf("GOT: %q; WANT: %q", got, want)
+		}
+	})
+
+	t.Run("write error", func(t *testing.T) {
+		aw, err := NewAsyncLineWriter(&errOnWrite{}, 10, QueueBlock)
+		ensureErrorNil(t, err)
+		ensureWrite(t, aw, "line 1\n")
+		ensureError(t, aw.Close(), "test write error")
+	})
+
+	t.Run("invalid", func(t *testing.T) {
+		_, err := NewAsyncLineWriter(new(testBuffer), 0, QueueBlock)
+		ensureError(t, err, "maxLines less than or equal to 0")
+	})
+}
@@ -0,0 +1,145 @@
+package gonl
+
+import (
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+)
+
+// ErrAborted is returned by AtomicFileLineWriter.Close after Abort.
+var ErrAborted = errors.New("gonl: atomic file aborted")
+
+// AtomicFileLineWriter is an io.WriteCloser that batches lines like a
+// BatchLineWriter, writing them to a temporary file in the directory of
+// the target file, which it renames over the target when it is closed
+// successfully. Consumers of the target file therefore never observe a
+// partially written file: they see either its previous contents or the
+// complete new contents. When any write fails, or Abort is invoked, the
+// temporary file is removed instead, and the target is left unchanged.
+//
+//	aw, err := gonl.NewAtomicFileLineWriter("report.csv")
+//	if err != nil {
+//		return err
+//	}
+//	defer aw.Abort() // does nothing after a successful Close
+//	if _, err = io.Copy(aw, r); err != nil {
+//		return err
+//	}
+//	return aw.Close()
+type AtomicFileLineWriter struct {
+	lw   *BatchLineWriter
+	af   *atomicFile
+	path string
+	done bool
+}
+
+// NewAtomicFileLineWriter returns a new AtomicFileLineWriter that
+// replaces the file at path when closed, writing through a
+// BatchLineWriter configured by the specified options, as
+// NewBatchLineWriterOptions does. The temporary file is created
+// immediately, with the permissions of the target when it exists, and
+// 0644 otherwise.
+func NewAtomicFileLineWriter(path string, options ...Option) (*AtomicFileLineWriter, error) {
+	perm := os.FileMode(0644)
+	if fi, err := os.Stat(path); err == nil {
+		perm = fi.Mode().Perm()
+	}
+	f, err := os.CreateTemp(filepath.Dir(path), "."+filepath.Base(path)+".tmp*")
+	if err != nil {
+		return nil, err
+	}
+	af := &atomicFile{f: f}
+	if err = f.Chmod(perm); err == nil {
+		var lw *BatchLineWriter
+		if lw, err = NewBatchLineWriterOptions(af, options...); err == nil {
+			return &AtomicFileLineWriter{lw: lw, af: af, path: path}, nil
+		}
+	}
+	_ = f.Close()
+	_ = os.Remove(f.Name())
+	return nil, err
+}
+
+// Abort discards the lines written, removing the temporary file
+// without replacing the target. It does nothing after Close or a
+// previous Abort.
+func (aw *AtomicFileLineWriter) Abort() error {
+	if aw.done {
+		return nil
+	}
+	aw.done = true
+	aw.af.aborted = true
+	_ = aw.lw.Close() // buffered lines are discarded by af
+	return os.Remove(aw.af.f.Name())
+}
+
+// Close flushes any buffered lines, syncs the temporary file to stable
+// storage, then renames it over the target. When that fails, or any
+// earlier write failed, it removes the temporary file instead and
+// returns the error. After Abort, it returns ErrAborted.
+func (aw *AtomicFileLineWriter) Close() error {
+	if aw.done {
+		if aw.af.aborted {
+			return ErrAborted
+		}
+		return ErrClosed
+	}
+	aw.done = true
+
+	err := aw.lw.Close()
+	if err == nil {
+		err = aw.af.err
+	}
+	if err == nil {
+		err = os.Rename(aw.af.f.Name(), aw.path)
+	}
+	if err != nil {
+		_ = os.Remove(aw.af.f.Name())
+	}
+	return err
+}
+
+// ReadFrom reads data from r until io.EOF or error, as
+// BatchLineWriter.ReadFrom does.
+func (aw *AtomicFileLineWriter) ReadFrom(r io.Reader) (int64, error) {
+	return aw.lw.ReadFrom(r)
+}
+
+// Write writes p, as BatchLineWriter.Write does.
+func (aw *AtomicFileLineWriter) Write(p []byte) (int, error) {
+	return aw.lw.Write(p)
+}
+
+// atomicFile is the temporary file written by an AtomicFileLineWriter.
+// It records the first error writing it, and discards writes once
+// aborted.
+type atomicFile struct {
+	f       *os.File
+	err     error
+	aborted bool
+}
+
+// Close syncs the file to stable storage, unless aborted, then closes
+// it.
+func (af *atomicFile) Close() error {
+	var err error
+	if !af.aborted {
+		err = af.f.Sync()
+	}
+	if cerr := af.f.Close(); err == nil {
+		err = cerr
+	}
+	return err
+}
+
+func (af *atomicFile) Write(p []byte) (int, error) {
+	if af.aborted {
+		return len(p), nil
+	}
+	n, err := af.f.Write(p)
+	if err != nil && af.err == nil {
+		af.err = err
+	}
+	return n, err
+}
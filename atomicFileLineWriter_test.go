@@ -0,0 +1,80 @@
+package gonl
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// ensureDirNames ensures dir contains exactly the specified files.
+func ensureDirNames(t *testing.T, dir string, want ...string) {
+	t.Helper()
+	entries, err := os.ReadDir(dir)
+	ensureErrorNil(t, err)
+	var got []string
+	for _, entry := range entries {
+		got = append(got, entry.Name())
+	}
+	ensureWrites(t, got, want)
+}
+
+func TestAtomicFileLineWriter(t *testing.T) {
+	t.Run("replaces target on close", func(t *testing.T) {
+		dir := t.TempDir()
+		path := filepath.Join(dir, "report.txt")
+		ensureErrorNil(t, ioutil.WriteFile(path, []byte("old\n"), 0600))
+
+		aw, err := NewAtomicFileLineWriter(path, WithFlushThreshold(4))
+		ensureErrorNil(t, err)
+		ensureWrite(t, aw, "line 1\nline 2\n")
+		_, err = aw.ReadFrom(strings.NewReader("line 3\nline 4"))
+		ensureErrorNil(t, err)
+		ensureFile(t, path, "old\n") // not yet replaced
+
+		ensureErrorNil(t, aw.Close())
+		ensureFile(t, path, "line 1\nline 2\nline 3\nline 4")
+		ensureDirNames(t, dir, "report.txt")
+
+		fi, err := os.Stat(path)
+		ensureErrorNil(t, err)
+		if got, want := fi.Mode().Perm(), os.FileMode(0600); got != want {
+			t.Errorf("GOT: %v; WANT: %v", got, want)
+		}
+		ensureErrorNil(t, aw.Abort()) // does nothing after Close
+		ensureError(t, aw.Close(), "already closed")
+	})
+
+	t.Run("abort", func(t *testing.T) {
+		dir := t.TempDir()
+		path := filepath.Join(dir, "report.txt")
+
+		aw, err := NewAtomicFileLineWriter(path, WithFlushThreshold(4))
+		ensureErrorNil(t, err)
+		ensureWrite(t, aw, "line 1\nline 2")
+		ensureErrorNil(t, aw.Abort())
+		ensureDirNames(t, dir)
+		if got, want := aw.Close(), ErrAborted; got != want {
+			t.Errorf("GOT: %v; WANT: %v", got, want)
+		}
+	})
+
+	t.Run("write error", func(t *testing.T) {
+		dir := t.TempDir()
+		path := filepath.Join(dir, "report.txt")
+
+		aw, err := NewAtomicFileLineWriter(path, WithFlushThreshold(4))
+		ensureErrorNil(t, err)
+		ensureErrorNil(t, aw.af.f.Close()) // cause writes to fail
+		_, err = aw.Write([]byte("line 1\n"))
+		ensureError(t, err, "file already closed")
+		ensureError(t, aw.Close(), "file already closed")
+		ensureDirNames(t, dir)
+	})
+
+	t.Run("missing directory", func(t *testing.T) {
+		_, err := NewAtomicFileLineWriter(filepath.Join(t.TempDir(), "missing", "report.txt"))
+		ensureError(t, err, "no such file or directory")
+	})
+}
@@ -0,0 +1,70 @@
+package gonl
+
+import (
+	"errors"
+	"fmt"
+	"io"
+)
+
+// BatchLineReader is the read side counterpart of BatchLineWriter. It
+// reads from the source io.Reader in chunks of at least size bytes,
+// and each Read fills the caller's buffer with as many complete
+// newline terminated lines as fit, never ending in the middle of a
+// line except when a single line is longer than the caller's buffer,
+// or when the final bytes from the source io.Reader are not
+// terminated by a newline.
+//
+//	br, err := gonl.NewBatchLineReader(conn, 64*1024)
+//	if err != nil {
+//		return err
+//	}
+//	n, err := br.Read(buf) // buf[:n] ends with a newline
+type BatchLineReader struct {
+	lr LineBufferedReader
+}
+
+// NewBatchLineReader returns a new BatchLineReader that reads from r
+// in chunks of at least size bytes.
+func NewBatchLineReader(r io.Reader, size int) (*BatchLineReader, error) {
+	return NewBatchLineReaderDelim(r, size, newline)
+}
+
+// NewBatchLineReaderDelim returns a new BatchLineReader that reads
+// from r in chunks of at least size bytes, and returns data on
+// boundaries of the specified delimiter rather than of newlines, such
+// as NUL for records produced by `find -print0`.
+func NewBatchLineReaderDelim(r io.Reader, size int, delim []byte) (*BatchLineReader, error) {
+	if len(delim) == 0 {
+		return nil, errors.New("cannot create BatchLineReader when delimiter is empty")
+	}
+	if size <= 0 {
+		return nil, fmt.Errorf("cannot create BatchLineReader when size less than or equal to 0: %d", size)
+	}
+	return &BatchLineReader{lr: LineBufferedReader{
+		R:     r,
+		Delim: append([]byte(nil), delim...),
+		rb: readBuffer{
+			buf:      make([]byte, 0, size),
+			readSize: size,
+		},
+	}}, nil
+}
+
+// Read reads up to len(p) bytes into p, ending on a newline boundary
+// unless a single line is longer than p or the source io.Reader has
+// returned an error. It returns the number of bytes read (0 <= n <=
+// len(p)) and any error encountered.
+func (br *BatchLineReader) Read(p []byte) (int, error) {
+	return br.lr.Read(p)
+}
+
+// WriteTo writes data to w until the source io.Reader returns an error,
+// such as io.EOF, with each Write ending on a newline boundary, except
+// for the final bytes not terminated by a newline. It returns the number
+// of bytes written, along with any error encountered other than io.EOF.
+//
+// This method is provided to satisfy the io.WriterTo interface, which
+// the io.Copy function uses if available.
+func (br *BatchLineReader) WriteTo(w io.Writer) (int64, error) {
+	return br.lr.WriteTo(w)
+}
@@ -0,0 +1,137 @@
+package gonl
+
+import (
+	"fmt"
+	"io"
+	"strings"
+	"testing"
+)
+
+func ExampleBatchLineReader() {
+	br, err := NewBatchLineReader(strings.NewReader("line 1\nline 2\nline 3"), 4096)
+	if err != nil {
+		panic(err)
+	}
+
+	buf := make([]byte, 10)
+	for {
+		n, err := br.Read(buf)
+		if n > 0 {
+			fmt.Printf("%q\n", buf[:n])
+		}
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			panic(err)
+		}
+	}
+	// Output:
+	// "line 1\n"
+	// "line 2\n"
+	// "line 3"
+}
+
+func TestBatchLineReader(t *testing.T) {
+	t.Run("size", func(t *testing.T) {
+		_, err := NewBatchLineReader(&testReader{}, 0)
+		ensureError(t, err, "less than or equal to 0")
+	})
+
+	t.Run("reads complete lines", func(t *testing.T) {
+		br, err := NewBatchLineReader(&testReader{tuples: []tuple{
+			tuple{"line 1\nline 2\nli", nil},
+			tuple{"ne 3\nline 4", io.EOF},
+		}}, 64)
+		ensureErrorNil(t, err)
+		buf := make([]byte, 10)
+
+		n, err := br.Read(buf)
+		ensureErrorNil(t, err)
+		ensureBufferLimit(t, buf, n, "line 1\n")
+
+		n, err = br.Read(buf)
+		ensureErrorNil(t, err)
+		ensureBufferLimit(t, buf, n, "line 2\n")
+
+		n, err = br.Read(buf)
+		ensureErrorNil(t, err)
+		ensureBufferLimit(t, buf, n, "line 3\n")
+
+		n, err = br.Read(buf)
+		ensureErrorNil(t, err)
+		ensureBufferLimit(t, buf, n, "line 4")
+
+		n, err = br.Read(buf)
+		ensureError(t, err, "EOF")
+		ensureBufferLimit(t, buf, n, "")
+	})
+
+	t.Run("NUL delimiter", func(t *testing.T) {
+		_, err := NewBatchLineReaderDelim(&testReader{}, 64, nil)
+		ensureError(t, err, "delimiter is empty")
+
+		br, err := NewBatchLineReaderDelim(&testReader{tuples: []tuple{
+			tuple{"file\nname 1\x00file 2\x00fi", nil},
+			tuple{"le 3", io.EOF},
+		}}, 64, []byte{NUL})
+		ensureErrorNil(t, err)
+		buf := make([]byte, 20)
+
+		n, err := br.Read(buf)
+		ensureErrorNil(t, err)
+		ensureBufferLimit(t, buf, n, "file\nname 1\x00file 2\x00")
+
+		n, err = br.Read(buf)
+		ensureErrorNil(t, err)
+		ensureBufferLimit(t, buf, n, "file 3")
+	})
+
+	t.Run("read size", func(t *testing.T) {
+		r := &sizeRecordingReader{data: []byte("line 1\n")}
+		br, err := NewBatchLineReader(r, 1000)
+		ensureErrorNil(t, err)
+
+		n, err := br.Read(make([]byte, 64))
+		ensureErrorNil(t, err)
+		if got, want := n, 7; got != want {
+			t.Errorf("GOT: %v; WANT: %v", got, want)
+		}
+		if got, want := r.size, 1000; got < want {
+			t.Errorf("GOT: %v; WANT: >= %v", got, want)
+		}
+	})
+}
+
+// sizeRecordingReader records the length of the buffer given to Read.
+type sizeRecordingReader struct {
+	data []byte
+	size int
+}
+
+func (r *sizeRecordingReader) Read(p []byte) (int, error) {
+	r.size = len(p)
+	n := copy(p, r.data)
+	r.data = r.data[n:]
+	if len(r.data) == 0 {
+		return n, io.EOF
+	}
+	return n, nil
+}
+
+func TestBatchLineReaderWriteTo(t *testing.T) {
+	br, err := NewBatchLineReader(&testReader{tuples: []tuple{
+		tuple{"line 1\nline 2\nli", nil},
+		tuple{"ne 3\nline 4", io.EOF},
+	}}, 64)
+	ensureErrorNil(t, err)
+	w := new(recordingWriter)
+
+	// io.Copy uses WriteTo, so each Write ends on a newline boundary.
+	n, err := io.Copy(w, br)
+	ensureErrorNil(t, err)
+	if got, want := n, int64(27); got != want {
+		t.Errorf("GOT: %v; WANT: %v", got, want)
+	}
+	ensureWrites(t, w.writes, []string{"line 1\nline 2\n", "line 3\n", "line 4"})
+}
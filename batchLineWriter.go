@@ -5,12 +5,32 @@ import (
 	"errors"
 	"fmt"
 	"io"
+	"net"
+	"sync"
+	"time"
 )
 
 const maxInt = int(^uint(0) >> 1)
 const minRead = 512
 const smallBufferSize = 64
 
+// ErrClosed is returned when a writer is used after it has been
+// closed. It wraps io.ErrClosedPipe.
+var ErrClosed = fmt.Errorf("gonl: writer already closed: %w", io.ErrClosedPipe)
+
+// newline is used when a line feed needs to be written on its own.
+var newline = []byte{'\n'}
+
+// NUL is the record delimiter used by `find -print0` and `xargs -0`,
+// which allows records such as filenames to contain newlines. Pass it
+// to NewBatchLineWriterDelim, or use it in the Delim field of the
+// other line oriented types in this package, for instance:
+//
+//	lw, err := gonl.NewBatchLineWriterDelim(os.Stdout, 4096, gonl.NUL)
+//	pw := &gonl.PerLineWriter{WC: os.Stdout, Delim: []byte{gonl.NUL}}
+//	r := &gonl.PerLineReader{R: os.Stdin, Delim: []byte{gonl.NUL}}
+const NUL byte = 0
+
 // BatchLineWriter is an io.WriteCloser that buffers output to ensure
 // it only emits bytes to the underlying io.WriteCloser on line feed
 // boundaries.
@@ -37,8 +57,262 @@ type BatchLineWriter struct {
 	// Flush on LF after buffer this size or larger.
 	flushThreshold int
 
+	// delim is the sequence of bytes terminating each line, which is
+	// LF unless created by NewBatchLineWriterDelim or
+	// NewBatchLineWriterDelimBytes.
+	delim []byte
+
+	// delimMatched is the number of bytes of a multi-byte delimiter
+	// matched at the end of the data written, used when collecting
+	// line lengths.
+	delimMatched int
+
 	// -1 when no newlines in buf
 	indexOfFinalNewline int
+
+	// FlushOnlyOnClose, when set, causes the BatchLineWriter to
+	// accumulate all data written to it, regardless of the flush
+	// threshold, and emit it to the underlying io.WriteCloser with a
+	// single Write call when Close is invoked. This is useful when
+	// each Write to the underlying io.WriteCloser is expensive, such
+	// as an HTTP POST, but because the buffer grows without bound,
+	// it should only be used when the amount of data is known to be
+	// modest. MaxLineLength is still enforced by each Write rather
+	// than by Close: a line that exceeds it is rejected, and its
+	// error returned, by the Write that makes it too long, and
+	// because nothing is flushed before Close, none of its bytes
+	// reach the underlying io.WriteCloser, unless TruncateLongLines
+	// is set, in which case only its truncated form is buffered.
+	FlushOnlyOnClose bool
+
+	// OnError, when not nil, is invoked with each non-nil error
+	// returned by a Write or Close call to the underlying
+	// io.WriteCloser, immediately before that error is returned to
+	// the caller. This is useful for centrally logging errors from
+	// goroutines that do not check the error returned by Write.
+	OnError func(err error)
+
+	// ErrorHandler, when not nil, is invoked with each non-nil error
+	// returned by a Write or Close call to the underlying
+	// io.WriteCloser, after OnError, and what it returns is returned
+	// to the caller instead, so it may annotate or convert errors,
+	// such as adding the name of the destination. When it returns
+	// nil, the BatchLineWriter continues as though the failed Write
+	// had written only the bytes it reported, keeping the remaining
+	// bytes buffered for the next flush, and the caller sees no
+	// error. Because nothing remains to be flushed after Close, the
+	// bytes a failed Write made by Close did not write are discarded
+	// regardless.
+	ErrorHandler func(err error) error
+
+	// CollectLineLengths, when set, causes the BatchLineWriter to
+	// record the length of each line written to it, available from
+	// the LineLengthHistogram method.
+	CollectLineLengths bool
+
+	// lineLengths maps bucket to count of lines; see
+	// LineLengthHistogram.
+	lineLengths map[int]int
+
+	// partialLineLength is the number of bytes written since the
+	// final newline, used when collecting line lengths.
+	partialLineLength int
+
+	// FlushPartialWhenFull, when set, causes the BatchLineWriter to
+	// flush its buffer when it reaches the flush threshold even when
+	// it contains no newline, rather than holding a single enormous
+	// line until a newline arrives or Close is invoked. This bounds
+	// latency, but breaks the guarantee that each Write to the
+	// underlying io.WriteCloser ends on a newline boundary, because
+	// a long line may be split across multiple Write calls.
+	FlushPartialWhenFull bool
+
+	// counters describe the data written; see Stats.
+	counters writerCounters
+
+	// JoinContinuations, when set, causes the BatchLineWriter to
+	// join each line ending with ContinuationByte to the line that
+	// follows it, removing both the continuation byte and the
+	// newline, so that the lines form a single logical line before
+	// being flushed. A continuation byte written by one Write call is
+	// joined with a newline written by the next Write call, provided
+	// the continuation byte has not already been flushed, which only
+	// happens when FlushPartialWhenFull is also set. Because fewer
+	// bytes are buffered than were written, Write still returns
+	// len(p) on success.
+	JoinContinuations bool
+
+	// ContinuationByte is the byte that marks a line continuation
+	// when JoinContinuations is set. When zero, a backslash is used.
+	ContinuationByte byte
+
+	// continued is set when the final byte written was the
+	// continuation byte, so a newline written next is joined.
+	continued bool
+
+	// CollectFlushLatency, when set, causes the BatchLineWriter to
+	// measure the duration of each Write to the underlying
+	// io.WriteCloser, available from the FlushLatency method.
+	CollectFlushLatency bool
+
+	// latencies is not nil after a flush has been measured.
+	latencies *latencyReservoir
+
+	// HoldLines, when greater than zero, causes the BatchLineWriter
+	// to hold back the most recent HoldLines complete lines, so that
+	// a line is only flushed once at least HoldLines newer complete
+	// lines have been written after it. The flush threshold still
+	// determines when a flush is attempted, but a flush only writes
+	// the lines that are no longer held, so up to HoldLines complete
+	// lines in addition to the flush threshold remain buffered, and
+	// no flush occurs while the buffer holds no more than HoldLines
+	// complete lines. When FlushPartialWhenFull is also set, a buffer
+	// is only flushed in the middle of a line when it contains no
+	// newline at all. Close flushes everything, including the held
+	// lines.
+	HoldLines int
+
+	// FlushInterval, when greater than zero, bounds how long a
+	// completed line may remain buffered when data trickles in
+	// slowly. A timer is started when the buffer first holds a
+	// completed line, and when it expires, all completed lines not
+	// held because of HoldLines are flushed, even when the flush
+	// threshold has not been reached. The timer fires on its own
+	// goroutine, so errors from these flushes are not returned to a
+	// caller, but are reported to OnError, and the data not written
+	// remains buffered for the next flush. No goroutine runs while
+	// no completed line is buffered, so an idle BatchLineWriter costs
+	// nothing, and Close stops the timer, so no flush occurs after it
+	// returns. It has no effect when FlushOnlyOnClose is set.
+	FlushInterval time.Duration
+
+	// OnFlush, when not nil, is invoked after each Write call to the
+	// underlying io.WriteCloser, including the final one made by
+	// Close, with the number of bytes that call wrote and the number
+	// of delimiter terminated lines among them. It is invoked even
+	// when the Write returns an error, in which case bytes only
+	// counts what was actually written. This is useful for feeding
+	// metrics without wrapping the underlying io.WriteCloser.
+	OnFlush func(bytes, lines int)
+
+	// FlushTracer, when not nil, is invoked around each Write call to
+	// the underlying io.WriteCloser, including the final one made by
+	// Close, for instance to start a trace span for each flush.
+	FlushTracer FlushTracer
+
+	// DiscardOnCancel, when set, causes ReadFromContext to discard
+	// all buffered bytes when its context is done, rather than
+	// flushing the completed lines.
+	DiscardOnCancel bool
+
+	// WriteTimeout, when greater than zero, bounds how long a single
+	// Write to the underlying io.WriteCloser may block, after which
+	// it fails with ErrWriteTimeout. When the underlying
+	// io.WriteCloser has a SetWriteDeadline method, like net.Conn,
+	// the deadline aborts the Write. Otherwise a watchdog stops
+	// waiting for the Write, which may still be in progress, so
+	// every later Write to the underlying io.WriteCloser, including
+	// the one made by Close, also fails with ErrWriteTimeout.
+	WriteTimeout time.Duration
+
+	// SyncEvery, when greater than zero and the underlying
+	// io.WriteCloser has a Sync method, like *os.File, causes the
+	// BatchLineWriter to call Sync after every SyncEvery successful
+	// flushes, and upon Close when any flush has not been synced, so
+	// flushed lines are durable. An error from Sync is returned as
+	// the error of the flush.
+	SyncEvery int
+
+	// unsynced is the number of flushes since Sync was last called.
+	unsynced int
+
+	// Retry, when not nil, causes a failed Write to the underlying
+	// io.WriteCloser to be retried according to the policy when its
+	// error is retryable. Each retry writes only the bytes not yet
+	// written, so partially written bytes are never duplicated.
+	Retry *RetryPolicy
+
+	// Grow, when not nil, determines the capacity of the buffer
+	// each time it must grow. When nil, the buffer grows to twice
+	// its capacity plus the number of bytes being added. See
+	// GrowDoubling and GrowFixed.
+	Grow GrowFunc
+
+	// MaxBuffer, when greater than zero, bounds the number of bytes
+	// the buffer may hold, for instance when the input may contain
+	// no newline for megabytes. When a Write would exceed it, the
+	// completed lines not held because of HoldLines are flushed
+	// first, and when the buffer is still full, Overflow determines
+	// what happens to the remaining bytes.
+	MaxBuffer int
+
+	// Overflow is the policy applied when the buffer reaches
+	// MaxBuffer.
+	Overflow OverflowPolicy
+
+	// MaxLineLength, when greater than zero, is the largest number
+	// of bytes a line may have, excluding its delimiter. A Write that
+	// causes a line to exceed it returns a *LineTooLongError, and the
+	// line is rejected: its bytes still buffered are discarded, as
+	// are its remaining bytes up to and including its delimiter, even
+	// when written by later Write calls. Only the bytes of the line
+	// flushed beforehand, because of FlushPartialWhenFull or
+	// OverflowFlush, reach the underlying io.WriteCloser. When
+	// TruncateLongLines is set, such a line is truncated to
+	// MaxLineLength bytes instead, keeping its delimiter, and no
+	// error is returned. When FlushOnlyOnClose is set, lines are
+	// still checked as they are written, so a rejected line is
+	// discarded entirely before Close, and Close only flushes the
+	// accepted or truncated lines, never returning a
+	// *LineTooLongError.
+	MaxLineLength int
+
+	// TruncateLongLines, when set, causes lines longer than
+	// MaxLineLength to be truncated rather than rejected.
+	TruncateLongLines bool
+
+	// written is the number of bytes accepted by Write when
+	// enforcing MaxLineLength, lineOffset is the value it had at the
+	// start of the current line, lineLength is the number of its
+	// bytes written so far, and discarding is set while discarding
+	// the remainder of a line longer than MaxLineLength.
+	written    int64
+	lineOffset int64
+	lineLength int
+	discarding bool
+
+	// delimPending is the number of bytes of a multi-byte delimiter
+	// with which the previous Write ended, when enforcing
+	// MaxLineLength.
+	delimPending int
+
+	// truncating is set while discarding the remainder of a line
+	// because of OverflowTruncate.
+	truncating bool
+
+	// stalled is set when a Write to the underlying io.WriteCloser
+	// was abandoned by the WriteTimeout watchdog.
+	stalled bool
+
+	// mu serializes access to the BatchLineWriter between callers and
+	// the FlushInterval timer.
+	mu sync.Mutex
+
+	// timer is not nil after the FlushInterval timer has been
+	// started, and timerArmed is set while it is pending.
+	timer      *time.Timer
+	timerArmed bool
+
+	// closed is set by Close.
+	closed bool
+
+	// writeBuffers is not nil when wc can write multiple slices with
+	// a single call.
+	writeBuffers func(net.Buffers) (int64, error)
+
+	// vectors is the backing array for the net.Buffers given to
+	// writeBuffers, to prevent allocating one for each flush.
+	vectors [2][]byte
 }
 
 // NewBatchLineWriter returns a new BatchLineWriter with the specified
@@ -65,16 +339,62 @@ type BatchLineWriter struct {
 //         return rerr
 //     }
 func NewBatchLineWriter(wc io.WriteCloser, flushThreshold int) (*BatchLineWriter, error) {
+	return NewBatchLineWriterDelim(wc, flushThreshold, '\n')
+}
+
+// NewBatchLineWriterDelim returns a new BatchLineWriter with the
+// specified flush threshold, which flushes on boundaries of the
+// specified delimiter byte rather than on LF, so the batching logic
+// may be used for other record delimited formats, such as records
+// separated by ';' or by the ASCII record separator 0x1E. Wherever the
+// BatchLineWriter documentation refers to a newline, it refers to
+// delim for the returned BatchLineWriter.
+func NewBatchLineWriterDelim(wc io.WriteCloser, flushThreshold int, delim byte) (*BatchLineWriter, error) {
+	return NewBatchLineWriterDelimBytes(wc, flushThreshold, []byte{delim})
+}
+
+// NewBatchLineWriterDelimBytes returns a new BatchLineWriter with the
+// specified flush threshold, which flushes on boundaries of the
+// specified multi-byte delimiter, such as CRLF for protocols like
+// HTTP, SMTP, and IRC. The BatchLineWriter never flushes between the
+// bytes of a delimiter, even when a delimiter is split across
+// multiple Write calls, except when FlushPartialWhenFull is set.
+// JoinContinuations is ignored when the delimiter is longer than one
+// byte.
+func NewBatchLineWriterDelimBytes(wc io.WriteCloser, flushThreshold int, delim []byte) (*BatchLineWriter, error) {
 	if flushThreshold <= 0 {
 		return nil, fmt.Errorf("cannot create BatchLineWriter when flushThreshold less than or equal to 0: %d", flushThreshold)
 	}
+	if len(delim) == 0 {
+		return nil, errors.New("cannot create BatchLineWriter when delimiter is empty")
+	}
 	return &BatchLineWriter{
 		wc:                  wc,
 		flushThreshold:      flushThreshold,
+		delim:               append([]byte(nil), delim...),
 		indexOfFinalNewline: -1,
+		writeBuffers:        buffersWriterFor(wc),
 	}, nil
 }
 
+// NewBatchLineWriterCap returns a new BatchLineWriter with the
+// specified flush threshold, whose buffer is preallocated with the
+// specified initial capacity. This is useful when the flush threshold
+// is small but occasional long lines would otherwise cause the buffer
+// to be reallocated several times early on. The initial capacity must
+// not be less than the flush threshold.
+func NewBatchLineWriterCap(wc io.WriteCloser, flushThreshold, initialCap int) (*BatchLineWriter, error) {
+	if initialCap < flushThreshold {
+		return nil, fmt.Errorf("cannot create BatchLineWriter when initialCap less than flushThreshold: %d < %d", initialCap, flushThreshold)
+	}
+	lw, err := NewBatchLineWriter(wc, flushThreshold)
+	if err != nil {
+		return nil, err
+	}
+	lw.buf = make([]byte, 0, initialCap)
+	return lw, nil
+}
+
 // bufferGrow will ensure the backing buffer has enough room to hold
 // at least n more bytes, reslicing the data in the buffer if
 // possible, and expanding the backing array if necessary. It returns
@@ -94,7 +414,7 @@ func (lw *BatchLineWriter) bufferGrow(n int) int {
 	// NOTE: If we get here, there is no way of leaving this method
 	// without lw.off set to 0, and any used portion of buffer moved
 	// to the left.
-	if lw.buf == nil && n <= smallBufferSize {
+	if lw.buf == nil && n <= smallBufferSize && lw.Grow == nil {
 		lw.buf = make([]byte, n, smallBufferSize)
 		return 0
 	}
@@ -105,11 +425,9 @@ func (lw *BatchLineWriter) bufferGrow(n int) int {
 		// slide the data over to avoid too frequent allocation and
 		// byte copying.
 		copy(lw.buf, lw.buf[lw.off:])
-	} else if c > maxInt-c-n {
-		panic(errors.New("gonl.BatchLineWriter: too large"))
 	} else {
 		// Allocate new backing array, then copy bytes.
-		buf := make([]byte, 2*c+n)
+		buf := make([]byte, lw.newCapacity(mpn, n))
 		copy(buf, lw.buf[lw.off:])
 		lw.buf = buf
 	}
@@ -150,24 +468,44 @@ func (lw *BatchLineWriter) bufferReset() {
 // io.WriteCloser. This will either return any error caused by writing
 // the bytes to the underlying io.WriteCloser, or an error caused by
 // closing it. Use this method when done with a BatchLineWriter to
-// prevent data loss.
+// prevent data loss. Calling Close more than once returns ErrClosed.
 func (lw *BatchLineWriter) Close() error {
 	var err error
 
+	lw.mu.Lock()
+	defer lw.mu.Unlock()
+	lw.stopFlushTimer()
+
+	if lw.closed {
+		return ErrClosed
+	}
+	lw.closed = true
+
+	if lw.CollectLineLengths && lw.partialLineLength > 0 {
+		lw.recordLineLength(lw.partialLineLength)
+		lw.partialLineLength = 0
+	}
+
 	if lw.bufferLength() > 0 {
-		_, err = lw.wc.Write(lw.buf[lw.off:])
+		var nw int
+		fp := lw.flushStart(lw.bufferLength())
+		nw, err = lw.wcWrite(lw.buf[lw.off:])
+		lw.flushDone(fp, nw, err, lw.buf[lw.off:])
 		if err != nil {
 			lw.bufferReset()
 			_ = lw.wc.Close()
 			lw.wc = nil
-			return err
+			return lw.reportError(err)
 		}
 	}
 
 	lw.bufferReset()
-	err = lw.wc.Close()
+	err = lw.syncRemaining()
+	if cerr := lw.wc.Close(); err == nil {
+		err = cerr
+	}
 	lw.wc = nil
-	return err
+	return lw.reportError(err)
 }
 
 // flush flushes buffer to underlying io.WriteCloser, up to but
@@ -176,13 +514,18 @@ func (lw *BatchLineWriter) flush(leno, lenp, index int) (int, error) {
 	debug("flush: leno: %d; len(p): %d; index: %d\n", leno, lenp, index)
 	debug("flush: lw.off: %d; expected nw: %d\n", lw.off, index-lw.off)
 	debug("flush: before: %q\n", lw.buf[lw.off:])
-	nw, err := lw.wc.Write(lw.buf[lw.off:index])
+	fp := lw.flushStart(index - lw.off)
+	nw, err := lw.wcWrite(lw.buf[lw.off:index])
+	lw.flushDone(fp, nw, err, lw.buf[lw.off:index])
 	if nw < 0 {
-		return nw, errors.New("invalid write result")
+		return nw, lw.reportError(errors.New("invalid write result"))
 	}
+	err = lw.reportError(err)
 	if err == nil {
-		lw.off += nw                // advance offset to after nw
-		lw.indexOfFinalNewline = -1 // optimization
+		lw.off += nw // advance offset to after nw
+		if lw.indexOfFinalNewline < lw.off {
+			lw.indexOfFinalNewline = -1 // optimization
+		}
 		return lenp, nil
 	}
 
@@ -205,15 +548,67 @@ func (lw *BatchLineWriter) flush(leno, lenp, index int) (int, error) {
 	lw.buf = lw.buf[:lw.off-nb]
 	debug("flush: after:  %q\n", lw.buf[lw.off:])
 
-	lw.indexOfFinalNewline = bytes.LastIndexByte(lw.buf[lw.off:], '\n')
+	lw.indexOfFinalNewline = lw.finalDelim(lw.off)
 	debug("flush: indexOfFinalNewline: %d; lw.off: %d; nb: %d\n", lw.indexOfFinalNewline, lw.off, nb)
-	if lw.indexOfFinalNewline != -1 {
-		lw.indexOfFinalNewline += lw.off
-	}
 
 	return 0, err
 }
 
+// releaseIndex returns the index of the final newline in the buffer
+// that may be flushed, skipping the newlines of the lines held because
+// of HoldLines. It returns a value less than lw.off when no newline may
+// be flushed.
+func (lw *BatchLineWriter) releaseIndex() int {
+	index := lw.indexOfFinalNewline
+	for held := lw.HoldLines; held > 0 && index >= lw.off; held-- {
+		end := index + 1 - len(lw.delim) // start of held delimiter
+		if end < lw.off {
+			return -1
+		}
+		index = lastIndexDelimBytes(lw.buf[lw.off:end], lw.delim)
+		if index >= 0 {
+			index += lw.off
+		}
+	}
+	return index
+}
+
+// finalDelim returns the index in the buffer of the final byte of the
+// final delimiter that ends at or after index m, or -1 when there is
+// none. A delimiter longer than one byte may begin before m, when it
+// was split across multiple writes.
+func (lw *BatchLineWriter) finalDelim(m int) int {
+	from := m - (len(lw.delim) - 1)
+	if from < lw.off {
+		from = lw.off
+	}
+	if from > len(lw.buf) {
+		return -1
+	}
+	index := lastIndexDelimBytes(lw.buf[from:], lw.delim)
+	if index < 0 {
+		return -1
+	}
+	return from + index
+}
+
+// reportError invokes the OnError callback when both it and err are
+// not nil, then returns err, or what ErrorHandler returns for it when
+// set.
+func (lw *BatchLineWriter) reportError(err error) error {
+	if err == nil {
+		return nil
+	}
+	lw.counters.addError()
+	if lw.OnError != nil {
+		lw.OnError(err)
+	}
+	if lw.ErrorHandler != nil {
+		err = lw.ErrorHandler(err)
+	}
+	return err
+}
+
 // ReadFrom reads data from r until io.EOF or error, periodically
 // flushing one or more completed newlines to the underlying
 // io.WriteCloser when the buffer length exceeds the configured
@@ -225,16 +620,33 @@ func (lw *BatchLineWriter) flush(leno, lenp, index int) (int, error) {
 // which the io.Copy function uses if available, eliminating the need
 // to copy bytes from the io.Reader, through two buffers, and finally
 // to the io.Writer.
+//
+// After Close has been called, it returns ErrClosed without reading
+// from r.
 func (lw *BatchLineWriter) ReadFrom(r io.Reader) (int64, error) {
 	var totalRead int64
 
+	lw.mu.Lock()
+	if (lw.FlushInterval > 0 || lw.MaxBuffer > 0 || lw.MaxLineLength > 0) && !lw.closed {
+		lw.mu.Unlock()
+		return lw.readFromInterval(r)
+	}
+	defer lw.mu.Unlock()
+
+	if lw.closed {
+		return 0, ErrClosed
+	}
+	if lw.stalled {
+		return 0, ErrWriteTimeout
+	}
+
 	for {
 		leno := lw.bufferLength()
 		m := lw.bufferGrow(minRead)
 		lw.buf = lw.buf[:m]
 
 		nr, rerr := r.Read(lw.buf[m:cap(lw.buf)])
-		if nr < 0 {
+		if nr < 0 || nr > cap(lw.buf)-m {
 			return totalRead, errors.New("invalid read result")
 		}
 
@@ -242,16 +654,23 @@ func (lw *BatchLineWriter) ReadFrom(r io.Reader) (int64, error) {
 
 		// NEWLINE LOGIC
 
-		p := lw.buf[m : m+nr]
-		if finalIndex := bytes.LastIndexByte(p, '\n'); finalIndex >= 0 {
-			lw.indexOfFinalNewline = m + finalIndex
+		lw.appended(m)
+		lw.counters.noteBuffered(lw.bufferLength())
+		if finalIndex := lw.finalDelim(m); finalIndex >= 0 {
+			lw.indexOfFinalNewline = finalIndex
 		}
 
-		if lw.bufferLength() >= lw.flushThreshold && lw.indexOfFinalNewline >= 0 {
-			// Flush some data
-			nw, werr := lw.flush(leno, len(p), lw.indexOfFinalNewline+1)
-			if werr != nil {
-				return totalRead + int64(nw), werr
+		if !lw.FlushOnlyOnClose && lw.bufferLength() >= lw.flushThreshold {
+			index := lw.releaseIndex() + 1
+			if lw.indexOfFinalNewline < lw.off && lw.FlushPartialWhenFull {
+				index = len(lw.buf)
+			}
+			if index > lw.off {
+				// Flush some data
+				nw, werr := lw.flush(leno, nr, index)
+				if werr != nil {
+					return totalRead + int64(nw), werr
+				}
 			}
 		}
 
@@ -272,10 +691,99 @@ func (lw *BatchLineWriter) ReadFrom(r io.Reader) (int64, error) {
 	}
 }
 
+// appended processes the bytes newly appended to the buffer starting
+// at index m, joining continuation lines and recording line lengths
+// when enabled.
+func (lw *BatchLineWriter) appended(m int) {
+	if lw.JoinContinuations && len(lw.delim) == 1 {
+		lw.joinContinuations(m)
+		if len(lw.buf) < m {
+			// Joined a continuation byte from a previous write.
+			m = len(lw.buf)
+		}
+	}
+	if lw.CollectLineLengths {
+		lw.recordLineLengths(lw.buf[m:])
+	}
+}
+
+// joinContinuations removes from the buffer each newline starting at
+// index m that immediately follows the continuation byte in the data
+// written, along with that continuation byte, which may have been
+// appended by a previous write. A continuation byte left in the buffer
+// by a join does not join a later newline.
+func (lw *BatchLineWriter) joinContinuations(m int) {
+	c := lw.ContinuationByte
+	if c == 0 {
+		c = '\\'
+	}
+	w := m
+	continued := lw.continued
+	for _, b := range lw.buf[m:] {
+		if b == lw.delim[0] && continued && w > lw.off {
+			w-- // drop both continuation byte and newline
+			if w < m && lw.partialLineLength > 0 {
+				lw.partialLineLength-- // already counted by previous write
+			}
+			continued = false
+			continue
+		}
+		lw.buf[w] = b
+		w++
+		continued = b == c
+	}
+	lw.buf = lw.buf[:w]
+	lw.continued = continued
+}
+
 // Write appends bytes from p to the internal buffer, flushing buffer
 // up to and including the final LF when buffer length exceeds
-// threshold specified when creating the BatchLineWriter.
+// threshold specified when creating the BatchLineWriter. After Close
+// has been called, it returns ErrClosed.
 func (lw *BatchLineWriter) Write(p []byte) (int, error) {
+	lw.mu.Lock()
+	defer lw.mu.Unlock()
+	n, err := lw.write(p)
+	lw.armFlushTimer()
+	return n, err
+}
+
+// write is Write, called with mu held.
+func (lw *BatchLineWriter) write(p []byte) (int, error) {
+	if lw.closed {
+		return 0, ErrClosed
+	}
+	if lw.stalled {
+		return 0, ErrWriteTimeout
+	}
+	if lw.MaxLineLength > 0 {
+		return lw.writeLimited(p)
+	}
+	return lw.writeBuffer(p)
+}
+
+// writeBuffer appends p to the buffer, enforcing MaxBuffer when set.
+func (lw *BatchLineWriter) writeBuffer(p []byte) (int, error) {
+	if lw.MaxBuffer > 0 {
+		return lw.writeBounded(p)
+	}
+	return lw.writeBatch(p)
+}
+
+// writeBatch appends p to the buffer, flushing as needed.
+func (lw *BatchLineWriter) writeBatch(p []byte) (int, error) {
+
+	if lw.writeBuffers != nil && !lw.JoinContinuations && lw.HoldLines <= 0 {
+		if finalIndex := lastIndexDelimBytes(p, lw.delim); finalIndex >= 0 && !lw.FlushOnlyOnClose && lw.bufferLength()+len(p) >= lw.flushThreshold {
+			// Write buffered bytes and completed lines from p without
+			// first copying p into buffer.
+			if lw.CollectLineLengths {
+				lw.recordLineLengths(p)
+			}
+			return lw.flushVectored(p, finalIndex+1)
+		}
+	}
+
 	leno := lw.bufferLength()
 
 	// functionally equivalent to `lw.buf = append(lw.buf, p...)`
@@ -286,21 +794,183 @@ func (lw *BatchLineWriter) Write(p []byte) (int, error) {
 	// Because just grew, no way this does not copy all p.
 	copy(lw.buf[m:], p)
 
-	if finalIndex := bytes.LastIndexByte(p, '\n'); finalIndex >= 0 {
-		lw.indexOfFinalNewline = m + finalIndex
+	return lw.writeAppended(leno, m, len(p))
+}
+
+// writeAppended processes the lenp bytes just appended to the buffer
+// at index m, when it held leno bytes before, flushing as needed.
+func (lw *BatchLineWriter) writeAppended(leno, m, lenp int) (int, error) {
+	lw.appended(m)
+	lw.counters.noteBuffered(lw.bufferLength())
+	if finalIndex := lw.finalDelim(m); finalIndex >= 0 {
+		lw.indexOfFinalNewline = finalIndex
 	}
 
-	debug("Write: m: %d; len(p): %d; indexOfFinalNewLine: %d\n", m, len(p), lw.indexOfFinalNewline)
+	debug("Write: m: %d; len(p): %d; indexOfFinalNewLine: %d\n", m, lenp, lw.indexOfFinalNewline)
 
 	// TODO Should this limit based on entire buffer size, or how much
 	// data is being used by buffer. Opting for the latter here.
-	if lw.bufferLength() < lw.flushThreshold || lw.indexOfFinalNewline < lw.off {
-		// Either do not need to flush, or no newline exists in buffer
+	if lw.FlushOnlyOnClose || lw.bufferLength() < lw.flushThreshold {
 		debug("Write: no need to flush\n")
-		return len(p), nil
+		return lenp, nil
+	}
+
+	if lw.indexOfFinalNewline < lw.off {
+		// No newline exists in buffer.
+		if lw.FlushPartialWhenFull {
+			return lw.flush(leno, lenp, len(lw.buf))
+		}
+		debug("Write: no newline to flush\n")
+		return lenp, nil
+	}
+
+	index := lw.releaseIndex()
+	if index < lw.off {
+		debug("Write: all lines held\n")
+		return lenp, nil
 	}
 
 	// Buffer is larger than threshold, and has LF: write everything
-	// up to and including that final LF.
-	return lw.flush(leno, len(p), lw.indexOfFinalNewline+1)
+	// up to and including the final LF not held.
+	return lw.flush(leno, lenp, index+1)
+}
+
+// WriteString is like Write, but accepts a string, which is copied
+// directly into the buffer, avoiding the allocation of converting it
+// to a byte slice, except when MaxLineLength or MaxBuffer is set.
+func (lw *BatchLineWriter) WriteString(s string) (int, error) {
+	lw.mu.Lock()
+	defer lw.mu.Unlock()
+
+	var n int
+	var err error
+	if lw.closed || lw.stalled || lw.MaxLineLength > 0 || lw.MaxBuffer > 0 {
+		n, err = lw.write([]byte(s))
+	} else {
+		leno := lw.bufferLength()
+		m, ok := lw.bufferGrowInline(len(s))
+		if !ok {
+			m = lw.bufferGrow(len(s))
+		}
+		copy(lw.buf[m:], s)
+		n, err = lw.writeAppended(leno, m, len(s))
+	}
+	lw.armFlushTimer()
+	return n, err
+}
+
+// WriteLines writes each slice from lines to the BatchLineWriter,
+// following each with a newline, batching as Write does. It returns
+// the total number of bytes written, including the appended newline
+// bytes. When an error occurs, the return value reflects how many
+// bytes were written before the error.
+func (lw *BatchLineWriter) WriteLines(lines [][]byte) (int, error) {
+	var total int
+	for _, line := range lines {
+		nw, err := lw.Write(line)
+		total += nw
+		if err != nil {
+			return total, err
+		}
+		nw, err = lw.Write(lw.delim)
+		total += nw
+		if err != nil {
+			return total, err
+		}
+	}
+	return total, nil
+}
+
+// WriteStringLines is like WriteLines, but accepts a slice of
+// strings.
+func (lw *BatchLineWriter) WriteStringLines(lines []string) (int, error) {
+	var total int
+	for _, line := range lines {
+		nw, err := lw.WriteString(line)
+		total += nw
+		if err != nil {
+			return total, err
+		}
+		nw, err = lw.Write(lw.delim)
+		total += nw
+		if err != nil {
+			return total, err
+		}
+	}
+	return total, nil
+}
+
+// PeekLines returns copies of the complete lines presently held in the
+// buffer, without their terminating newlines, excluding any trailing
+// partial line. It does not alter the buffer or cause a flush. Because
+// the returned slices are copies, they remain valid after subsequent
+// writes.
+func (lw *BatchLineWriter) PeekLines() [][]byte {
+	lw.mu.Lock()
+	defer lw.mu.Unlock()
+
+	if lw.indexOfFinalNewline < lw.off {
+		return nil
+	}
+	data := make([]byte, lw.indexOfFinalNewline+1-lw.off)
+	copy(data, lw.buf[lw.off:])
+
+	lines := make([][]byte, 0, bytes.Count(data, lw.delim))
+	for len(data) > 0 {
+		index := bytes.Index(data, lw.delim)
+		if index == -1 {
+			break // delimiter partially flushed
+		}
+		lines = append(lines, data[:index:index])
+		data = data[index+len(lw.delim):]
+	}
+	return lines
+}
+
+// PendingLines returns the number of complete lines presently held in
+// the buffer, including those held because of HoldLines, and excluding
+// any trailing partial line. It does not alter the buffer or cause a
+// flush.
+func (lw *BatchLineWriter) PendingLines() int {
+	lw.mu.Lock()
+	defer lw.mu.Unlock()
+
+	if lw.indexOfFinalNewline < lw.off {
+		return 0
+	}
+	return bytes.Count(lw.buf[lw.off:lw.indexOfFinalNewline+1], lw.delim)
+}
+
+// Delimiter returns the byte that terminates each line, on whose
+// boundaries the BatchLineWriter flushes. When the delimiter is longer
+// than one byte, it returns the final byte of the delimiter.
+func (lw *BatchLineWriter) Delimiter() byte { return lw.delim[len(lw.delim)-1] }
+
+// DelimiterBytes returns a copy of the delimiter that terminates each
+// line, on whose boundaries the BatchLineWriter flushes.
+func (lw *BatchLineWriter) DelimiterBytes() []byte { return append([]byte(nil), lw.delim...) }
+
+// Size returns the flush threshold specified when creating the
+// BatchLineWriter.
+func (lw *BatchLineWriter) Size() int { return lw.flushThreshold }
+
+// Buffered returns the number of bytes currently buffered, which have
+// not yet been written to the underlying io.WriteCloser.
+func (lw *BatchLineWriter) Buffered() int {
+	lw.mu.Lock()
+	defer lw.mu.Unlock()
+	return lw.bufferLength()
+}
+
+// Available returns the number of bytes that may be written before the
+// buffer reaches the flush threshold, which is zero when the buffer
+// already holds at least that many bytes, such as when it holds a long
+// partial line.
+func (lw *BatchLineWriter) Available() int {
+	lw.mu.Lock()
+	defer lw.mu.Unlock()
+	if n := lw.flushThreshold - lw.bufferLength(); n > 0 {
+		return n
+	}
+	return 0
 }
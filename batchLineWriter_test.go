@@ -2,6 +2,7 @@ package gonl
 
 import (
 	"bytes"
+	"errors"
 	"fmt"
 	"io"
 	"testing"
@@ -49,12 +50,11 @@ func ExampleBatchLineWriter() {
 
 	source := bytes.NewReader(novel)
 
-	// bytes.Buffer does not provide a Close method, therefore need to
-	// wrap it with a structure that provides a no-op Close method.
+	// bytes.Buffer does not provide a Close method, so Close only
+	// flushes the remaining data to it.
 	bb := new(bytes.Buffer)
-	destination := NopCloseWriter(bb)
 
-	lw, err := NewBatchLineWriter(destination, threshold)
+	lw, err := NewBatchLineWriterTo(bb, threshold)
 	if err != nil {
 		panic(err)
 	}
@@ -80,16 +80,37 @@ func TestBatchLineWriter(t *testing.T) {
 	// write error vs no write error
 
 	t.Run("NewBatchLineWriter", func(t *testing.T) {
-		_, err := NewBatchLineWriter(new(discardWriteCloser), 0)
+		_, err := NewBatchLineWriter(new(DiscardCounter), 0)
 		ensureError(t, err, "flushThreshold")
 
-		_, err = NewBatchLineWriter(new(discardWriteCloser), -1)
+		_, err = NewBatchLineWriter(new(DiscardCounter), -1)
 		ensureError(t, err, "flushThreshold")
 	})
 
+	t.Run("NewBatchLineWriterCap", func(t *testing.T) {
+		_, err := NewBatchLineWriterCap(new(DiscardCounter), 0, 16)
+		ensureError(t, err, "flushThreshold")
+
+		_, err = NewBatchLineWriterCap(new(DiscardCounter), 16, 8)
+		ensureError(t, err, "initialCap")
+
+		output := new(testBuffer)
+		lw, err := NewBatchLineWriterCap(output, 8, 64)
+		ensureErrorNil(t, err)
+		if got, want := cap(lw.buf), 64; got != want {
+			t.Errorf("GOT: %v; WANT: %v", got, want)
+		}
+
+		ensureWrite(t, lw, "line 1\nline 2\nline 3")
+		ensureStringer(t, output, "line 1\nline 2\n")
+		if got, want := cap(lw.buf), 64; got != want {
+			t.Errorf("GOT: %v; WANT: %v", got, want)
+		}
+	})
+
 	t.Run("Close", func(t *testing.T) {
 		t.Run("no error", func(t *testing.T) {
-			wc, err := NewBatchLineWriter(new(discardWriteCloser), 16)
+			wc, err := NewBatchLineWriter(new(DiscardCounter), 16)
 			ensureErrorNil(t, err)
 			ensureWrite(t, wc, "line 1\n")
 			ensureError(t, wc.Close())
@@ -107,7 +128,7 @@ func TestBatchLineWriter(t *testing.T) {
 			ensureError(t, wc.Close(), "test close error")
 		})
 		t.Run("write error during close", func(t *testing.T) {
-			wc, err := NewBatchLineWriter(new(discardWriteCloser), 16)
+			wc, err := NewBatchLineWriter(new(DiscardCounter), 16)
 			ensureErrorNil(t, err)
 			ensureError(t, wc.Close())
 		})
@@ -115,7 +136,7 @@ func TestBatchLineWriter(t *testing.T) {
 
 	t.Run("flushCompleted", func(t *testing.T) {
 		t.Run("buf has no newlines", func(t *testing.T) {
-			wc, err := NewBatchLineWriter(new(discardWriteCloser), 16)
+			wc, err := NewBatchLineWriter(new(DiscardCounter), 16)
 			ensureErrorNil(t, err)
 			ensureWrite(t, wc, "line 1")
 			n, err := wc.flushCompleted(0, 0, 0)
@@ -1426,6 +1447,752 @@ func TestBatchLineWriter(t *testing.T) {
 		})
 	})
 
+	t.Run("WriteLines", func(t *testing.T) {
+		t.Run("no error", func(t *testing.T) {
+			output := new(testBuffer)
+			lw, err := NewBatchLineWriter(output, 8)
+			ensureErrorNil(t, err)
+
+			nw, err := lw.WriteLines([][]byte{[]byte("line 1"), []byte("line 2"), []byte("line 3")})
+			ensureErrorNil(t, err)
+			if got, want := nw, 21; got != want {
+				t.Errorf("GOT: %v; WANT: %v", got, want)
+			}
+			ensureStringer(t, output, "line 1\nline 2\n")
+
+			ensureErrorNil(t, lw.Close())
+			ensureStringer(t, output, "line 1\nline 2\nline 3\n")
+		})
+		t.Run("error", func(t *testing.T) {
+			output := new(testBuffer)
+			lw, err := NewBatchLineWriter(NopCloseWriter(ShortWriter(output, 4)), 8)
+			ensureErrorNil(t, err)
+
+			nw, err := lw.WriteLines([][]byte{[]byte("line 1"), []byte("line 2")})
+			ensureError(t, err, io.ErrShortWrite.Error())
+			if got, want := nw, 7; got != want {
+				t.Errorf("GOT: %v; WANT: %v", got, want)
+			}
+			ensureStringer(t, output, "line")
+		})
+	})
+
+	t.Run("WriteString", func(t *testing.T) {
+		t.Run("flushes completed lines", func(t *testing.T) {
+			output := new(testBuffer)
+			lw, err := NewBatchLineWriter(output, 8)
+			ensureErrorNil(t, err)
+
+			nw, err := io.WriteString(lw, "line 1\nline 2\npartial")
+			ensureErrorNil(t, err)
+			if got, want := nw, 21; got != want {
+				t.Errorf("GOT: %v; WANT: %v", got, want)
+			}
+			ensureStringer(t, output, "line 1\nline 2\n")
+
+			ensureErrorNil(t, lw.Close())
+			ensureStringer(t, output, "line 1\nline 2\npartial")
+
+			_, err = lw.WriteString("line 3\n")
+			if !errors.Is(err, ErrClosed) {
+				t.Errorf("GOT: %v; WANT: %v", err, ErrClosed)
+			}
+		})
+
+		t.Run("MaxLineLength", func(t *testing.T) {
+			output := new(testBuffer)
+			lw, err := NewBatchLineWriterOptions(output, WithFlushThreshold(64), WithMaxLineLength(4, true))
+			ensureErrorNil(t, err)
+
+			_, err = lw.WriteString("line 1\n")
+			ensureErrorNil(t, err)
+			ensureErrorNil(t, lw.Close())
+			ensureStringer(t, output, "line\n")
+		})
+
+		t.Run("does not allocate", func(t *testing.T) {
+			lw, err := NewBatchLineWriter(new(DiscardCounter), 64)
+			ensureErrorNil(t, err)
+
+			allocs := testing.AllocsPerRun(100, func() {
+				_, _ = lw.WriteString("line of output\n")
+			})
+			if allocs != 0 {
+				t.Errorf("GOT: %v; WANT: %v", allocs, 0)
+			}
+		})
+	})
+
+	t.Run("WriteStringLines", func(t *testing.T) {
+		output := new(testBuffer)
+		lw, err := NewBatchLineWriter(output, 64)
+		ensureErrorNil(t, err)
+
+		nw, err := lw.WriteStringLines([]string{"line 1", "line 2"})
+		ensureErrorNil(t, err)
+		if got, want := nw, 14; got != want {
+			t.Errorf("GOT: %v; WANT: %v", got, want)
+		}
+		ensureStringer(t, output, "")
+
+		ensureErrorNil(t, lw.Close())
+		ensureStringer(t, output, "line 1\nline 2\n")
+	})
+
+	t.Run("FlushOnlyOnClose", func(t *testing.T) {
+		t.Run("Write", func(t *testing.T) {
+			output := new(testBuffer)
+			lw, err := NewBatchLineWriter(output, 4)
+			ensureErrorNil(t, err)
+			lw.FlushOnlyOnClose = true
+
+			ensureWrite(t, lw, "line 1\n")
+			ensureWrite(t, lw, "line 2\nline 3")
+			ensureStringer(t, output, "")
+
+			ensureErrorNil(t, lw.Close())
+			ensureStringer(t, output, "line 1\nline 2\nline 3")
+		})
+		t.Run("ReadFrom", func(t *testing.T) {
+			r := &testReader{tuples: []tuple{
+				tuple{"line 1\n", nil},
+				tuple{"line 2\n", io.EOF},
+			}}
+			output := new(testBuffer)
+			lw, err := NewBatchLineWriter(output, 4)
+			ensureErrorNil(t, err)
+			lw.FlushOnlyOnClose = true
+
+			_, err = lw.ReadFrom(r)
+			ensureErrorNil(t, err)
+			ensureStringer(t, output, "")
+
+			ensureErrorNil(t, lw.Close())
+			ensureStringer(t, output, "line 1\nline 2\n")
+		})
+	})
+
+	t.Run("OnError", func(t *testing.T) {
+		t.Run("not invoked without error", func(t *testing.T) {
+			var count int
+			lw, err := NewBatchLineWriter(new(testBuffer), 4)
+			ensureErrorNil(t, err)
+			lw.OnError = func(_ error) { count++ }
+
+			ensureWrite(t, lw, "line 1\nline 2")
+			ensureErrorNil(t, lw.Close())
+			if got, want := count, 0; got != want {
+				t.Errorf("GOT: %v; WANT: %v", got, want)
+			}
+		})
+		t.Run("write error", func(t *testing.T) {
+			var errs []error
+			lw, err := NewBatchLineWriter(&errOnWrite{}, 4)
+			ensureErrorNil(t, err)
+			lw.OnError = func(err error) { errs = append(errs, err) }
+
+			_, err = lw.Write([]byte("line 1\n"))
+			ensureError(t, err, "test write error")
+			if got, want := len(errs), 1; got != want {
+				t.Fatalf("GOT: %v; WANT: %v", got, want)
+			}
+			ensureError(t, errs[0], "test write error")
+		})
+		t.Run("close error", func(t *testing.T) {
+			var errs []error
+			lw, err := NewBatchLineWriter(&errOnClose{}, 4)
+			ensureErrorNil(t, err)
+			lw.OnError = func(err error) { errs = append(errs, err) }
+
+			ensureWrite(t, lw, "line 1")
+			ensureError(t, lw.Close(), "test close error")
+			if got, want := len(errs), 1; got != want {
+				t.Fatalf("GOT: %v; WANT: %v", got, want)
+			}
+			ensureError(t, errs[0], "test close error")
+		})
+	})
+
+	t.Run("ErrorHandler", func(t *testing.T) {
+		t.Run("converts error", func(t *testing.T) {
+			var observed error
+			lw, err := NewBatchLineWriter(&errOnWrite{}, 4)
+			ensureErrorNil(t, err)
+			lw.OnError = func(err error) { observed = err }
+			lw.ErrorHandler = func(err error) error { return fmt.Errorf("audit log: %w", err) }
+
+			_, err = lw.Write([]byte("line 1\n"))
+			ensureError(t, err, "audit log: test write error")
+			ensureError(t, observed, "test write error")
+			ensureError(t, lw.Close(), "audit log: test close error")
+		})
+		t.Run("suppressed error keeps unwritten bytes", func(t *testing.T) {
+			output := new(testBuffer)
+			lw, err := NewBatchLineWriter(NopCloseWriter(ShortWriter(output, 4)), 4)
+			ensureErrorNil(t, err)
+			lw.ErrorHandler = func(err error) error {
+				if errors.Is(err, io.ErrShortWrite) {
+					return nil
+				}
+				return err
+			}
+
+			ensureWrite(t, lw, "line 1\n")
+			ensureStringer(t, output, "line")
+			ensureErrorNil(t, lw.Close())
+			ensureStringer(t, output, "line 1\n")
+			if got, want := lw.Stats().Errors, int64(1); got != want {
+				t.Errorf("GOT: %v; WANT: %v", got, want)
+			}
+		})
+		t.Run("suppressed error keeps unwritten bytes with WriteBuffers", func(t *testing.T) {
+			for _, max := range []int{1, 4} {
+				output := &testBuffersWriter{max: max}
+				lw, err := NewBatchLineWriter(output, 4)
+				ensureErrorNil(t, err)
+				lw.ErrorHandler = func(error) error { return nil }
+
+				ensureWrite(t, lw, "ab")
+				ensureWrite(t, lw, "cdef\n")
+				if got, want := output.bufferWrites, 1; got != want {
+					t.Errorf("GOT: %v; WANT: %v", got, want)
+				}
+				ensureErrorNil(t, lw.Close())
+				ensureStringer(t, output, "abcdef\n")
+			}
+		})
+	})
+
+	t.Run("after Close", func(t *testing.T) {
+		output := new(DiscardCounter)
+		lw, err := NewBatchLineWriter(output, 4)
+		ensureErrorNil(t, err)
+		ensureErrorNil(t, lw.Close())
+
+		n, err := lw.Write([]byte("line 1\n"))
+		if !errors.Is(err, ErrClosed) || !errors.Is(err, io.ErrClosedPipe) {
+			t.Errorf("GOT: %v; WANT: %v", err, ErrClosed)
+		}
+		if got, want := n, 0; got != want {
+			t.Errorf("GOT: %v; WANT: %v", got, want)
+		}
+
+		_, err = lw.WriteLines([][]byte{[]byte("line 2")})
+		if !errors.Is(err, ErrClosed) {
+			t.Errorf("GOT: %v; WANT: %v", err, ErrClosed)
+		}
+
+		nr, err := lw.ReadFrom(&testReader{})
+		if !errors.Is(err, ErrClosed) {
+			t.Errorf("GOT: %v; WANT: %v", err, ErrClosed)
+		}
+		if got, want := nr, int64(0); got != want {
+			t.Errorf("GOT: %v; WANT: %v", got, want)
+		}
+
+		if err = lw.Close(); !errors.Is(err, ErrClosed) {
+			t.Errorf("GOT: %v; WANT: %v", err, ErrClosed)
+		}
+
+		if got, want := output.Count, 0; got != want {
+			t.Errorf("GOT: %v; WANT: %v", got, want)
+		}
+	})
+
+	t.Run("PeekLines", func(t *testing.T) {
+		output := new(testBuffer)
+		lw, err := NewBatchLineWriter(output, 64)
+		ensureErrorNil(t, err)
+
+		if got, want := len(lw.PeekLines()), 0; got != want {
+			t.Errorf("GOT: %v; WANT: %v", got, want)
+		}
+
+		ensureWrite(t, lw, "line 1\n\nline 3\nline 4")
+		lines := lw.PeekLines()
+		want := []string{"line 1", "", "line 3"}
+		if got, want := len(lines), len(want); got != want {
+			t.Fatalf("GOT: %v; WANT: %v", got, want)
+		}
+		for i := range want {
+			if got, want := string(lines[i]), want[i]; got != want {
+				t.Errorf("GOT: %q; WANT: %q", got, want)
+			}
+		}
+
+		// Buffer is unchanged, and returned lines do not alias it.
+		lines[0][0] = 'X'
+		if got, want := lw.bufferString(), "line 1\n\nline 3\nline 4"; got != want {
+			t.Errorf("GOT: %q; WANT: %q", got, want)
+		}
+		ensureStringer(t, output, "")
+	})
+
+	t.Run("PendingLines", func(t *testing.T) {
+		output := new(testBuffer)
+		lw, err := NewBatchLineWriterOptions(output, WithFlushThreshold(16), WithDelimiterBytes(crlf), WithHoldLines(1))
+		ensureErrorNil(t, err)
+
+		if got, want := lw.PendingLines(), 0; got != want {
+			t.Errorf("GOT: %v; WANT: %v", got, want)
+		}
+		ensureWrite(t, lw, "a\r\nb\r")
+		if got, want := lw.PendingLines(), 1; got != want {
+			t.Errorf("GOT: %v; WANT: %v", got, want)
+		}
+		ensureWrite(t, lw, "\nc")
+		if got, want := lw.PendingLines(), 2; got != want {
+			t.Errorf("GOT: %v; WANT: %v", got, want)
+		}
+		ensureErrorNil(t, lw.Flush())
+		ensureStringer(t, output, "a\r\n")
+		if got, want := lw.PendingLines(), 1; got != want {
+			t.Errorf("GOT: %v; WANT: %v", got, want)
+		}
+		ensureErrorNil(t, lw.Close())
+		if got, want := lw.PendingLines(), 0; got != want {
+			t.Errorf("GOT: %v; WANT: %v", got, want)
+		}
+	})
+
+	t.Run("FlushPartialWhenFull", func(t *testing.T) {
+		t.Run("disabled", func(t *testing.T) {
+			output := new(testBuffer)
+			lw, err := NewBatchLineWriter(output, 4)
+			ensureErrorNil(t, err)
+
+			ensureWrite(t, lw, "0123456789")
+			ensureStringer(t, output, "")
+		})
+		t.Run("Write", func(t *testing.T) {
+			output := new(testBuffer)
+			lw, err := NewBatchLineWriter(output, 4)
+			ensureErrorNil(t, err)
+			lw.FlushPartialWhenFull = true
+
+			ensureWrite(t, lw, "01")
+			ensureStringer(t, output, "")
+			ensureWrite(t, lw, "23456789")
+			ensureStringer(t, output, "0123456789")
+
+			// Newline still preferred when one is available.
+			ensureWrite(t, lw, "ab\ncdef")
+			ensureStringer(t, output, "0123456789ab\n")
+			if got, want := lw.bufferString(), "cdef"; got != want {
+				t.Errorf("GOT: %q; WANT: %q", got, want)
+			}
+		})
+		t.Run("ReadFrom", func(t *testing.T) {
+			output := new(testBuffer)
+			lw, err := NewBatchLineWriter(output, 4)
+			ensureErrorNil(t, err)
+			lw.FlushPartialWhenFull = true
+
+			_, err = lw.ReadFrom(&testReader{tuples: []tuple{
+				tuple{"0123456789", nil},
+				tuple{"ab", io.EOF},
+			}})
+			ensureErrorNil(t, err)
+			ensureStringer(t, output, "0123456789")
+		})
+	})
+
+	t.Run("introspection", func(t *testing.T) {
+		lw, err := NewBatchLineWriter(new(DiscardCounter), 42)
+		ensureErrorNil(t, err)
+		if got, want := lw.Delimiter(), byte('\n'); got != want {
+			t.Errorf("GOT: %q; WANT: %q", got, want)
+		}
+		if got, want := lw.Size(), 42; got != want {
+			t.Errorf("GOT: %v; WANT: %v", got, want)
+		}
+	})
+
+	t.Run("Buffered and Available", func(t *testing.T) {
+		output := new(testBuffer)
+		lw, err := NewBatchLineWriter(output, 16)
+		ensureErrorNil(t, err)
+
+		ensureBuffered := func(buffered, available int) {
+			t.Helper()
+			if got, want := lw.Buffered(), buffered; got != want {
+				t.Errorf("Buffered GOT: %v; WANT: %v", got, want)
+			}
+			if got, want := lw.Available(), available; got != want {
+				t.Errorf("Available GOT: %v; WANT: %v", got, want)
+			}
+		}
+
+		ensureBuffered(0, 16)
+		ensureWrite(t, lw, "line 1\n")
+		ensureBuffered(7, 9)
+		ensureWrite(t, lw, "line 2\nline 3")
+		ensureStringer(t, output, "line 1\nline 2\n")
+		ensureBuffered(6, 10)
+		ensureWrite(t, lw, " is much longer than the threshold")
+		ensureBuffered(40, 0)
+		ensureErrorNil(t, lw.Close())
+		ensureBuffered(0, 16)
+	})
+
+	t.Run("JoinContinuations", func(t *testing.T) {
+		t.Run("Write", func(t *testing.T) {
+			output := new(testBuffer)
+			lw, err := NewBatchLineWriter(output, 64)
+			ensureErrorNil(t, err)
+			lw.JoinContinuations = true
+
+			ensureWrite(t, lw, "one \\\ntwo\nthree \\")
+			ensureWrite(t, lw, "\nfour\n")
+			ensureErrorNil(t, lw.Close())
+			ensureStringer(t, output, "one two\nthree four\n")
+		})
+		t.Run("custom byte", func(t *testing.T) {
+			output := new(testBuffer)
+			lw, err := NewBatchLineWriter(output, 64)
+			ensureErrorNil(t, err)
+			lw.JoinContinuations = true
+			lw.ContinuationByte = '&'
+
+			ensureWrite(t, lw, "one &\ntwo \\\n")
+			ensureErrorNil(t, lw.Close())
+			ensureStringer(t, output, "one two \\\n")
+		})
+		t.Run("newline alone", func(t *testing.T) {
+			output := new(testBuffer)
+			lw, err := NewBatchLineWriter(output, 64)
+			ensureErrorNil(t, err)
+			lw.JoinContinuations = true
+
+			ensureWrite(t, lw, "one \\")
+			ensureWrite(t, lw, "\n")
+			ensureWrite(t, lw, "two\n")
+			ensureErrorNil(t, lw.Close())
+			ensureStringer(t, output, "one two\n")
+		})
+		t.Run("joined continuation byte", func(t *testing.T) {
+			output := new(testBuffer)
+			lw, err := NewBatchLineWriter(output, 64)
+			ensureErrorNil(t, err)
+			lw.JoinContinuations = true
+
+			ensureWrite(t, lw, "a\\\\\n\nb\n")
+			ensureErrorNil(t, lw.Close())
+			ensureStringer(t, output, "a\\\nb\n")
+		})
+		t.Run("joined continuation byte across writes", func(t *testing.T) {
+			output := new(testBuffer)
+			lw, err := NewBatchLineWriter(output, 64)
+			ensureErrorNil(t, err)
+			lw.JoinContinuations = true
+
+			ensureWrite(t, lw, "a\\\\")
+			ensureWrite(t, lw, "\n")
+			ensureWrite(t, lw, "\nb\n")
+			ensureErrorNil(t, lw.Close())
+			ensureStringer(t, output, "a\\\nb\n")
+		})
+		t.Run("ReadFrom", func(t *testing.T) {
+			output := new(testBuffer)
+			lw, err := NewBatchLineWriter(output, 4)
+			ensureErrorNil(t, err)
+			lw.JoinContinuations = true
+			lw.CollectLineLengths = true
+
+			nr, err := lw.ReadFrom(&testReader{tuples: []tuple{
+				tuple{"ab\\", nil},
+				tuple{"\ncd\nef", io.EOF},
+			}})
+			ensureErrorNil(t, err)
+			if got, want := nr, int64(9); got != want {
+				t.Errorf("GOT: %v; WANT: %v", got, want)
+			}
+			ensureStringer(t, output, "abcd\n")
+
+			if got, want := lw.LineLengthHistogram()[4], 1; got != want {
+				t.Errorf("GOT: %v; WANT: %v", got, want)
+			}
+		})
+	})
+
+	t.Run("ReadFrom invalid read result", func(t *testing.T) {
+		lw, err := NewBatchLineWriter(new(DiscardCounter), 64)
+		ensureErrorNil(t, err)
+
+		n, err := lw.ReadFrom(overReader{})
+		ensureError(t, err, "invalid read result")
+		if got, want := n, int64(0); got != want {
+			t.Errorf("GOT: %v; WANT: %v", got, want)
+		}
+	})
+
+	t.Run("ReadFrom large totals", func(t *testing.T) {
+		const limit = 8<<20 + 3 // not a multiple of the read size
+		output := new(DiscardCounter)
+		lw, err := NewBatchLineWriter(output, 4096)
+		ensureErrorNil(t, err)
+
+		// Seed counters as if multiple terabytes had already been
+		// written, to ensure they accumulate without truncation.
+		const seed = int64(5) << 40
+		lw.counters.bytes = seed
+		lw.counters.lines = seed
+
+		line := []byte("The quick brown fox jumps over the lazy dog.\n")
+		n, err := lw.ReadFrom(&repeatReader{line: line, limit: limit})
+		ensureErrorNil(t, err)
+		if got, want := n, int64(limit); got != want {
+			t.Errorf("GOT: %v; WANT: %v", got, want)
+		}
+		ensureErrorNil(t, lw.Close())
+
+		if got, want := output.Count, limit; got != want {
+			t.Errorf("GOT: %v; WANT: %v", got, want)
+		}
+		if got, want := lw.counters.bytes, seed+limit; got != want {
+			t.Errorf("GOT: %v; WANT: %v", got, want)
+		}
+		if got, want := lw.counters.lines, seed+limit/int64(len(line)); got != want {
+			t.Errorf("GOT: %v; WANT: %v", got, want)
+		}
+	})
+
+	t.Run("ReadFrom huge totals", func(t *testing.T) {
+		if testing.Short() {
+			t.Skip("reads more than 4 GiB")
+		}
+		const size = 1 << 20
+		const limit = 5<<30 + 3 // exceeds 32 bits; final read is short
+		output := new(SyncDiscardCounter)
+		lw, err := NewBatchLineWriterCap(output, size, size)
+		ensureErrorNil(t, err)
+
+		n, err := lw.ReadFrom(&hugeReader{limit: limit})
+		ensureErrorNil(t, err)
+		ensureErrorNil(t, lw.Close())
+
+		if got, want := n, int64(limit); got != want {
+			t.Errorf("GOT: %v; WANT: %v", got, want)
+		}
+		if got, want := output.Count(), int64(limit); got != want {
+			t.Errorf("GOT: %v; WANT: %v", got, want)
+		}
+		stats := lw.Stats()
+		if got, want := stats.Bytes, int64(limit); got != want {
+			t.Errorf("GOT: %v; WANT: %v", got, want)
+		}
+		// Each read ends with a newline, and each fills the buffer
+		// except the final one.
+		if got, want := stats.Lines, int64(limit/size+1); got != want {
+			t.Errorf("GOT: %v; WANT: %v", got, want)
+		}
+	})
+
+	t.Run("HoldLines", func(t *testing.T) {
+		t.Run("Write", func(t *testing.T) {
+			output := new(testBuffer)
+			lw, err := NewBatchLineWriter(output, 1)
+			ensureErrorNil(t, err)
+			lw.HoldLines = 2
+
+			ensureWrite(t, lw, "line 1\nline 2\n")
+			ensureStringer(t, output, "")
+			ensureWrite(t, lw, "line 3\n")
+			ensureStringer(t, output, "line 1\n")
+			ensureWrite(t, lw, "line 4\nline 5\nline")
+			ensureStringer(t, output, "line 1\nline 2\nline 3\n")
+			ensureWrite(t, lw, " 6\n")
+			ensureStringer(t, output, "line 1\nline 2\nline 3\nline 4\n")
+			ensureErrorNil(t, lw.Close())
+			ensureStringer(t, output, "line 1\nline 2\nline 3\nline 4\nline 5\nline 6\n")
+		})
+
+		t.Run("threshold", func(t *testing.T) {
+			output := new(testBuffer)
+			lw, err := NewBatchLineWriter(output, 16)
+			ensureErrorNil(t, err)
+			lw.HoldLines = 1
+
+			ensureWrite(t, lw, "a\nb\nc\n")
+			ensureStringer(t, output, "")
+			ensureWrite(t, lw, "line 4\nline 5\n")
+			ensureStringer(t, output, "a\nb\nc\nline 4\n")
+			ensureErrorNil(t, lw.Close())
+			ensureStringer(t, output, "a\nb\nc\nline 4\nline 5\n")
+		})
+
+		t.Run("ReadFrom", func(t *testing.T) {
+			output := new(testBuffer)
+			lw, err := NewBatchLineWriter(output, 1)
+			ensureErrorNil(t, err)
+			lw.HoldLines = 1
+
+			_, err = lw.ReadFrom(&testReader{tuples: []tuple{
+				tuple{"line 1\n", nil},
+				tuple{"line 2\nline 3\n", nil},
+				tuple{"line 4", io.EOF},
+			}})
+			ensureErrorNil(t, err)
+			ensureStringer(t, output, "line 1\nline 2\n")
+			ensureErrorNil(t, lw.Close())
+			ensureStringer(t, output, "line 1\nline 2\nline 3\nline 4")
+		})
+
+		t.Run("buffers writer", func(t *testing.T) {
+			output := &testBuffersWriter{}
+			lw, err := NewBatchLineWriter(output, 1)
+			ensureErrorNil(t, err)
+			lw.HoldLines = 1
+
+			ensureWrite(t, lw, "line 1\nline 2\n")
+			ensureStringer(t, output, "line 1\n")
+			ensureErrorNil(t, lw.Close())
+			ensureStringer(t, output, "line 1\nline 2\n")
+		})
+	})
+
+	t.Run("delimiter", func(t *testing.T) {
+		t.Run("Write", func(t *testing.T) {
+			output := new(testBuffer)
+			lw, err := NewBatchLineWriterDelim(output, 4, ';')
+			ensureErrorNil(t, err)
+			if got, want := lw.Delimiter(), byte(';'); got != want {
+				t.Errorf("GOT: %q; WANT: %q", got, want)
+			}
+
+			ensureWrite(t, lw, "a=1;b=2\n;c")
+			ensureStringer(t, output, "a=1;b=2\n;")
+			ensureWrite(t, lw, "=3\n")
+			ensureStringer(t, output, "a=1;b=2\n;")
+			ensureErrorNil(t, lw.Close())
+			ensureStringer(t, output, "a=1;b=2\n;c=3\n")
+		})
+
+		t.Run("ReadFrom", func(t *testing.T) {
+			output := new(testBuffer)
+			lw, err := NewBatchLineWriterDelim(output, 4, 0x1E)
+			ensureErrorNil(t, err)
+
+			_, err = lw.ReadFrom(&testReader{tuples: []tuple{
+				tuple{"rec 1\x1erec\n2", nil},
+				tuple{"\x1erec 3", io.EOF},
+			}})
+			ensureErrorNil(t, err)
+			ensureStringer(t, output, "rec 1\x1erec\n2\x1e")
+			ensureErrorNil(t, lw.Close())
+			ensureStringer(t, output, "rec 1\x1erec\n2\x1erec 3")
+		})
+
+		t.Run("WriteLines", func(t *testing.T) {
+			output := new(testBuffer)
+			lw, err := NewBatchLineWriterDelim(output, 64, ';')
+			ensureErrorNil(t, err)
+
+			_, err = lw.WriteStringLines([]string{"a", "b"})
+			ensureErrorNil(t, err)
+			if got, want := len(lw.PeekLines()), 2; got != want {
+				t.Errorf("GOT: %v; WANT: %v", got, want)
+			}
+			ensureErrorNil(t, lw.Close())
+			ensureStringer(t, output, "a;b;")
+		})
+	})
+
+	t.Run("multi-byte delimiter", func(t *testing.T) {
+		t.Run("empty", func(t *testing.T) {
+			_, err := NewBatchLineWriterDelimBytes(new(DiscardCounter), 1, nil)
+			ensureError(t, err, "delimiter is empty")
+		})
+
+		t.Run("Write", func(t *testing.T) {
+			output := new(testBuffer)
+			lw, err := NewBatchLineWriterDelimBytes(output, 1, crlf)
+			ensureErrorNil(t, err)
+			lw.CollectLineLengths = true
+			if got, want := string(lw.DelimiterBytes()), "\r\n"; got != want {
+				t.Errorf("GOT: %q; WANT: %q", got, want)
+			}
+			if got, want := lw.Delimiter(), byte('\n'); got != want {
+				t.Errorf("GOT: %q; WANT: %q", got, want)
+			}
+
+			ensureWrite(t, lw, "a\nb\r")
+			ensureStringer(t, output, "")
+			ensureWrite(t, lw, "\ncd\r\n\r")
+			ensureStringer(t, output, "a\nb\r\ncd\r\n")
+			ensureErrorNil(t, lw.Close())
+			ensureStringer(t, output, "a\nb\r\ncd\r\n\r")
+
+			if got, want := lw.LineLengthHistogram()[4], 1; got != want {
+				t.Errorf("GOT: %v; WANT: %v", got, want)
+			}
+			if got, want := lw.LineLengthHistogram()[2], 1; got != want {
+				t.Errorf("GOT: %v; WANT: %v", got, want)
+			}
+		})
+
+		t.Run("ReadFrom", func(t *testing.T) {
+			output := new(testBuffer)
+			lw, err := NewBatchLineWriterDelimBytes(output, 1, crlf)
+			ensureErrorNil(t, err)
+
+			_, err = lw.ReadFrom(&testReader{tuples: []tuple{
+				tuple{"line 1\r", nil},
+				tuple{"\nline 2\r", nil},
+				tuple{"\n", io.EOF},
+			}})
+			ensureErrorNil(t, err)
+			ensureStringer(t, output, "line 1\r\nline 2\r\n")
+			ensureErrorNil(t, lw.Close())
+		})
+
+		t.Run("buffers writer", func(t *testing.T) {
+			output := &testBuffersWriter{}
+			lw, err := NewBatchLineWriterDelimBytes(output, 1, crlf)
+			ensureErrorNil(t, err)
+			lw.PublishExpvar(expvarName(t))
+
+			ensureWrite(t, lw, "a\r")
+			ensureWrite(t, lw, "\nb\r\nc\r")
+			ensureStringer(t, output, "a\r\nb\r\n")
+			if got, want := lw.counters.lines, int64(2); got != want {
+				t.Errorf("GOT: %v; WANT: %v", got, want)
+			}
+			ensureErrorNil(t, lw.Close())
+			ensureStringer(t, output, "a\r\nb\r\nc\r")
+		})
+
+		t.Run("PeekLines and HoldLines", func(t *testing.T) {
+			output := new(testBuffer)
+			lw, err := NewBatchLineWriterDelimBytes(output, 1, crlf)
+			ensureErrorNil(t, err)
+			lw.HoldLines = 1
+
+			ensureWrite(t, lw, "a\r\nb\r\n")
+			ensureStringer(t, output, "a\r\n")
+			lines := lw.PeekLines()
+			if got, want := len(lines), 1; got != want {
+				t.Fatalf("GOT: %v; WANT: %v", got, want)
+			}
+			if got, want := string(lines[0]), "b"; got != want {
+				t.Errorf("GOT: %q; WANT: %q", got, want)
+			}
+			ensureErrorNil(t, lw.Close())
+			ensureStringer(t, output, "a\r\nb\r\n")
+		})
+	})
+
+	t.Run("NUL delimiter", func(t *testing.T) {
+		output := new(testBuffer)
+		lw, err := NewBatchLineWriterDelim(output, 1, NUL)
+		ensureErrorNil(t, err)
+
+		ensureWrite(t, lw, "file\nname 1\x00file 2")
+		ensureStringer(t, output, "file\nname 1\x00")
+		ensureErrorNil(t, lw.Close())
+		ensureStringer(t, output, "file\nname 1\x00file 2")
+	})
+
 	t.Run("digest", func(t *testing.T) {
 		// ??? not really worried about true message authentication
 		// codes. Just want to shove data into an io.Writer that does a
@@ -1,10 +1,12 @@
 package gonl
 
 import (
+	"bufio"
 	"bytes"
 	_ "embed"
 	"errors"
 	"io"
+	"net"
 	"testing"
 )
 
@@ -72,7 +74,7 @@ func BenchmarkDiscardWrites(b *testing.B) {
 
 		b.Run("ReadFrom", func(b *testing.B) {
 			for i := 0; i < b.N; i++ {
-				drain := new(discardWriteCloser)
+				drain := new(DiscardCounter)
 
 				output, err := NewBatchLineWriter(drain, bufSize)
 				if err != nil {
@@ -88,7 +90,7 @@ func BenchmarkDiscardWrites(b *testing.B) {
 					b.Fatal(err)
 				}
 
-				if got, want := drain.count, len(novel); got != want {
+				if got, want := drain.Count, len(novel); got != want {
 					b.Errorf("GOT: %v; WANT: %v", got, want)
 				}
 			}
@@ -97,7 +99,7 @@ func BenchmarkDiscardWrites(b *testing.B) {
 			buf := make([]byte, bufSize)
 
 			for i := 0; i < b.N; i++ {
-				drain := new(discardWriteCloser)
+				drain := new(DiscardCounter)
 
 				output, err := NewBatchLineWriter(drain, bufSize)
 				if err != nil {
@@ -113,7 +115,7 @@ func BenchmarkDiscardWrites(b *testing.B) {
 					b.Fatal(err)
 				}
 
-				if got, want := drain.count, len(novel); got != want {
+				if got, want := drain.Count, len(novel); got != want {
 					b.Errorf("GOT: %v; WANT: %v", got, want)
 				}
 			}
@@ -131,7 +133,7 @@ func BenchmarkDiscardWrites(b *testing.B) {
 		// io.WriteCloser.
 		b.Run("ReadFrom", func(b *testing.B) {
 			for i := 0; i < b.N; i++ {
-				drain := new(discardWriteCloser)
+				drain := new(DiscardCounter)
 				output := &PerLineWriter{WC: drain}
 
 				_, err := output.ReadFrom(bytes.NewReader(novel))
@@ -143,7 +145,7 @@ func BenchmarkDiscardWrites(b *testing.B) {
 					b.Fatal(err)
 				}
 
-				if got, want := drain.count, len(novel); got != want {
+				if got, want := drain.Count, len(novel); got != want {
 					b.Errorf("GOT: %v; WANT: %v", got, want)
 				}
 			}
@@ -152,7 +154,7 @@ func BenchmarkDiscardWrites(b *testing.B) {
 			buf := make([]byte, bufSize)
 
 			for i := 0; i < b.N; i++ {
-				drain := new(discardWriteCloser)
+				drain := new(DiscardCounter)
 				output := &PerLineWriter{WC: drain}
 
 				_, err := copyBuffer(output, bytes.NewReader(novel), buf)
@@ -164,7 +166,7 @@ func BenchmarkDiscardWrites(b *testing.B) {
 					b.Fatal(err)
 				}
 
-				if got, want := drain.count, len(novel); got != want {
+				if got, want := drain.Count, len(novel); got != want {
 					b.Errorf("GOT: %v; WANT: %v", got, want)
 				}
 			}
@@ -303,3 +305,129 @@ func BenchmarkHashWrites(b *testing.B) {
 		})
 	})
 }
+
+func BenchmarkLoopbackWrites(b *testing.B) {
+	// These benchmark functions contrast writing to a TCP connection
+	// using net.Buffers, which uses writev, with writing to the same
+	// connection hidden behind a structure that only provides Write.
+	lines := bytes.Repeat([]byte("The quick brown fox jumps over the lazy dog.\n"), 1<<14)
+
+	run := func(b *testing.B, wrap func(*net.TCPConn) io.WriteCloser) {
+		for i := 0; i < b.N; i++ {
+			conn, received := newLoopbackConn(b)
+
+			output, err := NewBatchLineWriter(wrap(conn), bufSize)
+			if err != nil {
+				b.Fatal(err)
+			}
+
+			_, err = copyBuffer(output, bytes.NewReader(lines), make([]byte, 4096))
+			if err != nil {
+				b.Fatal(err)
+			}
+
+			if err = output.Close(); err != nil {
+				b.Fatal(err)
+			}
+
+			if got, want := len(<-received), len(lines); got != want {
+				b.Fatalf("GOT: %v; WANT: %v", got, want)
+			}
+		}
+	}
+
+	b.Run("Buffers", func(b *testing.B) {
+		run(b, func(conn *net.TCPConn) io.WriteCloser { return conn })
+	})
+
+	b.Run("Write", func(b *testing.B) {
+		run(b, func(conn *net.TCPConn) io.WriteCloser {
+			return struct{ io.WriteCloser }{conn}
+		})
+	})
+}
+
+func BenchmarkBatchLineWriterPool(b *testing.B) {
+	// These benchmark functions contrast allocating a new
+	// BatchLineWriter for each short lived use with recycling them
+	// through a BatchLineWriterPool.
+	const threshold = 4096
+	lines := bytes.Repeat([]byte("The quick brown fox jumps over the lazy dog.\n"), 64)
+
+	b.Run("Fresh", func(b *testing.B) {
+		b.ReportAllocs()
+		b.RunParallel(func(pb *testing.PB) {
+			drain := new(DiscardCounter)
+			for pb.Next() {
+				lw, err := NewBatchLineWriter(drain, threshold)
+				if err != nil {
+					b.Error(err)
+					return
+				}
+				_, _ = lw.Write(lines)
+				if err = lw.Close(); err != nil {
+					b.Error(err)
+					return
+				}
+			}
+		})
+	})
+
+	b.Run("Pooled", func(b *testing.B) {
+		pool, err := NewBatchLineWriterPool(threshold)
+		if err != nil {
+			b.Fatal(err)
+		}
+		b.ReportAllocs()
+		b.RunParallel(func(pb *testing.PB) {
+			drain := new(DiscardCounter)
+			for pb.Next() {
+				lw := pool.Get(drain)
+				_, _ = lw.Write(lines)
+				if err := lw.Close(); err != nil {
+					b.Error(err)
+					return
+				}
+				pool.Put(lw)
+			}
+		})
+	})
+}
+
+func BenchmarkLineReaders(b *testing.B) {
+	// These benchmark functions contrast reading a stream of lines
+	// through a BatchLineReader with reading the same stream through
+	// a bufio.Reader of the same size, which does not respect line
+	// boundaries.
+	const size = 64 * 1024
+	lines := bytes.Repeat([]byte("The quick brown fox jumps over the lazy dog.\n"), 1<<14)
+
+	run := func(b *testing.B, wrap func(io.Reader) io.Reader) {
+		drain := new(DiscardCounter)
+		buf := make([]byte, bufSize)
+		for i := 0; i < b.N; i++ {
+			_, err := copyBuffer(drain, wrap(bytes.NewReader(lines)), buf)
+			if err != nil {
+				b.Fatal(err)
+			}
+			if got, want := drain.Count, len(lines); got != want {
+				b.Fatalf("GOT: %v; WANT: %v", got, want)
+			}
+			drain.Count = 0
+		}
+	}
+
+	b.Run("BatchLineReader", func(b *testing.B) {
+		run(b, func(r io.Reader) io.Reader {
+			br, err := NewBatchLineReader(r, size)
+			if err != nil {
+				b.Fatal(err)
+			}
+			return br
+		})
+	})
+
+	b.Run("bufio.Reader", func(b *testing.B) {
+		run(b, func(r io.Reader) io.Reader { return bufio.NewReaderSize(r, size) })
+	})
+}
@@ -7,23 +7,6 @@ import (
 	"io"
 )
 
-// discardWriteCloser is an io.WriteCloser that simply tracks how many
-// bytes have been written to it. Used in tests to provide a place to
-// write data to, and ensure expected number of bytes have been
-// written to it.
-type discardWriteCloser struct {
-	count int
-}
-
-func (dw *discardWriteCloser) Close() error {
-	return nil
-}
-
-func (dw *discardWriteCloser) Write(p []byte) (int, error) {
-	dw.count += len(p)
-	return len(p), nil
-}
-
 // testBuffer is an io.WriteCloser, and io.Reader, that maintains the
 // contents of the data written to it. Used in tests to be able to
 // spot check the contents of what has been written to it. Only reason
@@ -0,0 +1,97 @@
+package gonl
+
+import (
+	"errors"
+	"io"
+	"net"
+)
+
+// BuffersWriter is implemented by destinations that are able to write
+// multiple byte slices with a single call, for instance by using the
+// writev system call. When the io.WriteCloser given to
+// NewBatchLineWriter implements this interface, or is either a
+// *net.TCPConn or a *net.UnixConn, BatchLineWriter writes its buffered
+// bytes along with the completed lines from the bytes passed to Write
+// with a single call to it, eliminating the need to first copy those
+// lines into its buffer.
+type BuffersWriter interface {
+	WriteBuffers(bufs net.Buffers) (int64, error)
+}
+
+// buffersWriterFor returns a function that writes net.Buffers to w
+// using a single call, or nil when w does not support doing so.
+func buffersWriterFor(w io.Writer) func(net.Buffers) (int64, error) {
+	switch tw := underlying(w).(type) {
+	case BuffersWriter:
+		return tw.WriteBuffers
+	case *net.TCPConn, *net.UnixConn:
+		// net.Buffers uses writev when writing to these types.
+		return func(bufs net.Buffers) (int64, error) { return bufs.WriteTo(tw) }
+	}
+	return nil
+}
+
+// flushVectored writes the buffered bytes followed by p[:index] to
+// the underlying io.WriteCloser with a single call, then appends the
+// remainder of p to the buffer. Its return values follow the same
+// rules as flush.
+func (lw *BatchLineWriter) flushVectored(p []byte, index int) (int, error) {
+	leno := lw.bufferLength()
+
+	bufs := net.Buffers(lw.vectors[:0])
+	if leno > 0 {
+		bufs = append(bufs, lw.buf[lw.off:])
+	}
+	bufs = append(bufs, p[:index])
+
+	fp := lw.flushStart(leno + index)
+	nw64, err := lw.wcWriteBuffers(bufs)
+	lw.flushDone(fp, int(nw64), err, lw.buf[lw.off:], p[:index])
+	lw.vectors = [2][]byte{} // do not retain references to p
+	if nw64 < 0 || nw64 > int64(leno+index) {
+		return 0, lw.reportError(errors.New("invalid write result"))
+	}
+	nw := int(nw64)
+	if err == nil && nw < leno+index {
+		err = io.ErrShortWrite
+	}
+	err = lw.reportError(err)
+
+	if err == nil && nw < leno+index {
+		// ErrorHandler suppressed the error: keep the bytes not
+		// written, including all of p, buffered for the next flush.
+		rest := p
+		if nb := nw - leno; nb >= 0 {
+			lw.bufferReset()
+			rest = p[nb:]
+		} else {
+			lw.off += nw
+		}
+		m := lw.bufferGrow(len(rest))
+		copy(lw.buf[m:], rest)
+		lw.counters.noteBuffered(lw.bufferLength())
+		lw.indexOfFinalNewline = lw.finalDelim(lw.off)
+		return len(p), nil
+	}
+	if err == nil {
+		lw.bufferReset()
+		if rest := p[index:]; len(rest) > 0 {
+			m := lw.bufferGrow(len(rest))
+			copy(lw.buf[m:], rest)
+			lw.counters.noteBuffered(len(rest))
+		}
+		return len(p), nil
+	}
+
+	// nb is the number new bytes from p that got written.
+	if nb := nw - leno; nb >= 0 {
+		lw.bufferReset()
+		return nb, err
+	}
+
+	// Not all previously buffered bytes were written: keep the
+	// remainder, but report that no bytes of p were written.
+	lw.off += nw
+	lw.indexOfFinalNewline = lw.finalDelim(lw.off)
+	return 0, err
+}
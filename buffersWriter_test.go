@@ -0,0 +1,139 @@
+package gonl
+
+import (
+	"io"
+	"net"
+	"testing"
+)
+
+// testBuffersWriter is an io.WriteCloser that also implements
+// BuffersWriter, recording how many times each method is invoked and
+// writing at most max bytes per call when max is greater than 0.
+type testBuffersWriter struct {
+	testBuffer
+	max          int
+	writes       int
+	bufferWrites int
+}
+
+func (tw *testBuffersWriter) Write(p []byte) (int, error) {
+	tw.writes++
+	return tw.testBuffer.Write(p)
+}
+
+func (tw *testBuffersWriter) WriteBuffers(bufs net.Buffers) (int64, error) {
+	tw.bufferWrites++
+	var total int64
+	for _, buf := range bufs {
+		if tw.max > 0 && int(total)+len(buf) > tw.max {
+			n, _ := tw.testBuffer.Write(buf[:tw.max-int(total)])
+			return total + int64(n), io.ErrShortWrite
+		}
+		n, _ := tw.testBuffer.Write(buf)
+		total += int64(n)
+	}
+	return total, nil
+}
+
+func TestBatchLineWriterBuffers(t *testing.T) {
+	t.Run("detects BuffersWriter", func(t *testing.T) {
+		output := new(testBuffersWriter)
+		lw, err := NewBatchLineWriter(output, 8)
+		ensureErrorNil(t, err)
+
+		ensureWrite(t, lw, "line 1")
+		ensureWrite(t, lw, "\nline 2\nli")
+		ensureStringer(t, output, "line 1\nline 2\n")
+		if got, want := lw.bufferString(), "li"; got != want {
+			t.Errorf("GOT: %q; WANT: %q", got, want)
+		}
+		if got, want := output.bufferWrites, 1; got != want {
+			t.Errorf("GOT: %v; WANT: %v", got, want)
+		}
+
+		ensureWrite(t, lw, "ne 3")
+		ensureErrorNil(t, lw.Close())
+		ensureStringer(t, output, "line 1\nline 2\nline 3")
+	})
+
+	t.Run("short write of buffered bytes", func(t *testing.T) {
+		output := &testBuffersWriter{max: 3}
+		lw, err := NewBatchLineWriter(output, 8)
+		ensureErrorNil(t, err)
+
+		ensureWrite(t, lw, "line 1")
+		n, err := lw.Write([]byte("\nline 2\n"))
+		ensureError(t, err, io.ErrShortWrite.Error())
+		if got, want := n, 0; got != want {
+			t.Errorf("GOT: %v; WANT: %v", got, want)
+		}
+		ensureStringer(t, output, "lin")
+		if got, want := lw.bufferString(), "e 1"; got != want {
+			t.Errorf("GOT: %q; WANT: %q", got, want)
+		}
+	})
+
+	t.Run("short write of new bytes", func(t *testing.T) {
+		output := &testBuffersWriter{max: 9}
+		lw, err := NewBatchLineWriter(output, 8)
+		ensureErrorNil(t, err)
+
+		ensureWrite(t, lw, "line 1")
+		n, err := lw.Write([]byte("\nline 2\n"))
+		ensureError(t, err, io.ErrShortWrite.Error())
+		if got, want := n, 3; got != want {
+			t.Errorf("GOT: %v; WANT: %v", got, want)
+		}
+		ensureStringer(t, output, "line 1\nli")
+		if got, want := lw.bufferString(), ""; got != want {
+			t.Errorf("GOT: %q; WANT: %q", got, want)
+		}
+	})
+
+	t.Run("TCPConn", func(t *testing.T) {
+		client, received := newLoopbackConn(t)
+
+		lw, err := NewBatchLineWriter(client, 8)
+		ensureErrorNil(t, err)
+		if lw.writeBuffers == nil {
+			t.Fatal("GOT: nil; WANT: non-nil")
+		}
+
+		ensureWrite(t, lw, "line 1\nline 2\nline 3")
+		ensureErrorNil(t, lw.Close())
+		if got, want := string(<-received), "line 1\nline 2\nline 3"; got != want {
+			t.Errorf("GOT: %q; WANT: %q", got, want)
+		}
+	})
+}
+
+// newLoopbackConn returns the client side of a loopback TCP
+// connection, along with a channel that receives all bytes read by
+// the server side once the client side is closed.
+func newLoopbackConn(tb testing.TB) (*net.TCPConn, <-chan []byte) {
+	tb.Helper()
+
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		tb.Fatal(err)
+	}
+
+	received := make(chan []byte, 1)
+	go func() {
+		defer ln.Close()
+		conn, err := ln.Accept()
+		if err != nil {
+			received <- nil
+			return
+		}
+		buf, _ := io.ReadAll(conn)
+		_ = conn.Close()
+		received <- buf
+	}()
+
+	conn, err := net.Dial("tcp", ln.Addr().String())
+	if err != nil {
+		tb.Fatal(err)
+	}
+	return conn.(*net.TCPConn), received
+}
@@ -0,0 +1,130 @@
+package gonl
+
+import (
+	"bytes"
+	"io"
+)
+
+// Byte order marks recognized by BOMStripReader, in the order they are
+// tested.
+var (
+	BOMUTF8    = []byte{0xEF, 0xBB, 0xBF}
+	BOMUTF16BE = []byte{0xFE, 0xFF}
+	BOMUTF16LE = []byte{0xFF, 0xFE}
+)
+
+var byteOrderMarks = [][]byte{BOMUTF8, BOMUTF16BE, BOMUTF16LE}
+
+// BOMStripReader reads from the source io.Reader, removing a UTF-8,
+// UTF-16BE, or UTF-16LE byte order mark from the start of the stream,
+// such as those written by many Windows tools, so it does not end up
+// in the first line. It may be used as the source of any of the line
+// oriented readers in this package.
+//
+//	r := &gonl.LineBufferedReader{R: &gonl.BOMStripReader{R: f}}
+type BOMStripReader struct {
+	// R is the io.Reader from which data is read.
+	R io.Reader
+
+	// BOM is the byte order mark removed from the stream, or nil when
+	// the stream did not begin with one. It is only valid after the
+	// first Read returns, and may be used to choose a decoder for the
+	// remainder of the stream.
+	BOM []byte
+
+	started bool
+	pending []byte // bytes read while looking for a byte order mark
+	err     error
+}
+
+// Read reads up to len(p) bytes into p. It returns the number of bytes
+// read (0 <= n <= len(p)) and any error encountered.
+func (r *BOMStripReader) Read(p []byte) (int, error) {
+	if !r.started {
+		r.started = true
+		r.start()
+	}
+	if len(r.pending) > 0 {
+		n := copy(p, r.pending)
+		r.pending = r.pending[n:]
+		return n, nil
+	}
+	if r.err != nil {
+		err := r.err
+		r.err = nil
+		return 0, err
+	}
+	return r.R.Read(p)
+}
+
+// start reads from the source io.Reader until it has read enough bytes
+// to determine whether the stream begins with a byte order mark, which
+// it removes, retaining any other bytes read.
+func (r *BOMStripReader) start() {
+	head := make([]byte, minRead)
+	var n int
+	for {
+		for _, bom := range byteOrderMarks {
+			if bytes.HasPrefix(head[:n], bom) {
+				r.BOM = bom
+				r.pending = head[len(bom):n]
+				return
+			}
+		}
+		if r.err != nil || !isBOMPrefix(head[:n]) {
+			r.pending = head[:n]
+			return
+		}
+		var nr int
+		nr, r.err = r.R.Read(head[n:])
+		n += nr
+	}
+}
+
+// isBOMPrefix returns true when b is a proper prefix of a byte order
+// mark.
+func isBOMPrefix(b []byte) bool {
+	for _, bom := range byteOrderMarks {
+		if len(b) < len(bom) && bytes.HasPrefix(bom, b) {
+			return true
+		}
+	}
+	return false
+}
+
+// BOMWriter is an io.WriteCloser that writes BOM to the underlying
+// io.WriteCloser before the first bytes written to it, such as when
+// producing files for Windows tools that expect a UTF-8 byte order
+// mark. To emit a byte order mark in another encoding, use the WriteBOM
+// field of EncodingWriter.
+//
+//	w := &gonl.BOMWriter{WC: f, BOM: gonl.BOMUTF8}
+type BOMWriter struct {
+	// WC is io.WriteCloser where data is ultimately written.
+	WC io.WriteCloser
+
+	// BOM is the byte order mark to write.
+	BOM []byte
+
+	written int // number of bytes of BOM written
+}
+
+// Close closes the underlying io.WriteCloser. Nothing is written when
+// Write was never invoked.
+func (w *BOMWriter) Close() error {
+	return w.WC.Close()
+}
+
+// Write writes p to the underlying io.WriteCloser, preceded by BOM the
+// first time it is invoked. It returns the number of bytes of p
+// written.
+func (w *BOMWriter) Write(p []byte) (int, error) {
+	for w.written < len(w.BOM) {
+		n, err := w.WC.Write(w.BOM[w.written:])
+		w.written += n
+		if err != nil {
+			return 0, err
+		}
+	}
+	return w.WC.Write(p)
+}
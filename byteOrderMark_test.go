@@ -0,0 +1,87 @@
+package gonl
+
+import (
+	"bytes"
+	"io"
+	"io/ioutil"
+	"strings"
+	"testing"
+)
+
+func TestBOMStripReader(t *testing.T) {
+	t.Run("boms", func(t *testing.T) {
+		for _, bom := range [][]byte{BOMUTF8, BOMUTF16BE, BOMUTF16LE} {
+			r := &BOMStripReader{R: strings.NewReader(string(bom) + "line 1\n")}
+			buf, err := ioutil.ReadAll(r)
+			ensureErrorNil(t, err)
+			if got, want := string(buf), "line 1\n"; got != want {
+				t.Errorf("GOT: %q; WANT: %q", got, want)
+			}
+			if got, want := r.BOM, bom; !bytes.Equal(got, want) {
+				t.Errorf("GOT: %v; WANT: %v", got, want)
+			}
+		}
+	})
+
+	t.Run("no bom", func(t *testing.T) {
+		r := &BOMStripReader{R: strings.NewReader("\xefline 1\n")}
+		buf, err := ioutil.ReadAll(r)
+		ensureErrorNil(t, err)
+		if got, want := string(buf), "\xefline 1\n"; got != want {
+			t.Errorf("GOT: %q; WANT: %q", got, want)
+		}
+		if r.BOM != nil {
+			t.Errorf("GOT: %v; WANT: %v", r.BOM, nil)
+		}
+	})
+
+	t.Run("bom split across reads", func(t *testing.T) {
+		r := &BOMStripReader{R: &testReader{tuples: []tuple{
+			{"\xef", nil},
+			{"\xbb", nil},
+			{"\xbfline 1\n", io.EOF},
+		}}}
+		lr := &LineBufferedReader{R: r}
+		buf, err := ioutil.ReadAll(lr)
+		ensureErrorNil(t, err)
+		if got, want := string(buf), "line 1\n"; got != want {
+			t.Errorf("GOT: %q; WANT: %q", got, want)
+		}
+	})
+
+	t.Run("short stream", func(t *testing.T) {
+		r := &BOMStripReader{R: &testReader{tuples: []tuple{
+			{"\xef", io.EOF},
+		}}}
+		buf, err := ioutil.ReadAll(r)
+		ensureErrorNil(t, err)
+		if got, want := string(buf), "\xef"; got != want {
+			t.Errorf("GOT: %q; WANT: %q", got, want)
+		}
+	})
+}
+
+func TestBOMWriter(t *testing.T) {
+	t.Run("writes bom once", func(t *testing.T) {
+		output := new(testBuffer)
+		w := &BOMWriter{WC: output, BOM: BOMUTF8}
+
+		ensureWrite(t, w, "line 1\n")
+		ensureWrite(t, w, "line 2\n")
+		ensureErrorNil(t, w.Close())
+		ensureStringer(t, output, "\xef\xbb\xbfline 1\nline 2\n")
+	})
+
+	t.Run("nothing written", func(t *testing.T) {
+		output := new(testBuffer)
+		w := &BOMWriter{WC: output, BOM: BOMUTF8}
+		ensureErrorNil(t, w.Close())
+		ensureStringer(t, output, "")
+	})
+
+	t.Run("write error", func(t *testing.T) {
+		w := &BOMWriter{WC: &errOnWrite{}, BOM: BOMUTF8}
+		_, err := w.Write([]byte("line 1\n"))
+		ensureError(t, err, "test write error")
+	})
+}
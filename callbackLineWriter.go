@@ -0,0 +1,33 @@
+package gonl
+
+// CallbackLineWriter is an io.WriteCloser that invokes Publish exactly
+// once for each complete line written to it, for destinations such as
+// message queue clients that accept individual records rather than
+// implementing io.Writer. Lines split across multiple Write calls are
+// reassembled before being published. When closed, any final line not
+// terminated by a newline is also published.
+type CallbackLineWriter struct {
+	// Publish is invoked with each line, excluding its terminating
+	// newline. The slice is only valid for the duration of the call,
+	// and must be copied when it needs to be retained.
+	Publish func(line []byte) error
+
+	la lineAssembler
+}
+
+// Close publishes any remaining partial line.
+func (cw *CallbackLineWriter) Close() error {
+	return cw.la.close(cw.emit)
+}
+
+// Write publishes each line completed by p. When Publish returns an
+// error, processing stops, and Write returns that error along with
+// the number of bytes of p that preceded the line that failed to
+// publish.
+func (cw *CallbackLineWriter) Write(p []byte) (int, error) {
+	return cw.la.write(p, cw.emit)
+}
+
+func (cw *CallbackLineWriter) emit(line []byte) error {
+	return cw.Publish(trimNewline(line))
+}
@@ -0,0 +1,50 @@
+package gonl
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestCallbackLineWriter(t *testing.T) {
+	t.Run("publishes each line", func(t *testing.T) {
+		var lines []string
+		cw := &CallbackLineWriter{Publish: func(line []byte) error {
+			lines = append(lines, string(line))
+			return nil
+		}}
+
+		ensureWrite(t, cw, "line 1\nli")
+		ensureWrite(t, cw, "ne 2\n\nline 4")
+		ensureErrorNil(t, cw.Close())
+
+		want := []string{"line 1", "line 2", "", "line 4"}
+		if got, want := len(lines), len(want); got != want {
+			t.Fatalf("GOT: %v; WANT: %v", got, want)
+		}
+		for i := range want {
+			if got, want := lines[i], want[i]; got != want {
+				t.Errorf("GOT: %q; WANT: %q", got, want)
+			}
+		}
+	})
+
+	t.Run("publish error", func(t *testing.T) {
+		var count int
+		cw := &CallbackLineWriter{Publish: func(line []byte) error {
+			if string(line) == "line 2" {
+				return errors.New("publish failed")
+			}
+			count++
+			return nil
+		}}
+
+		n, err := cw.Write([]byte("line 1\nline 2\nline 3\n"))
+		ensureError(t, err, "publish failed")
+		if got, want := n, 7; got != want {
+			t.Errorf("GOT: %v; WANT: %v", got, want)
+		}
+		if got, want := count, 1; got != want {
+			t.Errorf("GOT: %v; WANT: %v", got, want)
+		}
+	})
+}
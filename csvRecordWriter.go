@@ -0,0 +1,90 @@
+package gonl
+
+import (
+	"io"
+)
+
+// CSVRecordWriter is an io.WriteCloser that writes only whole CSV
+// records to the underlying io.WriteCloser. RFC 4180 allows a quoted
+// field to contain newlines, so batching CSV on newline boundaries
+// alone may split a record across two writes. CSVRecordWriter tracks
+// quoting across Write calls, and each Write to the underlying
+// io.WriteCloser ends with the newline terminating a record. Records
+// terminated by either LF or CRLF are supported.
+//
+// To batch records, wrap a BatchLineWriter. Because each Write to the
+// BatchLineWriter ends on a record boundary, so does each of its
+// flushes, provided none of FlushPartialWhenFull, HoldLines,
+// JoinContinuations, and MaxLineLength is set.
+//
+//	lw, err := gonl.NewBatchLineWriter(f, 64*1024)
+//	if err != nil {
+//		return err
+//	}
+//	cw := &gonl.CSVRecordWriter{WC: lw}
+//	w := csv.NewWriter(cw)
+type CSVRecordWriter struct {
+	// WC is io.WriteCloser where data is ultimately written.
+	WC io.WriteCloser
+
+	buf    []byte // partial record
+	quoted bool   // true when the end of buf is within a quoted field
+}
+
+// Close writes any remaining partial record, then closes the
+// underlying io.WriteCloser.
+func (cw *CSVRecordWriter) Close() error {
+	if len(cw.buf) > 0 {
+		_, err := cw.WC.Write(cw.buf)
+		cw.buf = nil
+		if err != nil {
+			_ = cw.WC.Close()
+			return err
+		}
+	}
+	return cw.WC.Close()
+}
+
+// Write writes the records completed by p, along with any partial
+// record previously held, to the underlying io.WriteCloser in a single
+// Write, retaining any trailing partial record. It returns len(p) on
+// success. When the underlying io.WriteCloser returns an error, none
+// of p is consumed.
+func (cw *CSVRecordWriter) Write(p []byte) (int, error) {
+	end := -1 // index of the newline ending the final record
+	quoted := cw.quoted
+	for i, b := range p {
+		switch b {
+		case '"':
+			// An escaped quote toggles twice, so needs no special
+			// treatment.
+			quoted = !quoted
+		case '\n':
+			if !quoted {
+				end = i
+			}
+		}
+	}
+	if end < 0 {
+		cw.buf = append(cw.buf, p...)
+		cw.quoted = quoted
+		return len(p), nil
+	}
+
+	if len(cw.buf) == 0 {
+		if _, err := cw.WC.Write(p[:end+1]); err != nil {
+			return 0, err
+		}
+	} else {
+		held := len(cw.buf)
+		cw.buf = append(cw.buf, p[:end+1]...)
+		if _, err := cw.WC.Write(cw.buf); err != nil {
+			cw.buf = cw.buf[:held]
+			return 0, err
+		}
+		cw.buf = cw.buf[:0]
+	}
+	cw.buf = append(cw.buf, p[end+1:]...)
+	cw.quoted = quoted
+	return len(p), nil
+}
@@ -0,0 +1,53 @@
+package gonl
+
+import (
+	"encoding/csv"
+	"testing"
+)
+
+func TestCSVRecordWriter(t *testing.T) {
+	t.Run("quoted newlines", func(t *testing.T) {
+		output := new(recordingWriter)
+		cw := &CSVRecordWriter{WC: NopCloseWriter(output)}
+
+		ensureWrite(t, cw, "a,\"multi\n")
+		ensureWrite(t, cw, "line\"\r\nb,\"say \"\"hi\"\"\n\"\nc,")
+		ensureWrite(t, cw, "d\n")
+		ensureWrite(t, cw, "\"unterminated\n")
+		ensureErrorNil(t, cw.Close())
+		ensureWrites(t, output.writes, []string{
+			"a,\"multi\nline\"\r\nb,\"say \"\"hi\"\"\n\"\n",
+			"c,d\n",
+			"\"unterminated\n",
+		})
+	})
+
+	t.Run("batched by BatchLineWriter", func(t *testing.T) {
+		output := new(recordingWriter)
+		lw, err := NewBatchLineWriter(NopCloseWriter(output), 16)
+		ensureErrorNil(t, err)
+		w := csv.NewWriter(&CSVRecordWriter{WC: lw})
+
+		for _, record := range [][]string{{"1", "one\ntwo"}, {"2", "three\nfour\nfive"}, {"3", "six"}} {
+			ensureErrorNil(t, w.Write(record))
+			w.Flush()
+			ensureErrorNil(t, w.Error())
+		}
+		ensureErrorNil(t, lw.Close())
+		ensureWrites(t, output.writes, []string{
+			"1,\"one\ntwo\"\n2,\"three\nfour\nfive\"\n",
+			"3,six\n",
+		})
+	})
+
+	t.Run("write error", func(t *testing.T) {
+		cw := &CSVRecordWriter{WC: &errOnWrite{}}
+		ensureWrite(t, cw, "a,\"b")
+		n, err := cw.Write([]byte("\"\n"))
+		ensureError(t, err, "test write error")
+		if got, want := n, 0; got != want {
+			t.Errorf("GOT: %v; WANT: %v", got, want)
+		}
+		ensureError(t, cw.Close(), "test write error")
+	})
+}
@@ -0,0 +1,83 @@
+package gonl
+
+import (
+	"bytes"
+	"io"
+)
+
+// DemuxWriter is an io.WriteCloser that routes each line according to
+// the tag at its start, such as "stderr|" or "stdout|", writing the
+// remainder of the line after the tag separator to the io.WriteCloser
+// registered for that tag. It reverses what a MuxWriter produces.
+// Lines split across multiple Write calls are reassembled before being
+// routed. The final line, when not terminated by a newline, is routed
+// when the DemuxWriter is closed.
+//
+//	dw := &gonl.DemuxWriter{
+//		Routes:  map[string]io.WriteCloser{"stdout": stdout, "stderr": stderr},
+//		Default: stderr,
+//	}
+type DemuxWriter struct {
+	// Routes maps each tag to the io.WriteCloser where the lines with
+	// that tag are written, without their tag and separator.
+	Routes map[string]io.WriteCloser
+
+	// Default, when not nil, is where lines are written that either
+	// have no separator or have a tag not found in Routes. Such lines
+	// are written in their entirety, so no information is lost. When
+	// nil, such lines are dropped.
+	Default io.WriteCloser
+
+	// Separator, when not empty, terminates the tag at the start of
+	// each line. When empty, the tag is terminated by a '|'.
+	Separator []byte
+
+	la lineAssembler
+}
+
+// Close routes any remaining partial line, then closes Default and
+// every io.WriteCloser in Routes, returning the first error
+// encountered. A destination registered more than once is closed more
+// than once.
+func (dw *DemuxWriter) Close() error {
+	err := dw.la.close(dw.emit)
+	for _, wc := range dw.Routes {
+		if cerr := wc.Close(); err == nil {
+			err = cerr
+		}
+	}
+	if dw.Default != nil {
+		if cerr := dw.Default.Close(); err == nil {
+			err = cerr
+		}
+	}
+	return err
+}
+
+// Write routes each line completed by p. When a destination returns an
+// error, Write returns the number of bytes of p preceding the line that
+// could not be written along with that error. Dropped lines are
+// nevertheless consumed.
+func (dw *DemuxWriter) Write(p []byte) (int, error) {
+	return dw.la.write(p, dw.emit)
+}
+
+func (dw *DemuxWriter) emit(line []byte) error {
+	sep := dw.Separator
+	if len(sep) == 0 {
+		sep = []byte{'|'}
+	}
+	wc := dw.Default
+	rest := line
+	if i := bytes.Index(trimNewline(line), sep); i >= 0 {
+		if route, ok := dw.Routes[string(line[:i])]; ok {
+			wc = route
+			rest = line[i+len(sep):]
+		}
+	}
+	if wc == nil {
+		return nil
+	}
+	_, err := wc.Write(rest)
+	return err
+}
@@ -0,0 +1,49 @@
+package gonl
+
+import (
+	"io"
+	"testing"
+)
+
+func TestDemuxWriter(t *testing.T) {
+	t.Run("routes by tag", func(t *testing.T) {
+		stdout := new(testBuffer)
+		stderr := new(testBuffer)
+		other := new(testBuffer)
+		dw := &DemuxWriter{
+			Routes:  map[string]io.WriteCloser{"stdout": stdout, "stderr": stderr},
+			Default: other,
+		}
+
+		ensureWrite(t, dw, "stdout|line 1\nstderr|li")
+		ensureWrite(t, dw, "ne 2\nbogus|line 3\nno tag\nstdout|line 4")
+		ensureErrorNil(t, dw.Close())
+
+		ensureStringer(t, stdout, "line 1\nline 4")
+		ensureStringer(t, stderr, "line 2\n")
+		ensureStringer(t, other, "bogus|line 3\nno tag\n")
+	})
+
+	t.Run("custom separator without default", func(t *testing.T) {
+		a := new(testBuffer)
+		dw := &DemuxWriter{
+			Routes:    map[string]io.WriteCloser{"a": a},
+			Separator: []byte(": "),
+		}
+
+		ensureWrite(t, dw, "a: line 1\nb: line 2\na:line 3\na: line 4\n")
+		ensureErrorNil(t, dw.Close())
+		ensureStringer(t, a, "line 1\nline 4\n")
+	})
+
+	t.Run("write error", func(t *testing.T) {
+		dw := &DemuxWriter{Routes: map[string]io.WriteCloser{"a": &errOnWrite{}}}
+
+		n, err := dw.Write([]byte("b|line 1\na|line 2\n"))
+		ensureError(t, err, "test write error")
+		if got, want := n, 9; got != want {
+			t.Errorf("GOT: %v; WANT: %v", got, want)
+		}
+		ensureError(t, dw.Close(), "test close error")
+	})
+}
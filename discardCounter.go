@@ -0,0 +1,42 @@
+package gonl
+
+import (
+	"sync/atomic"
+)
+
+// DiscardCounter is an io.WriteCloser that discards all data written
+// to it, while counting the number of bytes written. It is useful as
+// a destination when testing and benchmarking the writers in this
+// package. It is not safe for concurrent use; see SyncDiscardCounter.
+type DiscardCounter struct {
+	// Count is the number of bytes written.
+	Count int
+}
+
+// Close does nothing and always returns nil.
+func (dc *DiscardCounter) Close() error { return nil }
+
+// Write adds len(p) to Count, then discards p.
+func (dc *DiscardCounter) Write(p []byte) (int, error) {
+	dc.Count += len(p)
+	return len(p), nil
+}
+
+// SyncDiscardCounter is an io.WriteCloser that discards all data
+// written to it, while atomically counting the number of bytes
+// written. Unlike DiscardCounter, it is safe for concurrent use.
+type SyncDiscardCounter struct {
+	count int64
+}
+
+// Close does nothing and always returns nil.
+func (dc *SyncDiscardCounter) Close() error { return nil }
+
+// Count returns the number of bytes written.
+func (dc *SyncDiscardCounter) Count() int64 { return atomic.LoadInt64(&dc.count) }
+
+// Write atomically adds len(p) to the count, then discards p.
+func (dc *SyncDiscardCounter) Write(p []byte) (int, error) {
+	atomic.AddInt64(&dc.count, int64(len(p)))
+	return len(p), nil
+}
@@ -0,0 +1,40 @@
+package gonl
+
+import (
+	"sync"
+	"testing"
+)
+
+func TestDiscardCounter(t *testing.T) {
+	dc := new(DiscardCounter)
+	ensureWrite(t, dc, "line 1\n")
+	ensureWrite(t, dc, "line 2")
+	ensureErrorNil(t, dc.Close())
+	if got, want := dc.Count, 13; got != want {
+		t.Errorf("GOT: %v; WANT: %v", got, want)
+	}
+}
+
+func TestSyncDiscardCounter(t *testing.T) {
+	const goroutines = 8
+	const writes = 100
+
+	dc := new(SyncDiscardCounter)
+
+	var wg sync.WaitGroup
+	wg.Add(goroutines)
+	for i := 0; i < goroutines; i++ {
+		go func() {
+			defer wg.Done()
+			for j := 0; j < writes; j++ {
+				_, _ = dc.Write([]byte("line\n"))
+			}
+		}()
+	}
+	wg.Wait()
+
+	ensureErrorNil(t, dc.Close())
+	if got, want := dc.Count(), int64(goroutines*writes*5); got != want {
+		t.Errorf("GOT: %v; WANT: %v", got, want)
+	}
+}
@@ -0,0 +1,77 @@
+package gonl
+
+import (
+	"io"
+
+	"golang.org/x/text/encoding"
+)
+
+// EncodingWriter is an io.WriteCloser that converts each complete line
+// written to it from UTF-8 to the encoding of Encoder before writing
+// it to the underlying io.WriteCloser, so text produced by a UTF-8
+// pipeline can be emitted in an encoding such as UTF-16LE. Lines split
+// across multiple Write calls, including lines with a multibyte rune
+// split between calls, are reassembled before being converted. The
+// final line, when not terminated by a newline, is converted and
+// written when the EncodingWriter is closed.
+//
+//	ew := &gonl.EncodingWriter{
+//		WC:       f,
+//		Encoder:  unicode.UTF16(unicode.LittleEndian, unicode.IgnoreBOM).NewEncoder(),
+//		WriteBOM: true,
+//	}
+type EncodingWriter struct {
+	// WC is io.WriteCloser where data is ultimately written.
+	WC io.WriteCloser
+
+	// Encoder converts UTF-8 text to the target encoding.
+	Encoder *encoding.Encoder
+
+	// WriteBOM, when set, causes a byte order mark, which is U+FEFF
+	// converted by Encoder, to be written before the first line. When
+	// set, Encoder should not be one that emits its own byte order
+	// mark, or the mark will be written before every line.
+	WriteBOM bool
+
+	la lineAssembler
+
+	// started is set once the byte order mark has been written.
+	started bool
+}
+
+// Close converts and writes any remaining partial line, then closes
+// the underlying io.WriteCloser.
+func (ew *EncodingWriter) Close() error {
+	if err := ew.la.close(ew.emit); err != nil {
+		_ = ew.WC.Close()
+		return err
+	}
+	return ew.WC.Close()
+}
+
+// Write converts and writes each line completed by p to the underlying
+// io.WriteCloser, returning the number of bytes of p consumed. When a
+// line cannot be converted, Write returns the error from Encoder.
+func (ew *EncodingWriter) Write(p []byte) (int, error) {
+	return ew.la.write(p, ew.emit)
+}
+
+func (ew *EncodingWriter) emit(line []byte) error {
+	if ew.WriteBOM && !ew.started {
+		bom, err := ew.Encoder.String("\uFEFF")
+		if err != nil {
+			return err
+		}
+		if _, err = io.WriteString(ew.WC, bom); err != nil {
+			return err
+		}
+	}
+	ew.started = true
+
+	encoded, err := ew.Encoder.Bytes(line)
+	if err != nil {
+		return err
+	}
+	_, err = ew.WC.Write(encoded)
+	return err
+}
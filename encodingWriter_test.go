@@ -0,0 +1,48 @@
+package gonl
+
+import (
+	"testing"
+
+	"golang.org/x/text/encoding/charmap"
+	"golang.org/x/text/encoding/unicode"
+)
+
+func TestEncodingWriter(t *testing.T) {
+	t.Run("UTF-16LE with BOM", func(t *testing.T) {
+		output := new(testBuffer)
+		ew := &EncodingWriter{
+			WC:       output,
+			Encoder:  unicode.UTF16(unicode.LittleEndian, unicode.IgnoreBOM).NewEncoder(),
+			WriteBOM: true,
+		}
+
+		ensureWrite(t, ew, "h\xc3")   // split é
+		ensureWrite(t, ew, "\xa9\nz") // remainder of é
+		ensureStringer(t, output, "\xff\xfeh\x00\xe9\x00\n\x00")
+		ensureErrorNil(t, ew.Close())
+		ensureStringer(t, output, "\xff\xfeh\x00\xe9\x00\n\x00z\x00")
+	})
+
+	t.Run("without BOM", func(t *testing.T) {
+		output := new(testBuffer)
+		ew := &EncodingWriter{WC: output, Encoder: charmap.ISO8859_1.NewEncoder()}
+
+		ensureWrite(t, ew, "caf\xc3\xa9\n")
+		ensureErrorNil(t, ew.Close())
+		ensureStringer(t, output, "caf\xe9\n")
+	})
+
+	t.Run("unrepresentable rune", func(t *testing.T) {
+		output := new(testBuffer)
+		ew := &EncodingWriter{WC: output, Encoder: charmap.ISO8859_1.NewEncoder()}
+
+		n, err := ew.Write([]byte("ok\n\xe2\x82\xac\n"))
+		if err == nil {
+			t.Fatal("GOT: nil; WANT: error")
+		}
+		if got, want := n, 3; got != want {
+			t.Errorf("GOT: %v; WANT: %v", got, want)
+		}
+		ensureStringer(t, output, "ok\n")
+	})
+}
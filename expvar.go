@@ -0,0 +1,28 @@
+package gonl
+
+import (
+	"expvar"
+	"sync/atomic"
+)
+
+// PublishExpvar publishes counters describing the BatchLineWriter
+// through the expvar package under the specified name, so they appear
+// on the /debug/vars page. The published value is a map with the
+// number of bytes and lines written to the underlying io.WriteCloser,
+// the number of flushes, which are Write calls to the underlying
+// io.WriteCloser, and the number of errors it returned. The counters
+// are updated atomically, and may be read concurrently with writes.
+//
+// As with expvar.Publish, this panics when the name is already
+// registered.
+func (lw *BatchLineWriter) PublishExpvar(name string) {
+	c := &lw.counters
+	expvar.Publish(name, expvar.Func(func() interface{} {
+		return map[string]int64{
+			"bytes":   atomic.LoadInt64(&c.bytes),
+			"lines":   atomic.LoadInt64(&c.lines),
+			"flushes": atomic.LoadInt64(&c.flushes),
+			"errors":  atomic.LoadInt64(&c.errors),
+		}
+	}))
+}
@@ -0,0 +1,57 @@
+package gonl
+
+import (
+	"encoding/json"
+	"expvar"
+	"fmt"
+	"sync/atomic"
+	"testing"
+)
+
+// expvarNames numbers the names returned by expvarName.
+var expvarNames int64
+
+// expvarName returns a name for PublishExpvar that is unique within the
+// test binary, because expvar variables cannot be unpublished, so that
+// tests may run more than once, as with -count.
+func expvarName(t *testing.T) string {
+	return fmt.Sprintf("%s#%d", t.Name(), atomic.AddInt64(&expvarNames, 1))
+}
+
+func TestPublishExpvar(t *testing.T) {
+	output := new(testBuffer)
+	lw, err := NewBatchLineWriter(output, 8)
+	ensureErrorNil(t, err)
+	name := expvarName(t)
+	lw.PublishExpvar(name)
+
+	ensureWrite(t, lw, "line 1\nline 2\nline 3")
+	ensureErrorNil(t, lw.Close())
+
+	var got map[string]int64
+	if err := json.Unmarshal([]byte(expvar.Get(name).String()), &got); err != nil {
+		t.Fatal(err)
+	}
+	want := map[string]int64{"bytes": 20, "lines": 2, "flushes": 2, "errors": 0}
+	for k, v := range want {
+		if got[k] != v {
+			t.Errorf("%s: GOT: %v; WANT: %v", k, got[k], v)
+		}
+	}
+}
+
+func TestPublishExpvarErrors(t *testing.T) {
+	lw, err := NewBatchLineWriter(&errOnWrite{}, 4)
+	ensureErrorNil(t, err)
+	lw.PublishExpvar(expvarName(t))
+
+	_, err = lw.Write([]byte("line 1\n"))
+	ensureError(t, err, "test write error")
+
+	if got, want := lw.counters.errors, int64(1); got != want {
+		t.Errorf("GOT: %v; WANT: %v", got, want)
+	}
+	if got, want := lw.counters.lines, int64(0); got != want {
+		t.Errorf("GOT: %v; WANT: %v", got, want)
+	}
+}
@@ -0,0 +1,47 @@
+package gonl
+
+// Flush is equivalent to FlushCompletedLines. It is provided because
+// it is the method callers of bufio.Writer and http.Flusher expect.
+func (lw *BatchLineWriter) Flush() error { return lw.FlushCompletedLines() }
+
+// FlushCompletedLines writes all buffered completed lines to the
+// underlying io.WriteCloser without closing it, regardless of the flush
+// threshold, FlushOnlyOnClose, and FlushInterval. Lines held because of
+// HoldLines remain buffered, as do the bytes following the final
+// newline, which are written once their line is completed, so every
+// Write to the underlying io.WriteCloser still ends with a newline.
+// On error, the bytes not written remain buffered for the next flush.
+// After Close has been called, it returns ErrClosed.
+func (lw *BatchLineWriter) FlushCompletedLines() error {
+	lw.mu.Lock()
+	defer lw.mu.Unlock()
+
+	if lw.closed {
+		return ErrClosed
+	}
+	if index := lw.releaseIndex(); index >= lw.off {
+		_, err := lw.flush(lw.bufferLength(), 0, index+1)
+		return err
+	}
+	return nil
+}
+
+// FlushAll writes all buffered data to the underlying io.WriteCloser
+// without closing it, as Close does, including the lines held because
+// of HoldLines and a trailing partial line, so it should only be used
+// when the underlying io.WriteCloser does not require each Write to end
+// with a newline. On error, the bytes not written remain buffered for
+// the next flush. After Close has been called, it returns ErrClosed.
+func (lw *BatchLineWriter) FlushAll() error {
+	lw.mu.Lock()
+	defer lw.mu.Unlock()
+
+	if lw.closed {
+		return ErrClosed
+	}
+	if l := lw.bufferLength(); l > 0 {
+		_, err := lw.flush(l, 0, len(lw.buf))
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,32 @@
+package gonl
+
+// NewBatchLineWriterFunc returns a new BatchLineWriter that passes
+// each batch of complete lines to flush rather than writing them to
+// an io.WriteCloser, for destinations such as custom batch APIs that
+// do not implement io.Writer. The batching behavior is identical to
+// that of a BatchLineWriter created by NewBatchLineWriter with size
+// as its flush threshold, and Close passes any remaining bytes to
+// flush.
+//
+// The slice given to flush refers to the internal buffer of the
+// BatchLineWriter, and is only valid for the duration of the call. It
+// must be copied when it needs to be retained. When flush returns an
+// error, none of the bytes given to it are considered to have been
+// written.
+func NewBatchLineWriterFunc(flush func(data []byte) error, size int) (*BatchLineWriter, error) {
+	return NewBatchLineWriter(flushFunc(flush), size)
+}
+
+// flushFunc adapts a function to the io.WriteCloser interface.
+type flushFunc func(data []byte) error
+
+// Close does nothing.
+func (f flushFunc) Close() error { return nil }
+
+// Write passes p to the function, returning len(p) when it succeeds.
+func (f flushFunc) Write(p []byte) (int, error) {
+	if err := f(p); err != nil {
+		return 0, err
+	}
+	return len(p), nil
+}
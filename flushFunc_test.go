@@ -0,0 +1,50 @@
+package gonl
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestNewBatchLineWriterFunc(t *testing.T) {
+	t.Run("size", func(t *testing.T) {
+		_, err := NewBatchLineWriterFunc(func([]byte) error { return nil }, 0)
+		ensureError(t, err, "less than or equal to 0")
+	})
+
+	t.Run("batches", func(t *testing.T) {
+		var batches []string
+		lw, err := NewBatchLineWriterFunc(func(data []byte) error {
+			batches = append(batches, string(data))
+			return nil
+		}, 10)
+		ensureErrorNil(t, err)
+
+		ensureWrite(t, lw, "line 1\n")
+		ensureWrite(t, lw, "line 2\nline")
+		ensureWrite(t, lw, " 3")
+		ensureErrorNil(t, lw.Close())
+
+		want := []string{"line 1\nline 2\n", "line 3"}
+		if got, want := len(batches), len(want); got != want {
+			t.Fatalf("GOT: %v; WANT: %v", got, want)
+		}
+		for i := range want {
+			if got, want := batches[i], want[i]; got != want {
+				t.Errorf("GOT: %q; WANT: %q", got, want)
+			}
+		}
+	})
+
+	t.Run("flush error", func(t *testing.T) {
+		lw, err := NewBatchLineWriterFunc(func([]byte) error {
+			return errors.New("flush failed")
+		}, 1)
+		ensureErrorNil(t, err)
+
+		n, err := lw.Write([]byte("line 1\n"))
+		ensureError(t, err, "flush failed")
+		if got, want := n, 0; got != want {
+			t.Errorf("GOT: %v; WANT: %v", got, want)
+		}
+	})
+}
@@ -0,0 +1,93 @@
+package gonl
+
+import (
+	"errors"
+	"io"
+	"time"
+)
+
+// armFlushTimer starts the FlushInterval timer when it is enabled, not
+// already pending, and the buffer holds a completed line. It is called
+// with mu held.
+func (lw *BatchLineWriter) armFlushTimer() {
+	if lw.FlushInterval <= 0 || lw.FlushOnlyOnClose || lw.timerArmed || lw.closed {
+		return
+	}
+	if lw.indexOfFinalNewline < lw.off {
+		return // no completed line
+	}
+	lw.timerArmed = true
+	if lw.timer == nil {
+		lw.timer = time.AfterFunc(lw.FlushInterval, lw.flushOnInterval)
+		return
+	}
+	lw.timer.Reset(lw.FlushInterval)
+}
+
+// stopFlushTimer stops any pending FlushInterval timer. It is called
+// with mu held.
+func (lw *BatchLineWriter) stopFlushTimer() {
+	if lw.timer != nil {
+		lw.timer.Stop()
+	}
+	lw.timerArmed = false
+}
+
+// flushOnInterval is invoked by the FlushInterval timer to flush all
+// completed lines not held because of HoldLines.
+func (lw *BatchLineWriter) flushOnInterval() {
+	lw.mu.Lock()
+	defer lw.mu.Unlock()
+
+	if !lw.timerArmed || lw.closed {
+		return // stopped after this callback began
+	}
+	lw.timerArmed = false
+	if index := lw.releaseIndex(); index >= lw.off {
+		// Error already reported by flush, and unwritten bytes remain
+		// in the buffer.
+		_, _ = lw.flush(lw.bufferLength(), 0, index+1)
+	}
+}
+
+// readFromInterval is ReadFrom when any of FlushInterval, MaxBuffer, or
+// MaxLineLength is set. Rather than reading directly into the buffer,
+// it reads into a separate slice without holding mu, so the
+// FlushInterval timer can flush while waiting for a slow io.Reader, and
+// passes what it reads to write, which enforces MaxBuffer and
+// MaxLineLength.
+func (lw *BatchLineWriter) readFromInterval(r io.Reader) (int64, error) {
+	var totalRead int64
+
+	size := lw.flushThreshold
+	if size < minRead {
+		size = minRead
+	}
+	buf := make([]byte, size)
+
+	for {
+		nr, rerr := r.Read(buf)
+		if nr < 0 || nr > len(buf) {
+			return totalRead, errors.New("invalid read result")
+		}
+
+		if nr > 0 {
+			lw.mu.Lock()
+			nw, werr := lw.write(buf[:nr])
+			lw.armFlushTimer()
+			lw.mu.Unlock()
+			if werr != nil {
+				return totalRead + int64(nw), werr
+			}
+		}
+
+		totalRead += int64(nr)
+
+		if rerr == io.EOF {
+			return totalRead, nil
+		}
+		if rerr != nil {
+			return totalRead, rerr
+		}
+	}
+}
@@ -0,0 +1,134 @@
+package gonl
+
+import (
+	"errors"
+	"io"
+	"testing"
+	"time"
+)
+
+// chanWriteCloser is an io.WriteCloser that sends a copy of each
+// slice written to it on a channel, so tests may wait for writes made
+// by other goroutines.
+type chanWriteCloser struct {
+	writes chan string
+	err    error
+}
+
+func newChanWriteCloser() *chanWriteCloser {
+	return &chanWriteCloser{writes: make(chan string, 16)}
+}
+
+func (cw *chanWriteCloser) Close() error { return nil }
+
+func (cw *chanWriteCloser) Write(p []byte) (int, error) {
+	cw.writes <- string(p)
+	if cw.err != nil {
+		return 0, cw.err
+	}
+	return len(p), nil
+}
+
+// ensureReceive waits for the next write made to cw.
+func ensureReceive(tb testing.TB, cw *chanWriteCloser, want string) {
+	tb.Helper()
+	select {
+	case got := <-cw.writes:
+		if got != want {
+			tb.Errorf("GOT: %q; WANT: %q", got, want)
+		}
+	case <-time.After(5 * time.Second):
+		tb.Fatalf("GOT: no write; WANT: %q", want)
+	}
+}
+
+// ensureNoReceive ensures no write is made to cw for a while.
+func ensureNoReceive(tb testing.TB, cw *chanWriteCloser) {
+	tb.Helper()
+	select {
+	case got := <-cw.writes:
+		tb.Errorf("GOT: %q; WANT: no write", got)
+	case <-time.After(50 * time.Millisecond):
+	}
+}
+
+func TestFlushInterval(t *testing.T) {
+	t.Run("flushes completed lines", func(t *testing.T) {
+		output := newChanWriteCloser()
+		lw, err := NewBatchLineWriter(output, 1024)
+		ensureErrorNil(t, err)
+		lw.FlushInterval = 10 * time.Millisecond
+
+		ensureWrite(t, lw, "partial")
+		ensureNoReceive(t, output)
+
+		ensureWrite(t, lw, " line 1\nline 2\npartial")
+		ensureReceive(t, output, "partial line 1\nline 2\n")
+
+		ensureWrite(t, lw, " line 3\n")
+		ensureReceive(t, output, "partial line 3\n")
+
+		ensureErrorNil(t, lw.Close())
+		ensureNoReceive(t, output)
+	})
+
+	t.Run("close stops timer", func(t *testing.T) {
+		output := newChanWriteCloser()
+		lw, err := NewBatchLineWriter(output, 1024)
+		ensureErrorNil(t, err)
+		lw.FlushInterval = 10 * time.Millisecond
+
+		ensureWrite(t, lw, "line 1\n")
+		ensureErrorNil(t, lw.Close())
+		ensureReceive(t, output, "line 1\n")
+		ensureNoReceive(t, output)
+	})
+
+	t.Run("ReadFrom slow reader", func(t *testing.T) {
+		output := newChanWriteCloser()
+		lw, err := NewBatchLineWriter(output, 1024)
+		ensureErrorNil(t, err)
+		lw.FlushInterval = 10 * time.Millisecond
+
+		pr, pw := io.Pipe()
+		done := make(chan error, 1)
+		go func() {
+			_, err := lw.ReadFrom(pr)
+			done <- err
+		}()
+
+		_, err = pw.Write([]byte("line 1\nli"))
+		ensureErrorNil(t, err)
+		ensureReceive(t, output, "line 1\n")
+
+		_, err = pw.Write([]byte("ne 2\n"))
+		ensureErrorNil(t, err)
+		ensureReceive(t, output, "line 2\n")
+
+		ensureErrorNil(t, pw.Close())
+		ensureErrorNil(t, <-done)
+		ensureErrorNil(t, lw.Close())
+	})
+
+	t.Run("error reported", func(t *testing.T) {
+		output := newChanWriteCloser()
+		output.err = errors.New("flush failed")
+		lw, err := NewBatchLineWriter(output, 1024)
+		ensureErrorNil(t, err)
+		lw.FlushInterval = 10 * time.Millisecond
+		reported := make(chan error, 1)
+		lw.OnError = func(err error) { reported <- err }
+
+		ensureWrite(t, lw, "line 1\n")
+		ensureReceive(t, output, "line 1\n")
+		ensureError(t, <-reported, "flush failed")
+
+		// Data not written remains buffered.
+		lw.mu.Lock()
+		got := lw.bufferString()
+		lw.mu.Unlock()
+		if want := "line 1\n"; got != want {
+			t.Errorf("GOT: %q; WANT: %q", got, want)
+		}
+	})
+}
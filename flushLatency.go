@@ -0,0 +1,56 @@
+package gonl
+
+import (
+	"sort"
+	"time"
+)
+
+// flushLatencySamples is the number of most recent flush durations
+// retained when collecting flush latency.
+const flushLatencySamples = 1024
+
+// latencyReservoir retains the most recent flush durations in a ring
+// buffer, bounding memory use regardless of how many flushes occur.
+type latencyReservoir struct {
+	samples [flushLatencySamples]time.Duration
+	count   int // total number of samples recorded
+}
+
+func (lr *latencyReservoir) record(d time.Duration) {
+	lr.samples[lr.count%flushLatencySamples] = d
+	lr.count++
+}
+
+// FlushLatency returns the 50th, 90th, and 99th percentiles of the
+// time taken by each Write to the underlying io.WriteCloser, measured
+// over the most recent 1024 flushes made while the
+// CollectFlushLatency field was set. This helps determine whether
+// latency is caused by the buffering policy or by a slow underlying
+// io.WriteCloser. All three values are zero when no flushes have been
+// measured.
+func (lw *BatchLineWriter) FlushLatency() (p50, p90, p99 time.Duration) {
+	lw.mu.Lock()
+	defer lw.mu.Unlock()
+	lr := lw.latencies
+	if lr == nil || lr.count == 0 {
+		return 0, 0, 0
+	}
+	n := lr.count
+	if n > flushLatencySamples {
+		n = flushLatencySamples
+	}
+	sorted := make([]time.Duration, n)
+	copy(sorted, lr.samples[:n])
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i] < sorted[j] })
+	return percentile(sorted, 50), percentile(sorted, 90), percentile(sorted, 99)
+}
+
+// percentile returns the p-th percentile of the sorted durations using
+// the nearest rank method.
+func percentile(sorted []time.Duration, p int) time.Duration {
+	rank := (p*len(sorted) + 99) / 100 // ceiling
+	if rank < 1 {
+		rank = 1
+	}
+	return sorted[rank-1]
+}
@@ -0,0 +1,87 @@
+package gonl
+
+import (
+	"testing"
+	"time"
+)
+
+// sleepWriteCloser is an io.WriteCloser that sleeps for a duration
+// taken from its delays before each Write.
+type sleepWriteCloser struct {
+	delays []time.Duration
+}
+
+func (sw *sleepWriteCloser) Close() error { return nil }
+
+func (sw *sleepWriteCloser) Write(p []byte) (int, error) {
+	if len(sw.delays) > 0 {
+		time.Sleep(sw.delays[0])
+		sw.delays = sw.delays[1:]
+	}
+	return len(p), nil
+}
+
+func TestPercentile(t *testing.T) {
+	sorted := make([]time.Duration, 100)
+	for i := range sorted {
+		sorted[i] = time.Duration(i + 1)
+	}
+	for _, p := range []int{1, 50, 90, 99, 100} {
+		if got, want := percentile(sorted, p), time.Duration(p); got != want {
+			t.Errorf("%d: GOT: %v; WANT: %v", p, got, want)
+		}
+	}
+	if got, want := percentile(sorted[:1], 50), time.Duration(1); got != want {
+		t.Errorf("GOT: %v; WANT: %v", got, want)
+	}
+}
+
+func TestFlushLatency(t *testing.T) {
+	t.Run("disabled", func(t *testing.T) {
+		lw, err := NewBatchLineWriter(new(DiscardCounter), 1)
+		ensureErrorNil(t, err)
+		ensureWrite(t, lw, "line 1\n")
+
+		p50, p90, p99 := lw.FlushLatency()
+		if p50 != 0 || p90 != 0 || p99 != 0 {
+			t.Errorf("GOT: %v, %v, %v; WANT: 0, 0, 0", p50, p90, p99)
+		}
+	})
+
+	t.Run("enabled", func(t *testing.T) {
+		const slow = 20 * time.Millisecond
+		output := &sleepWriteCloser{delays: []time.Duration{0, 0, 0, 0, slow}}
+		lw, err := NewBatchLineWriter(output, 1)
+		ensureErrorNil(t, err)
+		lw.CollectFlushLatency = true
+
+		for i := 0; i < 5; i++ {
+			ensureWrite(t, lw, "line\n")
+		}
+
+		p50, p90, p99 := lw.FlushLatency()
+		if p50 >= slow {
+			t.Errorf("GOT: %v; WANT: < %v", p50, slow)
+		}
+		if p90 < slow || p99 < slow {
+			t.Errorf("GOT: %v, %v; WANT: >= %v", p90, p99, slow)
+		}
+		if got, want := lw.latencies.count, 5; got != want {
+			t.Errorf("GOT: %v; WANT: %v", got, want)
+		}
+	})
+
+	t.Run("bounded", func(t *testing.T) {
+		lw, err := NewBatchLineWriter(new(DiscardCounter), 1)
+		ensureErrorNil(t, err)
+		lw.CollectFlushLatency = true
+
+		for i := 0; i < flushLatencySamples+10; i++ {
+			ensureWrite(t, lw, "line\n")
+		}
+		_, _, _ = lw.FlushLatency()
+		if got, want := lw.latencies.count, flushLatencySamples+10; got != want {
+			t.Errorf("GOT: %v; WANT: %v", got, want)
+		}
+	})
+}
@@ -0,0 +1,75 @@
+package gonl
+
+import (
+	"time"
+)
+
+// FlushTracer is implemented by tracing integrations invoked around
+// each Write a BatchLineWriter makes to its underlying io.WriteCloser,
+// such as one starting an OpenTelemetry span for each flush, which
+// shows whether the downstream write latency or the upstream read
+// latency dominates a pipeline.
+//
+//	type spanTracer struct {
+//		ctx    context.Context
+//		tracer trace.Tracer
+//	}
+//
+//	func (st spanTracer) StartFlush(size int) func(bytes, lines int, err error) {
+//		_, span := st.tracer.Start(st.ctx, "gonl.flush")
+//		return func(bytes, lines int, err error) {
+//			span.SetAttributes(attribute.Int("bytes", bytes), attribute.Int("lines", lines))
+//			if err != nil {
+//				span.RecordError(err)
+//			}
+//			span.End()
+//		}
+//	}
+type FlushTracer interface {
+	// StartFlush is invoked immediately before a Write of size bytes
+	// to the underlying io.WriteCloser. It returns a function, which
+	// may be nil, invoked immediately after that Write returns, with
+	// the number of bytes written, the number of delimiter
+	// terminated lines among them, and the error the Write returned.
+	StartFlush(size int) func(bytes, lines int, err error)
+}
+
+// flushProbe holds what flushDone needs to know about a flush started
+// by flushStart.
+type flushProbe struct {
+	start time.Time                         // zero unless collecting flush latency
+	end   func(bytes, lines int, err error) // nil unless tracing
+}
+
+// flushStart is invoked immediately before a Write of size bytes to the
+// underlying io.WriteCloser.
+func (lw *BatchLineWriter) flushStart(size int) flushProbe {
+	var fp flushProbe
+	if lw.FlushTracer != nil {
+		fp.end = lw.FlushTracer.StartFlush(size)
+	}
+	if lw.CollectFlushLatency {
+		fp.start = time.Now()
+	}
+	return fp
+}
+
+// flushDone is invoked immediately after a Write to the underlying
+// io.WriteCloser of the bytes in bufs returns nw and err. It records
+// the duration of the flush when collecting flush latency, updates the
+// counters, and ends the trace of the flush.
+func (lw *BatchLineWriter) flushDone(fp flushProbe, nw int, err error, bufs ...[]byte) {
+	if !fp.start.IsZero() {
+		if lw.latencies == nil {
+			lw.latencies = new(latencyReservoir)
+		}
+		lw.latencies.record(time.Since(fp.start))
+	}
+	lines := lw.countWrite(nw, bufs...)
+	if fp.end != nil {
+		if nw < 0 {
+			nw = 0
+		}
+		fp.end(nw, lines, err)
+	}
+}
@@ -0,0 +1,75 @@
+package gonl
+
+import (
+	"testing"
+)
+
+type flushTrace struct {
+	size, bytes, lines int
+	err                error
+}
+
+// testTracer records each flush it is invoked for.
+type testTracer struct {
+	traces []flushTrace
+}
+
+func (tt *testTracer) StartFlush(size int) func(bytes, lines int, err error) {
+	return func(bytes, lines int, err error) {
+		tt.traces = append(tt.traces, flushTrace{size, bytes, lines, err})
+	}
+}
+
+func TestFlushTracer(t *testing.T) {
+	t.Run("traces each flush", func(t *testing.T) {
+		tracer := new(testTracer)
+		lw, err := NewBatchLineWriterOptions(new(testBuffer), WithFlushThreshold(8), WithFlushTracer(tracer))
+		ensureErrorNil(t, err)
+
+		ensureWrite(t, lw, "line 1\nline 2\nline 3")
+		ensureErrorNil(t, lw.Close())
+
+		want := []flushTrace{{14, 14, 2, nil}, {6, 6, 0, nil}}
+		if len(tracer.traces) != len(want) {
+			t.Fatalf("GOT: %v; WANT: %v", tracer.traces, want)
+		}
+		for i := range want {
+			if tracer.traces[i] != want[i] {
+				t.Errorf("GOT: %v; WANT: %v", tracer.traces[i], want[i])
+			}
+		}
+	})
+
+	t.Run("buffers writer", func(t *testing.T) {
+		tracer := new(testTracer)
+		lw, err := NewBatchLineWriterOptions(&testBuffersWriter{}, WithFlushThreshold(8), WithFlushTracer(tracer))
+		ensureErrorNil(t, err)
+
+		ensureWrite(t, lw, "line 1")
+		ensureWrite(t, lw, "\nline 2\n")
+		ensureErrorNil(t, lw.Close())
+
+		if got, want := len(tracer.traces), 1; got != want {
+			t.Fatalf("GOT: %v; WANT: %v", got, want)
+		}
+		if got, want := tracer.traces[0], (flushTrace{14, 14, 2, nil}); got != want {
+			t.Errorf("GOT: %v; WANT: %v", got, want)
+		}
+	})
+
+	t.Run("error", func(t *testing.T) {
+		tracer := new(testTracer)
+		lw, err := NewBatchLineWriterOptions(&errOnWrite{}, WithFlushThreshold(1), WithFlushTracer(tracer))
+		ensureErrorNil(t, err)
+
+		_, err = lw.Write([]byte("line 1\n"))
+		ensureError(t, err, "test write error")
+
+		if got, want := len(tracer.traces), 1; got != want {
+			t.Fatalf("GOT: %v; WANT: %v", got, want)
+		}
+		if got, want := tracer.traces[0], (flushTrace{7, 0, 0, errWrite{}}); got != want {
+			t.Errorf("GOT: %v; WANT: %v", got, want)
+		}
+	})
+}
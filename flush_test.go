@@ -0,0 +1,130 @@
+package gonl
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestFlush(t *testing.T) {
+	t.Run("completed lines", func(t *testing.T) {
+		output := new(testBuffer)
+		lw, err := NewBatchLineWriter(output, 1024)
+		ensureErrorNil(t, err)
+
+		ensureWrite(t, lw, "line 1\nline 2\npartial")
+		ensureStringer(t, output, "")
+		ensureErrorNil(t, lw.Flush())
+		ensureStringer(t, output, "line 1\nline 2\n")
+
+		// nothing to flush
+		ensureErrorNil(t, lw.Flush())
+		ensureStringer(t, output, "line 1\nline 2\n")
+
+		ensureWrite(t, lw, " line 3\n")
+		ensureErrorNil(t, lw.Flush())
+		ensureStringer(t, output, "line 1\nline 2\npartial line 3\n")
+		ensureErrorNil(t, lw.Close())
+	})
+
+	t.Run("flush only on close", func(t *testing.T) {
+		output := new(testBuffer)
+		lw, err := NewBatchLineWriterOptions(output, WithFlushOnlyOnClose())
+		ensureErrorNil(t, err)
+
+		ensureWrite(t, lw, "line 1\n")
+		ensureErrorNil(t, lw.Flush())
+		ensureStringer(t, output, "line 1\n")
+		ensureErrorNil(t, lw.Close())
+	})
+
+	t.Run("hold lines", func(t *testing.T) {
+		output := new(testBuffer)
+		lw, err := NewBatchLineWriterOptions(output, WithHoldLines(1))
+		ensureErrorNil(t, err)
+
+		ensureWrite(t, lw, "line 1\nline 2\n")
+		ensureErrorNil(t, lw.Flush())
+		ensureStringer(t, output, "line 1\n")
+		ensureErrorNil(t, lw.Close())
+		ensureStringer(t, output, "line 1\nline 2\n")
+	})
+
+	t.Run("error keeps unwritten bytes", func(t *testing.T) {
+		output := &flakyWriter{failures: 1, err: errors.New("transient")}
+		lw, err := NewBatchLineWriter(flakyPlainWriter{output}, 1024)
+		ensureErrorNil(t, err)
+
+		ensureWrite(t, lw, "line 1\n")
+		ensureError(t, lw.Flush(), "transient")
+		ensureStringer(t, output, "lin")
+		ensureErrorNil(t, lw.Flush())
+		ensureStringer(t, output, "line 1\n")
+		ensureErrorNil(t, lw.Close())
+	})
+
+	t.Run("after close", func(t *testing.T) {
+		lw, err := NewBatchLineWriter(new(testBuffer), 1024)
+		ensureErrorNil(t, err)
+		ensureErrorNil(t, lw.Close())
+		if err := lw.Flush(); !errors.Is(err, ErrClosed) {
+			t.Errorf("GOT: %v; WANT: %v", err, ErrClosed)
+		}
+	})
+}
+
+func TestFlushAll(t *testing.T) {
+	t.Run("partial line", func(t *testing.T) {
+		output := new(testBuffer)
+		lw, err := NewBatchLineWriter(output, 1024)
+		ensureErrorNil(t, err)
+
+		ensureWrite(t, lw, "line 1\npartial")
+		ensureErrorNil(t, lw.FlushCompletedLines())
+		ensureStringer(t, output, "line 1\n")
+		ensureErrorNil(t, lw.FlushAll())
+		ensureStringer(t, output, "line 1\npartial")
+
+		// nothing to flush
+		ensureErrorNil(t, lw.FlushAll())
+
+		ensureWrite(t, lw, " line 2\n")
+		ensureErrorNil(t, lw.Close())
+		ensureStringer(t, output, "line 1\npartial line 2\n")
+	})
+
+	t.Run("hold lines", func(t *testing.T) {
+		output := new(testBuffer)
+		lw, err := NewBatchLineWriterOptions(output, WithHoldLines(1))
+		ensureErrorNil(t, err)
+
+		ensureWrite(t, lw, "line 1\nline 2\n")
+		ensureErrorNil(t, lw.FlushAll())
+		ensureStringer(t, output, "line 1\nline 2\n")
+		ensureErrorNil(t, lw.Close())
+	})
+
+	t.Run("error keeps unwritten bytes", func(t *testing.T) {
+		output := &flakyWriter{failures: 1, err: errors.New("transient")}
+		lw, err := NewBatchLineWriter(flakyPlainWriter{output}, 1024)
+		ensureErrorNil(t, err)
+
+		ensureWrite(t, lw, "partial")
+		ensureError(t, lw.FlushAll(), "transient")
+		ensureStringer(t, output, "par")
+		ensureErrorNil(t, lw.FlushAll())
+		ensureStringer(t, output, "partial")
+		ensureErrorNil(t, lw.Close())
+	})
+
+	t.Run("after close", func(t *testing.T) {
+		lw, err := NewBatchLineWriter(new(testBuffer), 1024)
+		ensureErrorNil(t, err)
+		ensureErrorNil(t, lw.Close())
+		if err := lw.FlushAll(); !errors.Is(err, ErrClosed) {
+			t.Errorf("GOT: %v; WANT: %v", err, ErrClosed)
+		}
+		if err := lw.FlushCompletedLines(); !errors.Is(err, ErrClosed) {
+			t.Errorf("GOT: %v; WANT: %v", err, ErrClosed)
+		}
+	})
+}
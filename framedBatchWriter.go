@@ -0,0 +1,108 @@
+package gonl
+
+import (
+	"io"
+)
+
+// FramedBatchWriter is an io.WriteCloser that groups every N complete
+// lines into a single framed message, written to the underlying
+// io.WriteCloser with one Write call. Each message consists of
+// Prefix, followed by the lines excluding their newlines and joined
+// by Sep, followed by Suffix. When closed, any lines not yet forming
+// a full batch, including a final line not terminated by a newline,
+// are framed and written as a final short message.
+//
+//	// Emit each group of 100 JSON lines as a JSON array.
+//	fw := &gonl.FramedBatchWriter{
+//		WC:     conn,
+//		N:      100,
+//		Prefix: []byte("["),
+//		Sep:    []byte(","),
+//		Suffix: []byte("]\n"),
+//	}
+type FramedBatchWriter struct {
+	// WC is io.WriteCloser where data is ultimately written.
+	WC io.WriteCloser
+
+	// N is the number of lines framed in each message. Values less
+	// than 1 are treated as 1.
+	N int
+
+	// Prefix is written at the start of each message.
+	Prefix []byte
+
+	// Sep is written between consecutive lines of a message.
+	Sep []byte
+
+	// Suffix is written at the end of each message.
+	Suffix []byte
+
+	la lineAssembler
+
+	// batch holds the message being framed, and count is the number
+	// of lines it contains.
+	batch []byte
+	count int
+}
+
+// Close frames and writes any lines not yet written, then closes the
+// underlying io.WriteCloser.
+func (fw *FramedBatchWriter) Close() error {
+	err := fw.la.close(fw.emit)
+	if err == nil && fw.count > 0 {
+		err = fw.writeBatch()
+	}
+	fw.batch = nil
+	fw.count = 0
+	if err != nil {
+		_ = fw.WC.Close()
+		return err
+	}
+	return fw.WC.Close()
+}
+
+// Write adds each line completed by p to the current message, writing
+// the message to the underlying io.WriteCloser each time it reaches N
+// lines. It returns the number of bytes of p consumed.
+func (fw *FramedBatchWriter) Write(p []byte) (int, error) {
+	return fw.la.write(p, fw.emit)
+}
+
+// emit adds line to the current message, writing the message when it
+// reaches N lines. When that write fails, line is removed from the
+// message again, because lineAssembler treats it as not consumed, while
+// the lines added before it are kept, so the message is written in full
+// when the caller retries the Write.
+func (fw *FramedBatchWriter) emit(line []byte) error {
+	mark, count := len(fw.batch), fw.count
+	if fw.count == 0 {
+		fw.batch = append(fw.batch[:0], fw.Prefix...)
+	} else {
+		fw.batch = append(fw.batch, fw.Sep...)
+	}
+	fw.batch = append(fw.batch, trimNewline(line)...)
+	fw.count++
+
+	if fw.count < fw.N {
+		return nil
+	}
+	if err := fw.writeBatch(); err != nil {
+		if count == 0 {
+			mark = 0
+		}
+		fw.batch = fw.batch[:mark]
+		fw.count = count
+		return err
+	}
+	return nil
+}
+
+// writeBatch terminates the current message with Suffix and writes it,
+// then starts a new message. When the write fails, the caller is
+// responsible for restoring the message.
+func (fw *FramedBatchWriter) writeBatch() error {
+	fw.batch = append(fw.batch, fw.Suffix...)
+	fw.count = 0
+	_, err := fw.WC.Write(fw.batch)
+	return err
+}
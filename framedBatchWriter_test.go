@@ -0,0 +1,87 @@
+package gonl
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestFramedBatchWriter(t *testing.T) {
+	newFramedBatchWriter := func(output *testBuffer, n int) *FramedBatchWriter {
+		return &FramedBatchWriter{
+			WC:     output,
+			N:      n,
+			Prefix: []byte("["),
+			Sep:    []byte(","),
+			Suffix: []byte("]\n"),
+		}
+	}
+
+	t.Run("frames every N lines", func(t *testing.T) {
+		output := new(testBuffer)
+		fw := newFramedBatchWriter(output, 3)
+
+		ensureWrite(t, fw, "1\n2\n")
+		ensureStringer(t, output, "")
+		ensureWrite(t, fw, "3\n4\n5\n6\n7")
+		ensureStringer(t, output, "[1,2,3]\n[4,5,6]\n")
+		ensureErrorNil(t, fw.Close())
+		ensureStringer(t, output, "[1,2,3]\n[4,5,6]\n[7]\n")
+	})
+
+	t.Run("line split across writes", func(t *testing.T) {
+		output := new(testBuffer)
+		fw := newFramedBatchWriter(output, 2)
+
+		ensureWrite(t, fw, "li")
+		ensureWrite(t, fw, "ne 1\nline 2\nline 3\n")
+		ensureStringer(t, output, "[line 1,line 2]\n")
+		ensureErrorNil(t, fw.Close())
+		ensureStringer(t, output, "[line 1,line 2]\n[line 3]\n")
+	})
+
+	t.Run("close without lines", func(t *testing.T) {
+		output := new(testBuffer)
+		fw := newFramedBatchWriter(output, 2)
+
+		ensureWrite(t, fw, "1\n2\n")
+		ensureErrorNil(t, fw.Close())
+		ensureStringer(t, output, "[1,2]\n")
+	})
+
+	t.Run("N less than 1", func(t *testing.T) {
+		output := new(testBuffer)
+		fw := newFramedBatchWriter(output, 0)
+
+		ensureWrite(t, fw, "1\n2\n")
+		ensureStringer(t, output, "[1]\n[2]\n")
+		ensureErrorNil(t, fw.Close())
+	})
+
+	t.Run("write error", func(t *testing.T) {
+		fw := &FramedBatchWriter{WC: &errOnWrite{}, N: 1}
+		n, err := fw.Write([]byte("a\n"))
+		ensureError(t, err, "test write error")
+		if got, want := n, 0; got != want {
+			t.Errorf("GOT: %v; WANT: %v", got, want)
+		}
+		ensureError(t, fw.Close(), "test close error")
+	})
+
+	t.Run("write error keeps batch", func(t *testing.T) {
+		output := &flakyWriter{failures: 1, err: errors.New("transient")}
+		fw := &FramedBatchWriter{WC: flakyPlainWriter{output}, N: 3, Prefix: []byte("["), Sep: []byte(","), Suffix: []byte("]\n")}
+
+		n, err := fw.Write([]byte("a\nb\nc\nd\n"))
+		ensureError(t, err, "transient")
+		if got, want := n, 4; got != want {
+			t.Errorf("GOT: %v; WANT: %v", got, want)
+		}
+		ensureStringer(t, output, "[a,b") // half of the failed message
+		output.testBuffer = testBuffer{}
+
+		// Retry the bytes not consumed.
+		ensureWrite(t, fw, "c\nd\n")
+		ensureErrorNil(t, fw.Close())
+		ensureStringer(t, output, "[a,b,c]\n[d]\n")
+	})
+}
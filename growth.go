@@ -0,0 +1,46 @@
+package gonl
+
+import (
+	"errors"
+)
+
+// GrowFunc determines how the buffer of a BatchLineWriter grows. It is
+// invoked with the current capacity of the buffer and the number of
+// bytes the buffer must hold, which is larger than that capacity, and
+// returns the capacity of the new buffer. A result smaller than needed
+// is raised to needed.
+type GrowFunc func(capacity, needed int) int
+
+// GrowDoubling returns twice the capacity, which amortizes the cost of
+// copying when the amount of buffered data is unpredictable.
+func GrowDoubling(capacity, needed int) int { return 2 * capacity }
+
+// GrowFixed returns a GrowFunc that grows the buffer by the smallest
+// multiple of increment that holds the needed bytes, which avoids over
+// allocation when the size of batches is predictable. An increment
+// less than 1 is treated as 1.
+func GrowFixed(increment int) GrowFunc {
+	if increment < 1 {
+		increment = 1
+	}
+	return func(capacity, needed int) int {
+		steps := (needed - capacity + increment - 1) / increment
+		return capacity + steps*increment
+	}
+}
+
+// newCapacity returns the capacity of the buffer to allocate when it
+// must hold needed bytes, n of which are being added.
+func (lw *BatchLineWriter) newCapacity(needed, n int) int {
+	c := cap(lw.buf)
+	if lw.Grow == nil {
+		if c > maxInt-c-n {
+			panic(errors.New("gonl.BatchLineWriter: too large"))
+		}
+		return 2*c + n
+	}
+	if size := lw.Grow(c, needed); size > needed {
+		return size
+	}
+	return needed
+}
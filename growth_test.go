@@ -0,0 +1,75 @@
+package gonl
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestGrowFixed(t *testing.T) {
+	grow := GrowFixed(100)
+	for _, tc := range []struct{ capacity, needed, want int }{
+		{0, 1, 100},
+		{100, 101, 200},
+		{100, 350, 400},
+		{100, 300, 300},
+	} {
+		if got := grow(tc.capacity, tc.needed); got != tc.want {
+			t.Errorf("%d, %d: GOT: %v; WANT: %v", tc.capacity, tc.needed, got, tc.want)
+		}
+	}
+
+	if got, want := GrowFixed(0)(10, 12), 12; got != want {
+		t.Errorf("GOT: %v; WANT: %v", got, want)
+	}
+}
+
+func TestBatchLineWriterGrow(t *testing.T) {
+	t.Run("fixed", func(t *testing.T) {
+		output := new(testBuffer)
+		lw, err := NewBatchLineWriterOptions(output,
+			WithFlushThreshold(1024),
+			WithInitialCapacity(100),
+			WithGrowFunc(GrowFixed(100)),
+		)
+		ensureErrorNil(t, err)
+		if got, want := cap(lw.buf), 100; got != want {
+			t.Errorf("GOT: %v; WANT: %v", got, want)
+		}
+
+		ensureWrite(t, lw, strings.Repeat("a", 150))
+		if got, want := cap(lw.buf), 200; got != want {
+			t.Errorf("GOT: %v; WANT: %v", got, want)
+		}
+		ensureWrite(t, lw, strings.Repeat("b", 60))
+		if got, want := cap(lw.buf), 300; got != want {
+			t.Errorf("GOT: %v; WANT: %v", got, want)
+		}
+
+		ensureErrorNil(t, lw.Close())
+		ensureStringer(t, output, strings.Repeat("a", 150)+strings.Repeat("b", 60))
+	})
+
+	t.Run("doubling", func(t *testing.T) {
+		lw, err := NewBatchLineWriterOptions(new(testBuffer),
+			WithFlushThreshold(1024),
+			WithInitialCapacity(100),
+			WithGrowFunc(GrowDoubling),
+		)
+		ensureErrorNil(t, err)
+
+		ensureWrite(t, lw, strings.Repeat("a", 150))
+		if got, want := cap(lw.buf), 200; got != want {
+			t.Errorf("GOT: %v; WANT: %v", got, want)
+		}
+		ensureWrite(t, lw, strings.Repeat("b", 500))
+		if got, want := cap(lw.buf), 650; got != want {
+			t.Errorf("GOT: %v; WANT: %v", got, want)
+		}
+		ensureErrorNil(t, lw.Close())
+	})
+
+	t.Run("initial capacity", func(t *testing.T) {
+		_, err := NewBatchLineWriterOptions(new(testBuffer), WithInitialCapacity(-1))
+		ensureError(t, err, "initialCap less than 0")
+	})
+}
@@ -0,0 +1,105 @@
+package gonl
+
+import (
+	"bytes"
+	"compress/gzip"
+	"io"
+)
+
+// Frame describes one independently compressed frame written by a
+// GzipFrameWriter.
+type Frame struct {
+	// Offset and Size are the position and length of the compressed
+	// frame within the stream written to the underlying
+	// io.WriteCloser.
+	Offset, Size int64
+
+	// RawOffset and RawSize are the position and length of the
+	// uncompressed data of the frame within the stream written to the
+	// GzipFrameWriter.
+	RawOffset, RawSize int64
+}
+
+// GzipFrameWriter is an io.WriteCloser that compresses the data of
+// each Write as an independent gzip member, recording the offset of
+// each, so a consumer can seek to and decompress any one frame without
+// reading the rest of the stream. Because a sequence of gzip members is
+// itself a valid gzip stream, the whole output can also be decompressed
+// by any gzip reader. Wrapped by a BatchLineWriter, each frame holds
+// one batch of complete lines. Compressing small frames is less
+// effective than compressing a single stream, so a large flush
+// threshold works best.
+//
+//	fw, err := gonl.NewGzipFrameWriter(f, gzip.DefaultCompression)
+//	if err != nil {
+//		return err
+//	}
+//	lw, err := gonl.NewBatchLineWriter(fw, 1<<20)
+//
+// To read a single frame, decompress the section of the stream it
+// occupies:
+//
+//	zr, err := gzip.NewReader(io.NewSectionReader(f, frame.Offset, frame.Size))
+type GzipFrameWriter struct {
+	wc     io.WriteCloser
+	zw     *gzip.Writer
+	frame  bytes.Buffer
+	frames []Frame
+	offset int64
+	raw    int64
+}
+
+// NewGzipFrameWriter returns a new GzipFrameWriter that writes frames
+// compressed at the specified compression level to wc.
+func NewGzipFrameWriter(wc io.WriteCloser, level int) (*GzipFrameWriter, error) {
+	zw, err := gzip.NewWriterLevel(nil, level)
+	if err != nil {
+		return nil, err
+	}
+	return &GzipFrameWriter{wc: wc, zw: zw}, nil
+}
+
+// Close closes the underlying io.WriteCloser.
+func (fw *GzipFrameWriter) Close() error {
+	return fw.wc.Close()
+}
+
+// Frames returns the frames written so far, in order.
+func (fw *GzipFrameWriter) Frames() []Frame {
+	return append([]Frame(nil), fw.frames...)
+}
+
+// Write compresses p as a single frame, and writes the frame to the
+// underlying io.WriteCloser with one Write. It returns len(p) when the
+// frame is written in full, and 0 otherwise, in which case the frame
+// is not recorded. An empty p writes nothing.
+func (fw *GzipFrameWriter) Write(p []byte) (int, error) {
+	if len(p) == 0 {
+		return 0, nil
+	}
+	fw.frame.Reset()
+	fw.zw.Reset(&fw.frame)
+	if _, err := fw.zw.Write(p); err != nil {
+		return 0, err
+	}
+	if err := fw.zw.Close(); err != nil {
+		return 0, err
+	}
+
+	nw, err := fw.wc.Write(fw.frame.Bytes())
+	fw.offset += int64(nw) // keep offsets of later frames correct
+	if err != nil {
+		return 0, err
+	}
+	if nw < fw.frame.Len() {
+		return 0, io.ErrShortWrite
+	}
+	fw.frames = append(fw.frames, Frame{
+		Offset:    fw.offset - int64(nw),
+		Size:      int64(nw),
+		RawOffset: fw.raw,
+		RawSize:   int64(len(p)),
+	})
+	fw.raw += int64(len(p))
+	return len(p), nil
+}
@@ -0,0 +1,71 @@
+package gonl
+
+import (
+	"bytes"
+	"compress/gzip"
+	"io"
+	"io/ioutil"
+	"testing"
+)
+
+func TestGzipFrameWriter(t *testing.T) {
+	t.Run("frames batches", func(t *testing.T) {
+		output := new(testBuffer)
+		fw, err := NewGzipFrameWriter(output, gzip.BestCompression)
+		ensureErrorNil(t, err)
+		lw, err := NewBatchLineWriter(fw, 16)
+		ensureErrorNil(t, err)
+
+		ensureWrite(t, lw, "line 1\nline 2\nline 3\n")
+		ensureWrite(t, lw, "line 4\nline 5\nline 6\n")
+		ensureWrite(t, lw, "line 7")
+		ensureErrorNil(t, lw.Close())
+
+		frames := fw.Frames()
+		if got, want := len(frames), 3; got != want {
+			t.Fatalf("GOT: %v; WANT: %v", got, want)
+		}
+		data := output.Bytes()
+		for i, want := range []string{"line 1\nline 2\nline 3\n", "line 4\nline 5\nline 6\n", "line 7"} {
+			frame := frames[i]
+			zr, err := gzip.NewReader(io.NewSectionReader(bytes.NewReader(data), frame.Offset, frame.Size))
+			ensureErrorNil(t, err)
+			buf, err := ioutil.ReadAll(zr)
+			ensureErrorNil(t, err)
+			if got := string(buf); got != want {
+				t.Errorf("GOT: %q; WANT: %q", got, want)
+			}
+			if got, want := frame.RawSize, int64(len(want)); got != want {
+				t.Errorf("GOT: %v; WANT: %v", got, want)
+			}
+		}
+		if got, want := frames[2].Offset+frames[2].Size, int64(len(data)); got != want {
+			t.Errorf("GOT: %v; WANT: %v", got, want)
+		}
+		if got, want := frames[2].RawOffset, int64(42); got != want {
+			t.Errorf("GOT: %v; WANT: %v", got, want)
+		}
+
+		// The stream as a whole is valid gzip.
+		all, err := gunzip(t, data)
+		ensureErrorNil(t, err)
+		if want := "line 1\nline 2\nline 3\nline 4\nline 5\nline 6\nline 7"; all != want {
+			t.Errorf("GOT: %q; WANT: %q", all, want)
+		}
+	})
+
+	t.Run("invalid level", func(t *testing.T) {
+		_, err := NewGzipFrameWriter(new(testBuffer), 42)
+		ensureError(t, err, "invalid compression level")
+	})
+
+	t.Run("write error", func(t *testing.T) {
+		fw, err := NewGzipFrameWriter(&errOnWrite{}, gzip.DefaultCompression)
+		ensureErrorNil(t, err)
+		_, err = fw.Write([]byte("line 1\n"))
+		ensureError(t, err, "test write error")
+		if got := len(fw.Frames()); got != 0 {
+			t.Errorf("GOT: %v; WANT: %v", got, 0)
+		}
+	})
+}
@@ -0,0 +1,59 @@
+package gonl
+
+import (
+	"compress/gzip"
+	"io"
+)
+
+// NewGzipLineWriter returns a new BatchLineWriter, configured by the
+// specified options as NewBatchLineWriterOptions does, that compresses
+// its output with gzip at the specified compression level, such as
+// gzip.DefaultCompression, before writing it to wc. After compressing
+// each batch of complete lines, it flushes the gzip.Writer, so a
+// reader of the compressed stream written so far, such as one tailing
+// a compressed log file, can always decode up to the final complete
+// line. Closing the returned BatchLineWriter writes the gzip footer,
+// then closes wc.
+//
+// Each flush of the gzip.Writer costs a few bytes of output and
+// reduces the compression ratio, so a larger flush threshold compresses
+// better. FlushPartialWhenFull should not be set, because the stream
+// would then be flushed in the middle of a line.
+//
+//	lw, err := gonl.NewGzipLineWriter(f, gzip.DefaultCompression,
+//		gonl.WithFlushInterval(time.Second))
+func NewGzipLineWriter(wc io.WriteCloser, level int, options ...Option) (*BatchLineWriter, error) {
+	zw, err := gzip.NewWriterLevel(wc, level)
+	if err != nil {
+		return nil, err
+	}
+	return NewBatchLineWriterOptions(&gzipFlusher{zw: zw, wc: wc}, options...)
+}
+
+// gzipFlusher is an io.WriteCloser that compresses each Write with zw,
+// then flushes zw, so the compressed output ends on the same boundary
+// as the Write.
+type gzipFlusher struct {
+	zw *gzip.Writer
+	wc io.WriteCloser
+}
+
+// Close writes the gzip footer, then closes the underlying
+// io.WriteCloser.
+func (gf *gzipFlusher) Close() error {
+	err := gf.zw.Close()
+	if cerr := gf.wc.Close(); err == nil {
+		err = cerr
+	}
+	return err
+}
+
+// Write compresses p, then flushes the compressed data to the
+// underlying io.WriteCloser.
+func (gf *gzipFlusher) Write(p []byte) (int, error) {
+	n, err := gf.zw.Write(p)
+	if err != nil {
+		return n, err
+	}
+	return n, gf.zw.Flush()
+}
@@ -0,0 +1,57 @@
+package gonl
+
+import (
+	"bytes"
+	"compress/gzip"
+	"io"
+	"io/ioutil"
+	"testing"
+)
+
+// gunzip returns the decompressed contents of the possibly incomplete
+// gzip stream data, along with the error that ended decompression.
+func gunzip(t *testing.T, data []byte) (string, error) {
+	t.Helper()
+	zr, err := gzip.NewReader(bytes.NewReader(data))
+	ensureErrorNil(t, err)
+	buf, err := ioutil.ReadAll(zr)
+	return string(buf), err
+}
+
+func TestNewGzipLineWriter(t *testing.T) {
+	t.Run("decodable at line boundaries", func(t *testing.T) {
+		output := new(testBuffer)
+		lw, err := NewGzipLineWriter(output, gzip.BestSpeed, WithFlushThreshold(16))
+		ensureErrorNil(t, err)
+
+		ensureWrite(t, lw, "line 1\nline 2\nline 3\nli")
+		got, err := gunzip(t, output.Bytes())
+		if err != io.ErrUnexpectedEOF {
+			t.Errorf("GOT: %v; WANT: %v", err, io.ErrUnexpectedEOF)
+		}
+		if want := "line 1\nline 2\nline 3\n"; got != want {
+			t.Errorf("GOT: %q; WANT: %q", got, want)
+		}
+
+		ensureWrite(t, lw, "ne 4")
+		ensureErrorNil(t, lw.Close())
+		got, err = gunzip(t, output.Bytes())
+		ensureErrorNil(t, err)
+		if want := "line 1\nline 2\nline 3\nline 4"; got != want {
+			t.Errorf("GOT: %q; WANT: %q", got, want)
+		}
+	})
+
+	t.Run("invalid level", func(t *testing.T) {
+		_, err := NewGzipLineWriter(new(testBuffer), 42)
+		ensureError(t, err, "invalid compression level")
+	})
+
+	t.Run("write error", func(t *testing.T) {
+		lw, err := NewGzipLineWriter(&errOnWrite{}, gzip.DefaultCompression, WithFlushThreshold(4))
+		ensureErrorNil(t, err)
+		_, err = lw.Write([]byte("line 1\n"))
+		ensureError(t, err, "test write error")
+		ensureError(t, lw.Close(), "test write error")
+	})
+}
@@ -0,0 +1,140 @@
+package gonl
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+)
+
+// JSONLError is returned by a JSONLReader when a line cannot be
+// decoded.
+type JSONLError struct {
+	// Line is the number of the line, counting from 1.
+	Line int64
+
+	// Err is the error from decoding the line.
+	Err error
+}
+
+func (e *JSONLError) Error() string {
+	return fmt.Sprintf("gonl: line %d: %v", e.Line, e.Err)
+}
+
+// Unwrap returns the error from decoding the line.
+func (e *JSONLError) Unwrap() error { return e.Err }
+
+// JSONLReader reads JSON Lines, also known as NDJSON, from the source
+// io.Reader, decoding one JSON document from each line. It is the read
+// side complement of JSONLWriter. Blank lines are ignored, and there is
+// no limit on the length of a line. An error decoding a line is
+// returned as a *JSONLError, after which the following line may still
+// be read.
+//
+//	jr := &gonl.JSONLReader{R: f}
+//	for {
+//		var event Event
+//		if err := jr.Decode(&event); err == io.EOF {
+//			break
+//		} else if err != nil {
+//			return err
+//		}
+//		// ...
+//	}
+type JSONLReader struct {
+	// R is the io.Reader from which data is read.
+	R io.Reader
+
+	// SkipMalformed, when set, causes lines that are not valid JSON to
+	// be skipped rather than returned as an error. Valid JSON that
+	// cannot be decoded into the value passed to Decode is still
+	// returned as an error.
+	SkipMalformed bool
+
+	br      *bufio.Reader
+	long    []byte // line longer than the buffer of br
+	line    int64
+	skipped int64
+}
+
+// Decode decodes the JSON document on the next line into v, as
+// json.Unmarshal does. It returns io.EOF when there are no more lines.
+func (jr *JSONLReader) Decode(v interface{}) error {
+	data, err := jr.next()
+	if err != nil {
+		return err
+	}
+	if err = json.Unmarshal(data, v); err != nil {
+		return &JSONLError{Line: jr.line, Err: err}
+	}
+	return nil
+}
+
+// Next returns the JSON document on the next line, without leading or
+// trailing whitespace. It returns io.EOF when there are no more lines.
+// The returned slice is a copy, and remains valid after subsequent
+// reads.
+func (jr *JSONLReader) Next() (json.RawMessage, error) {
+	data, err := jr.next()
+	if err != nil {
+		return nil, err
+	}
+	return append(json.RawMessage(nil), data...), nil
+}
+
+// Line returns the number of the last line read, counting from 1.
+func (jr *JSONLReader) Line() int64 { return jr.line }
+
+// Skipped returns the number of malformed lines skipped because
+// SkipMalformed is set.
+func (jr *JSONLReader) Skipped() int64 { return jr.skipped }
+
+// next returns the next line that is not blank, validated as JSON. The
+// slice is only valid until the following call.
+func (jr *JSONLReader) next() ([]byte, error) {
+	for {
+		line, err := jr.readLine()
+		if err != nil {
+			return nil, err
+		}
+		line = bytes.TrimSpace(line)
+		if len(line) == 0 {
+			continue
+		}
+		if json.Valid(line) {
+			return line, nil
+		}
+		if jr.SkipMalformed {
+			jr.skipped++
+			continue
+		}
+		// Decode the line again only to obtain a descriptive error.
+		return nil, &JSONLError{Line: jr.line, Err: json.Unmarshal(line, new(json.RawMessage))}
+	}
+}
+
+// readLine returns the next line, including its terminating newline
+// when it has one. The slice is only valid until the following call.
+func (jr *JSONLReader) readLine() ([]byte, error) {
+	if jr.br == nil {
+		jr.br = bufio.NewReader(jr.R)
+	}
+	line, err := jr.br.ReadSlice('\n')
+	if err == bufio.ErrBufferFull {
+		jr.long = append(jr.long[:0], line...)
+		for err == bufio.ErrBufferFull {
+			line, err = jr.br.ReadSlice('\n')
+			jr.long = append(jr.long, line...)
+		}
+		line = jr.long
+	}
+	if len(line) == 0 {
+		return nil, err
+	}
+	if err != nil && err != io.EOF {
+		return nil, err
+	}
+	jr.line++
+	return line, nil
+}
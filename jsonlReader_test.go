@@ -0,0 +1,101 @@
+package gonl
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+)
+
+func TestJSONLReader(t *testing.T) {
+	t.Run("decode", func(t *testing.T) {
+		jr := &JSONLReader{R: strings.NewReader("{\"n\":1}\n\n  {\"n\":2}  \r\n{\"n\":3}")}
+
+		for want := 1; want <= 3; want++ {
+			var v struct{ N int }
+			ensureErrorNil(t, jr.Decode(&v))
+			if got := v.N; got != want {
+				t.Errorf("GOT: %v; WANT: %v", got, want)
+			}
+		}
+		if got, want := jr.Decode(new(interface{})), io.EOF; got != want {
+			t.Errorf("GOT: %v; WANT: %v", got, want)
+		}
+		if got, want := jr.Line(), int64(4); got != want {
+			t.Errorf("GOT: %v; WANT: %v", got, want)
+		}
+	})
+
+	t.Run("next", func(t *testing.T) {
+		long := `"` + strings.Repeat("x", 10000) + `"`
+		jr := &JSONLReader{R: strings.NewReader("[1, 2]\n" + long + "\n")}
+
+		raw, err := jr.Next()
+		ensureErrorNil(t, err)
+		if got, want := string(raw), "[1, 2]"; got != want {
+			t.Errorf("GOT: %q; WANT: %q", got, want)
+		}
+		raw, err = jr.Next()
+		ensureErrorNil(t, err)
+		if got, want := string(raw), long; got != want {
+			t.Errorf("GOT: %d bytes; WANT: %d bytes", len(got), len(want))
+		}
+		_, err = jr.Next()
+		if got, want := err, io.EOF; got != want {
+			t.Errorf("GOT: %v; WANT: %v", got, want)
+		}
+	})
+
+	t.Run("malformed line", func(t *testing.T) {
+		jr := &JSONLReader{R: strings.NewReader("1\n{oops\n3\n")}
+
+		var v int
+		ensureErrorNil(t, jr.Decode(&v))
+		err := jr.Decode(&v)
+		var je *JSONLError
+		if !errors.As(err, &je) {
+			t.Fatalf("GOT: %v; WANT: %T", err, je)
+		}
+		if got, want := je.Line, int64(2); got != want {
+			t.Errorf("GOT: %v; WANT: %v", got, want)
+		}
+		var se *json.SyntaxError
+		if !errors.As(err, &se) {
+			t.Errorf("GOT: %v; WANT: %T", err, se)
+		}
+		ensureError(t, err, "line 2: invalid character")
+
+		// The following line may still be read.
+		ensureErrorNil(t, jr.Decode(&v))
+		if got, want := v, 3; got != want {
+			t.Errorf("GOT: %v; WANT: %v", got, want)
+		}
+	})
+
+	t.Run("skip malformed", func(t *testing.T) {
+		jr := &JSONLReader{R: strings.NewReader("1\n{oops\n\"two\"\n3\n"), SkipMalformed: true}
+
+		var v int
+		ensureErrorNil(t, jr.Decode(&v))
+		err := jr.Decode(&v) // valid JSON of the wrong type is not skipped
+		ensureError(t, err, "line 3: json: cannot unmarshal string")
+		ensureErrorNil(t, jr.Decode(&v))
+		if got, want := v, 3; got != want {
+			t.Errorf("GOT: %v; WANT: %v", got, want)
+		}
+		if got, want := jr.Skipped(), int64(1); got != want {
+			t.Errorf("GOT: %v; WANT: %v", got, want)
+		}
+	})
+
+	t.Run("read error", func(t *testing.T) {
+		jr := &JSONLReader{R: &testReader{tuples: []tuple{
+			{"1\n2", errors.New("test read error")},
+		}}}
+
+		var v int
+		ensureErrorNil(t, jr.Decode(&v))
+		ensureError(t, jr.Decode(&v), "test read error")
+	})
+}
@@ -0,0 +1,60 @@
+package gonl
+
+import (
+	"encoding/json"
+	"io"
+)
+
+// JSONLWriter encodes values as JSON Lines, also known as NDJSON, one
+// JSON document per line, batching the lines with a BatchLineWriter
+// before writing them to the underlying io.WriteCloser. Because
+// json.Marshal escapes newlines within strings, and compacts the
+// output of any json.Marshaler, each encoded value never contains a
+// raw newline, and so always occupies exactly one line.
+//
+// A JSONLWriter is not safe for concurrent use by multiple goroutines.
+//
+//	jw, err := gonl.NewJSONLWriter(conn, gonl.WithFlushInterval(time.Second))
+//	if err != nil {
+//		return err
+//	}
+//	defer jw.Close()
+//	err = jw.Encode(event)
+type JSONLWriter struct {
+	lw      *BatchLineWriter
+	scratch []byte
+}
+
+// NewJSONLWriter returns a new JSONLWriter that writes to wc through a
+// BatchLineWriter configured by the specified options, as
+// NewBatchLineWriterOptions does.
+func NewJSONLWriter(wc io.WriteCloser, options ...Option) (*JSONLWriter, error) {
+	lw, err := NewBatchLineWriterOptions(wc, options...)
+	if err != nil {
+		return nil, err
+	}
+	return &JSONLWriter{lw: lw}, nil
+}
+
+// Close flushes any buffered lines, then closes the underlying
+// io.WriteCloser.
+func (jw *JSONLWriter) Close() error {
+	jw.scratch = nil
+	return jw.lw.Close()
+}
+
+// Encode writes the JSON encoding of v, followed by a newline. When v
+// cannot be encoded, Encode returns the error from json.Marshal and
+// nothing is written.
+func (jw *JSONLWriter) Encode(v interface{}) error {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+	jw.scratch = append(append(jw.scratch[:0], data...), '\n')
+	_, err = jw.lw.Write(jw.scratch)
+	return err
+}
+
+// Stats returns the statistics of the underlying BatchLineWriter.
+func (jw *JSONLWriter) Stats() Stats { return jw.lw.Stats() }
@@ -0,0 +1,40 @@
+package gonl
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestJSONLWriter(t *testing.T) {
+	t.Run("encodes one value per line", func(t *testing.T) {
+		output := new(testBuffer)
+		jw, err := NewJSONLWriter(output, WithFlushThreshold(64))
+		ensureErrorNil(t, err)
+
+		ensureErrorNil(t, jw.Encode(map[string]string{"msg": "line 1\nline 2"}))
+		ensureErrorNil(t, jw.Encode(json.RawMessage("{\n  \"a\": 1\n}")))
+		ensureErrorNil(t, jw.Encode(42))
+		ensureStringer(t, output, "") // batched
+		ensureErrorNil(t, jw.Close())
+		ensureStringer(t, output, "{\"msg\":\"line 1\\nline 2\"}\n{\"a\":1}\n42\n")
+
+		if got, want := jw.Stats().Lines, int64(3); got != want {
+			t.Errorf("GOT: %v; WANT: %v", got, want)
+		}
+	})
+
+	t.Run("marshal error", func(t *testing.T) {
+		output := new(testBuffer)
+		jw, err := NewJSONLWriter(output)
+		ensureErrorNil(t, err)
+
+		ensureError(t, jw.Encode(make(chan int)), "unsupported type")
+		ensureErrorNil(t, jw.Close())
+		ensureStringer(t, output, "")
+	})
+
+	t.Run("invalid option", func(t *testing.T) {
+		_, err := NewJSONLWriter(new(testBuffer), WithFlushThreshold(0))
+		ensureError(t, err, "flushThreshold")
+	})
+}
@@ -0,0 +1,57 @@
+package gonl
+
+import (
+	"bytes"
+	"unicode/utf8"
+)
+
+// crlf is the carriage return and line feed sequence.
+var crlf = []byte{'\r', '\n'}
+
+// LastIndexDelim returns the index of the final occurrence of delim in
+// buf, or -1 when buf does not contain delim. This is the routine
+// BatchLineWriter uses to find the final line boundary in its buffer,
+// exported so that other line boundary aware code remains consistent
+// with it. The bytes up to and including the returned index form the
+// complete lines of buf.
+func LastIndexDelim(buf []byte, delim byte) int {
+	return bytes.LastIndexByte(buf, delim)
+}
+
+// LastIndexCRLF returns the index of the line feed of the final CRLF
+// sequence in buf, or -1 when buf does not contain one. Like
+// LastIndexDelim, it returns the index of the final byte of the
+// delimiter, so the bytes up to and including the returned index form
+// the complete CRLF terminated lines of buf.
+func LastIndexCRLF(buf []byte) int {
+	return lastIndexDelimBytes(buf, crlf)
+}
+
+// lastIndexDelimBytes returns the index of the final byte of the final
+// occurrence of delim in buf, or -1 when buf does not contain delim.
+func lastIndexDelimBytes(buf, delim []byte) int {
+	if len(delim) == 1 {
+		return LastIndexDelim(buf, delim[0])
+	}
+	index := bytes.LastIndex(buf, delim)
+	if index == -1 {
+		return -1
+	}
+	return index + len(delim) - 1
+}
+
+// LastIndexRune returns the index of the final byte of the final
+// occurrence of the UTF-8 encoding of r in buf, or -1 when buf does
+// not contain it. Like LastIndexDelim, it returns the index of the
+// final byte of the delimiter, so the bytes up to and including the
+// returned index form the complete lines of buf terminated by r. When
+// r is not a valid rune, it searches for the encoding of
+// utf8.RuneError.
+func LastIndexRune(buf []byte, r rune) int {
+	if r >= 0 && r < utf8.RuneSelf {
+		return bytes.LastIndexByte(buf, byte(r))
+	}
+	var encoded [utf8.UTFMax]byte
+	n := utf8.EncodeRune(encoded[:], r)
+	return lastIndexDelimBytes(buf, encoded[:n])
+}
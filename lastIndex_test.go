@@ -0,0 +1,66 @@
+package gonl
+
+import (
+	"testing"
+)
+
+func TestLastIndexDelim(t *testing.T) {
+	cases := []struct {
+		buf  string
+		want int
+	}{
+		{"", -1},
+		{"no delimiter", -1},
+		{"\n", 0},
+		{"line 1\nline 2", 6},
+		{"line 1\nline 2\n", 13},
+	}
+	for _, c := range cases {
+		if got := LastIndexDelim([]byte(c.buf), '\n'); got != c.want {
+			t.Errorf("%q: GOT: %v; WANT: %v", c.buf, got, c.want)
+		}
+	}
+
+	if got, want := LastIndexDelim([]byte("a\x00b\x00"), 0), 3; got != want {
+		t.Errorf("GOT: %v; WANT: %v", got, want)
+	}
+}
+
+func TestLastIndexCRLF(t *testing.T) {
+	cases := []struct {
+		buf  string
+		want int
+	}{
+		{"", -1},
+		{"no delimiter", -1},
+		{"lone\nline\rfeeds", -1},
+		{"\r\n", 1},
+		{"line 1\r\nline 2", 7},
+		{"line 1\r\nline 2\r\n", 15},
+	}
+	for _, c := range cases {
+		if got := LastIndexCRLF([]byte(c.buf)); got != c.want {
+			t.Errorf("%q: GOT: %v; WANT: %v", c.buf, got, c.want)
+		}
+	}
+}
+
+func TestLastIndexRune(t *testing.T) {
+	cases := []struct {
+		buf  string
+		r    rune
+		want int
+	}{
+		{"", '\n', -1},
+		{"abc", '¶', -1},
+		{"line 1¶line 2", '¶', 7},
+		{"line 1¶line 2¶", '¶', 15},
+		{"line 1\nline 2\n", '\n', 13},
+		{"bad�", -1, 5},
+	}
+	for _, c := range cases {
+		if got := LastIndexRune([]byte(c.buf), c.r); got != c.want {
+			t.Errorf("%q: GOT: %v; WANT: %v", c.buf, got, c.want)
+		}
+	}
+}
@@ -0,0 +1,73 @@
+package gonl
+
+import (
+	"bytes"
+)
+
+// lineAssembler reassembles newline terminated lines from arbitrary
+// chunks of bytes, such as the slices passed to successive Write
+// calls, for the writers in this package that process one line at a
+// time.
+type lineAssembler struct {
+	// buf holds the partial line not yet terminated by a newline.
+	buf []byte
+}
+
+// buffered returns the number of bytes of partial line held.
+func (la *lineAssembler) buffered() int { return len(la.buf) }
+
+// write invokes emit for each line completed by p, including its
+// terminating newline, then retains any trailing partial line. A line
+// wholly contained in p is passed to emit without being copied, so
+// emit must not retain the slice it is given. It returns the number
+// of bytes of p consumed, which is len(p) unless emit returns an
+// error, in which case it is the number of bytes of p preceding the
+// line for which emit failed, and that line remains unconsumed.
+func (la *lineAssembler) write(p []byte, emit func(line []byte) error) (int, error) {
+	var n int
+	for {
+		index := bytes.IndexByte(p[n:], '\n')
+		if index == -1 {
+			la.buf = append(la.buf, p[n:]...)
+			return len(p), nil
+		}
+		end := n + index + 1
+
+		line := p[n:end]
+		if len(la.buf) > 0 {
+			la.buf = append(la.buf, line...)
+			line = la.buf
+		}
+
+		if err := emit(line); err != nil {
+			if len(la.buf) > 0 {
+				// Restore partial line.
+				la.buf = la.buf[:len(la.buf)-(end-n)]
+			}
+			return n, err
+		}
+
+		la.buf = la.buf[:0]
+		n = end
+	}
+}
+
+// close invokes emit with the partial line, which is not terminated
+// by a newline, when there is one.
+func (la *lineAssembler) close(emit func(line []byte) error) error {
+	if len(la.buf) == 0 {
+		return nil
+	}
+	line := la.buf
+	la.buf = nil
+	return emit(line)
+}
+
+// trimNewline returns line without its terminating newline, if it has
+// one.
+func trimNewline(line []byte) []byte {
+	if l := len(line); l > 0 && line[l-1] == '\n' {
+		return line[:l-1]
+	}
+	return line
+}
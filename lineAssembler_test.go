@@ -0,0 +1,85 @@
+package gonl
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestLineAssembler(t *testing.T) {
+	var lines []string
+	collect := func(line []byte) error {
+		lines = append(lines, string(line))
+		return nil
+	}
+
+	t.Run("reassembles lines", func(t *testing.T) {
+		lines = nil
+		var la lineAssembler
+
+		for _, p := range []string{"line 1\nli", "ne ", "2\nline 3\n", "line 4"} {
+			n, err := la.write([]byte(p), collect)
+			ensureErrorNil(t, err)
+			if got, want := n, len(p); got != want {
+				t.Errorf("GOT: %v; WANT: %v", got, want)
+			}
+		}
+		if got, want := la.buffered(), 6; got != want {
+			t.Errorf("GOT: %v; WANT: %v", got, want)
+		}
+		ensureErrorNil(t, la.close(collect))
+
+		want := []string{"line 1\n", "line 2\n", "line 3\n", "line 4"}
+		if got, want := len(lines), len(want); got != want {
+			t.Fatalf("GOT: %v; WANT: %v", got, want)
+		}
+		for i := range want {
+			if got, want := lines[i], want[i]; got != want {
+				t.Errorf("GOT: %q; WANT: %q", got, want)
+			}
+		}
+	})
+
+	t.Run("emit error", func(t *testing.T) {
+		var la lineAssembler
+		fail := func(line []byte) error {
+			if string(line) == "line 2\n" {
+				return errors.New("emit failed")
+			}
+			return nil
+		}
+
+		_, err := la.write([]byte("li"), fail)
+		ensureErrorNil(t, err)
+
+		n, err := la.write([]byte("ne 1\nline 2\n"), fail)
+		ensureError(t, err, "emit failed")
+		if got, want := n, 5; got != want {
+			t.Errorf("GOT: %v; WANT: %v", got, want)
+		}
+
+		lines = nil
+		_, err = la.write([]byte("ne 2\n"), collect)
+		ensureErrorNil(t, err)
+		if got, want := len(lines), 1; got != want {
+			t.Fatalf("GOT: %v; WANT: %v", got, want)
+		}
+		if got, want := lines[0], "ne 2\n"; got != want {
+			t.Errorf("GOT: %q; WANT: %q", got, want)
+		}
+	})
+
+	t.Run("emit error restores partial line", func(t *testing.T) {
+		var la lineAssembler
+		_, err := la.write([]byte("li"), collect)
+		ensureErrorNil(t, err)
+
+		n, err := la.write([]byte("ne 1\n"), func([]byte) error { return errors.New("emit failed") })
+		ensureError(t, err, "emit failed")
+		if got, want := n, 0; got != want {
+			t.Errorf("GOT: %v; WANT: %v", got, want)
+		}
+		if got, want := la.buffered(), 2; got != want {
+			t.Errorf("GOT: %v; WANT: %v", got, want)
+		}
+	})
+}
@@ -0,0 +1,81 @@
+package gonl
+
+import (
+	"bytes"
+	"errors"
+	"io"
+)
+
+// LineBuffer accumulates bytes written to it, and implements
+// io.WriterTo to drain its complete lines into any io.Writer at
+// once. This decouples the accumulation of lines from their eventual
+// destination.
+//
+// A trailing partial line, one without a terminating newline, is
+// retained in the LineBuffer by WriteTo until it is completed by a
+// subsequent Write.
+//
+// The zero value of LineBuffer is an empty buffer ready to use.
+type LineBuffer struct {
+	buf []byte
+
+	// lines is the number of newlines in buf.
+	lines int
+
+	// -1 when no newlines in buf; only valid when lines > 0
+	indexOfFinalNewline int
+}
+
+// Len returns the number of bytes held, including any trailing
+// partial line.
+func (lb *LineBuffer) Len() int { return len(lb.buf) }
+
+// Lines returns the number of complete lines held.
+func (lb *LineBuffer) Lines() int { return lb.lines }
+
+// Write appends p to the buffer. It always returns len(p) and a nil
+// error.
+func (lb *LineBuffer) Write(p []byte) (int, error) {
+	if n := bytes.Count(p, newline); n > 0 {
+		lb.indexOfFinalNewline = len(lb.buf) + bytes.LastIndexByte(p, '\n')
+		lb.lines += n
+	}
+	lb.buf = append(lb.buf, p...)
+	return len(p), nil
+}
+
+// WriteTo writes all complete lines held to w using a single Write
+// call, then removes them from the buffer. It returns the number of
+// bytes written and any error encountered. When w writes only some of
+// the bytes, the bytes that were written are removed from the buffer,
+// while the remainder are retained.
+func (lb *LineBuffer) WriteTo(w io.Writer) (int64, error) {
+	if lb.lines == 0 {
+		return 0, nil
+	}
+
+	index := lb.indexOfFinalNewline + 1
+	nw, err := w.Write(lb.buf[:index])
+	if nw < 0 || nw > index {
+		return 0, errors.New("invalid write result")
+	}
+	if nw == index {
+		lb.consume(nw, lb.lines)
+		return int64(nw), err
+	}
+	if err == nil {
+		err = io.ErrShortWrite
+	}
+	lb.consume(nw, bytes.Count(lb.buf[:nw], newline))
+	return int64(nw), err
+}
+
+// consume removes the first n bytes, which contain the specified
+// number of newlines, from the buffer.
+func (lb *LineBuffer) consume(n, lines int) {
+	lb.buf = lb.buf[:copy(lb.buf, lb.buf[n:])]
+	lb.lines -= lines
+	if lb.lines > 0 {
+		lb.indexOfFinalNewline -= n
+	}
+}
@@ -0,0 +1,75 @@
+package gonl
+
+import (
+	"io"
+	"testing"
+)
+
+func TestLineBuffer(t *testing.T) {
+	t.Run("empty", func(t *testing.T) {
+		lb := new(LineBuffer)
+		output := new(testBuffer)
+
+		n, err := lb.WriteTo(output)
+		ensureErrorNil(t, err)
+		if got, want := n, int64(0); got != want {
+			t.Errorf("GOT: %v; WANT: %v", got, want)
+		}
+		if got, want := lb.Lines(), 0; got != want {
+			t.Errorf("GOT: %v; WANT: %v", got, want)
+		}
+	})
+
+	t.Run("retains partial line", func(t *testing.T) {
+		lb := new(LineBuffer)
+		ensureWrite(t, lb, "line 1\nline 2\nli")
+		if got, want := lb.Lines(), 2; got != want {
+			t.Errorf("GOT: %v; WANT: %v", got, want)
+		}
+
+		output := new(testBuffer)
+		n, err := lb.WriteTo(output)
+		ensureErrorNil(t, err)
+		if got, want := n, int64(14); got != want {
+			t.Errorf("GOT: %v; WANT: %v", got, want)
+		}
+		ensureStringer(t, output, "line 1\nline 2\n")
+		if got, want := lb.Lines(), 0; got != want {
+			t.Errorf("GOT: %v; WANT: %v", got, want)
+		}
+		if got, want := lb.Len(), 2; got != want {
+			t.Errorf("GOT: %v; WANT: %v", got, want)
+		}
+
+		ensureWrite(t, lb, "ne 3\n")
+		if got, want := lb.Lines(), 1; got != want {
+			t.Errorf("GOT: %v; WANT: %v", got, want)
+		}
+		_, err = lb.WriteTo(output)
+		ensureErrorNil(t, err)
+		ensureStringer(t, output, "line 1\nline 2\nline 3\n")
+	})
+
+	t.Run("short write", func(t *testing.T) {
+		lb := new(LineBuffer)
+		ensureWrite(t, lb, "line 1\nline 2\nline 3")
+
+		output := new(testBuffer)
+		n, err := lb.WriteTo(ShortWriter(output, 9))
+		ensureError(t, err, io.ErrShortWrite.Error())
+		if got, want := n, int64(9); got != want {
+			t.Errorf("GOT: %v; WANT: %v", got, want)
+		}
+		ensureStringer(t, output, "line 1\nli")
+		if got, want := lb.Lines(), 1; got != want {
+			t.Errorf("GOT: %v; WANT: %v", got, want)
+		}
+
+		_, err = lb.WriteTo(output)
+		ensureErrorNil(t, err)
+		ensureStringer(t, output, "line 1\nline 2\n")
+		if got, want := lb.Len(), 6; got != want {
+			t.Errorf("GOT: %v; WANT: %v", got, want)
+		}
+	})
+}
@@ -0,0 +1,220 @@
+package gonl
+
+import (
+	"bytes"
+	"errors"
+	"io"
+)
+
+// LineBufferedReader reads from the source io.Reader and only fills
+// the caller's buffer with complete newline terminated lines, holding
+// back any trailing partial line until more data arrives from the
+// source io.Reader.
+//
+// It is the read side analog of BatchLineWriter flushing on newline
+// boundaries. Each Read returns as many complete lines as fit in the
+// provided buffer. The only two exceptions are when a single line is
+// longer than the provided buffer, in which case the buffer is filled
+// with as much of that line as fits, and after the source io.Reader
+// returns an error, in which case the final bytes not terminated by a
+// newline are returned before the error.
+//
+//	r := &gonl.LineBufferedReader{R: conn}
+//	n, err := r.Read(buf) // buf[:n] ends with a newline
+type LineBufferedReader struct {
+	// R is the io.Reader from which data is read.
+	R io.Reader
+
+	// Delim, when not empty, is the sequence of bytes terminating
+	// each line, such as NUL, rather than a newline.
+	Delim []byte
+
+	rb readBuffer
+}
+
+// Read reads up to len(p) bytes into p, ending on a newline boundary
+// unless a single line is longer than p or the source io.Reader has
+// returned an error. It returns the number of bytes read (0 <= n <=
+// len(p)) and any error encountered.
+func (r *LineBufferedReader) Read(p []byte) (int, error) {
+	return r.rb.read(r.R, p, r.Delim, true)
+}
+
+// WriteTo writes data to w until the source io.Reader returns an error,
+// such as io.EOF, with each Write ending on a newline boundary, except
+// for the final bytes not terminated by a newline. It returns the number
+// of bytes written, along with any error encountered other than io.EOF.
+//
+// This method is provided to satisfy the io.WriterTo interface, which
+// the io.Copy function uses if available.
+func (r *LineBufferedReader) WriteTo(w io.Writer) (int64, error) {
+	return r.rb.writeTo(r.R, w, r.Delim, true)
+}
+
+// readBuffer holds data read from a source io.Reader that has not yet
+// been returned to the caller, for the readers in this package that
+// only return data on newline boundaries.
+type readBuffer struct {
+	// contents buf[off:len(buf)]
+	buf []byte
+	off int
+
+	// err is the error most recently returned by the source
+	// io.Reader.
+	err error
+
+	// readSize is the minimum free space offered to the source
+	// io.Reader on each Read, or minRead when zero.
+	readSize int
+}
+
+// read copies data into p, ending at a delimiter in the window of
+// buffered data that fits in p, which is the final delimiter in the
+// window when last is set, and the first otherwise, reading more data
+// from r as needed. When delim is empty, lines end with a newline. It
+// returns the number of bytes read (0 <= n <= len(p)) and any error
+// encountered.
+func (rb *readBuffer) read(r io.Reader, p []byte, delim []byte, last bool) (int, error) {
+	if len(p) == 0 {
+		return 0, nil // from io.Reader documentation
+	}
+	if len(delim) == 0 {
+		delim = newline
+	}
+
+	for {
+		if n, ok := rb.readBuffered(p, delim, last); ok {
+			return n, nil
+		}
+		if rb.err != nil {
+			// Return the exact error that the underlying io.Reader
+			// provided to this, each time this method is invoked.
+			return 0, rb.err
+		}
+		if err := rb.fill(r); err != nil {
+			return 0, err
+		}
+	}
+}
+
+// writeTo writes data from the buffer to w, reading more data from r
+// as needed, with each Write ending at the final delimiter buffered
+// when last is set, and at the first otherwise, until r returns an
+// error. When delim is empty, lines end with a newline. It returns the
+// number of bytes written and any error encountered other than
+// io.EOF.
+func (rb *readBuffer) writeTo(r io.Reader, w io.Writer, delim []byte, last bool) (int64, error) {
+	if len(delim) == 0 {
+		delim = newline
+	}
+
+	var total int64
+	for {
+		for {
+			pending := rb.buf[rb.off:]
+			var i int
+			if last {
+				i = lastIndexDelimBytes(pending, delim)
+			} else if i = bytes.Index(pending, delim); i >= 0 {
+				i += len(delim) - 1
+			}
+			if i < 0 {
+				if rb.err == nil || len(pending) == 0 {
+					break
+				}
+				// No more data will arrive to complete this line.
+				i = len(pending) - 1
+			}
+
+			nw, err := w.Write(pending[:i+1])
+			if nw < 0 || nw > i+1 {
+				return total, errors.New("invalid write result")
+			}
+			rb.off += nw
+			total += int64(nw)
+			if err == nil && nw < i+1 {
+				err = io.ErrShortWrite
+			}
+			if err != nil {
+				return total, err
+			}
+		}
+
+		if rb.err == io.EOF {
+			return total, nil
+		}
+		if rb.err != nil {
+			return total, rb.err
+		}
+		if err := rb.fill(r); err != nil {
+			return total, err
+		}
+	}
+}
+
+// readBuffered copies lines from the buffer into p, up to and
+// including the final or first delimiter that fits, depending on last.
+// It returns the number of bytes copied, and whether the data copied
+// is suitable to return to the caller.
+func (rb *readBuffer) readBuffered(p []byte, delim []byte, last bool) (int, bool) {
+	pending := rb.buf[rb.off:]
+	if len(pending) == 0 {
+		return 0, false
+	}
+
+	window := pending
+	if len(window) > len(p) {
+		window = window[:len(p)]
+	}
+
+	var i int
+	if last {
+		i = lastIndexDelimBytes(window, delim)
+	} else if i = bytes.Index(window, delim); i >= 0 {
+		i += len(delim) - 1
+	}
+	if i >= 0 {
+		n := copy(p, window[:i+1])
+		rb.off += n
+		return n, true
+	}
+
+	if len(pending) >= len(p) || rb.err != nil {
+		// Either this line is longer than the provided buffer, or no
+		// more data will arrive to complete this line.
+		n := copy(p, window)
+		rb.off += n
+		return n, true
+	}
+
+	return 0, false
+}
+
+// fill reads another chunk of data from r into the buffer.
+func (rb *readBuffer) fill(r io.Reader) error {
+	// Slide remaining bytes to the start of the buffer to make room.
+	if rb.off > 0 {
+		n := copy(rb.buf, rb.buf[rb.off:])
+		rb.buf = rb.buf[:n]
+		rb.off = 0
+	}
+
+	size := rb.readSize
+	if size == 0 {
+		size = minRead
+	}
+	if cap(rb.buf)-len(rb.buf) < size {
+		buf := make([]byte, len(rb.buf), 2*cap(rb.buf)+size)
+		copy(buf, rb.buf)
+		rb.buf = buf
+	}
+
+	m := len(rb.buf)
+	nr, err := r.Read(rb.buf[m:cap(rb.buf)])
+	if nr < 0 || nr > cap(rb.buf)-m {
+		return errors.New("invalid read result")
+	}
+	rb.buf = rb.buf[:m+nr]
+	rb.err = err
+	return nil
+}
@@ -0,0 +1,201 @@
+package gonl
+
+import (
+	"io"
+	"io/ioutil"
+	"strings"
+	"testing"
+)
+
+func TestLineBufferedReader(t *testing.T) {
+	t.Run("empty", func(t *testing.T) {
+		r := &LineBufferedReader{R: &testReader{tuples: []tuple{
+			tuple{"", io.EOF},
+		}}}
+		buf := make([]byte, 64)
+
+		n, err := r.Read(buf)
+		ensureError(t, err, "EOF")
+		ensureBufferLimit(t, buf, n, "")
+
+		// Error is returned again rather than reading from source.
+		n, err = r.Read(buf)
+		ensureError(t, err, "EOF")
+		ensureBufferLimit(t, buf, n, "")
+	})
+
+	t.Run("holds partial line until more data", func(t *testing.T) {
+		r := &LineBufferedReader{R: &testReader{tuples: []tuple{
+			tuple{"line 1\nli", nil},
+			tuple{"ne 2", nil},
+			tuple{"\nline 3", io.EOF},
+		}}}
+		buf := make([]byte, 64)
+
+		n, err := r.Read(buf)
+		ensureErrorNil(t, err)
+		ensureBufferLimit(t, buf, n, "line 1\n")
+
+		n, err = r.Read(buf)
+		ensureErrorNil(t, err)
+		ensureBufferLimit(t, buf, n, "line 2\n")
+
+		n, err = r.Read(buf)
+		ensureErrorNil(t, err)
+		ensureBufferLimit(t, buf, n, "line 3")
+
+		n, err = r.Read(buf)
+		ensureError(t, err, "EOF")
+		ensureBufferLimit(t, buf, n, "")
+	})
+
+	t.Run("multiple lines fit", func(t *testing.T) {
+		r := &LineBufferedReader{R: &testReader{tuples: []tuple{
+			tuple{"line 1\nline 2\nline 3\n", io.EOF},
+		}}}
+		buf := make([]byte, 10)
+
+		n, err := r.Read(buf)
+		ensureErrorNil(t, err)
+		ensureBufferLimit(t, buf, n, "line 1\n")
+
+		buf = make([]byte, 64)
+		n, err = r.Read(buf)
+		ensureErrorNil(t, err)
+		ensureBufferLimit(t, buf, n, "line 2\nline 3\n")
+
+		n, err = r.Read(buf)
+		ensureError(t, err, "EOF")
+		ensureBufferLimit(t, buf, n, "")
+	})
+
+	t.Run("line longer than buffer", func(t *testing.T) {
+		r := &LineBufferedReader{R: &testReader{tuples: []tuple{
+			tuple{"0123456789\n", io.EOF},
+		}}}
+		buf := make([]byte, 4)
+
+		n, err := r.Read(buf)
+		ensureErrorNil(t, err)
+		ensureBufferLimit(t, buf, n, "0123")
+
+		n, err = r.Read(buf)
+		ensureErrorNil(t, err)
+		ensureBufferLimit(t, buf, n, "4567")
+
+		n, err = r.Read(buf)
+		ensureErrorNil(t, err)
+		ensureBufferLimit(t, buf, n, "89\n")
+	})
+
+	t.Run("error during read", func(t *testing.T) {
+		r := &LineBufferedReader{R: &testReader{tuples: []tuple{
+			tuple{"line 1\nline 2", io.ErrUnexpectedEOF},
+		}}}
+		buf := make([]byte, 64)
+
+		n, err := r.Read(buf)
+		ensureErrorNil(t, err)
+		ensureBufferLimit(t, buf, n, "line 1\n")
+
+		n, err = r.Read(buf)
+		ensureErrorNil(t, err)
+		ensureBufferLimit(t, buf, n, "line 2")
+
+		n, err = r.Read(buf)
+		ensureError(t, err, "unexpected EOF")
+		ensureBufferLimit(t, buf, n, "")
+	})
+
+	t.Run("ReadAll", func(t *testing.T) {
+		const input = "line 1\nline 2\nline 3"
+		buf, err := ioutil.ReadAll(&LineBufferedReader{R: strings.NewReader(input)})
+		ensureErrorNil(t, err)
+		if got, want := string(buf), input; got != want {
+			t.Errorf("GOT: %q; WANT: %q", got, want)
+		}
+	})
+}
+
+func TestLineBufferedReaderDelim(t *testing.T) {
+	r := &LineBufferedReader{R: &testReader{tuples: []tuple{
+		tuple{"a\r\nb\r", nil},
+		tuple{"\nc", io.EOF},
+	}}, Delim: crlf}
+	buf := make([]byte, 64)
+
+	for _, want := range []string{"a\r\n", "b\r\n", "c"} {
+		n, err := r.Read(buf)
+		ensureErrorNil(t, err)
+		ensureBufferLimit(t, buf, n, want)
+	}
+}
+
+// recordingWriter records the bytes passed to each Write.
+type recordingWriter struct {
+	writes []string
+}
+
+func (rw *recordingWriter) Write(p []byte) (int, error) {
+	rw.writes = append(rw.writes, string(p))
+	return len(p), nil
+}
+
+func ensureWrites(t *testing.T, got, want []string) {
+	t.Helper()
+	if len(got) != len(want) {
+		t.Fatalf("GOT: %q; WANT: %q", got, want)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("GOT: %q; WANT: %q", got[i], want[i])
+		}
+	}
+}
+
+func TestLineBufferedReaderWriteTo(t *testing.T) {
+	t.Run("writes complete lines", func(t *testing.T) {
+		r := &LineBufferedReader{R: &testReader{tuples: []tuple{
+			tuple{"line 1\nline 2\nli", nil},
+			tuple{"ne 3\nline 4", io.EOF},
+		}}}
+		w := new(recordingWriter)
+
+		n, err := r.WriteTo(w)
+		ensureErrorNil(t, err)
+		if got, want := n, int64(27); got != want {
+			t.Errorf("GOT: %v; WANT: %v", got, want)
+		}
+		ensureWrites(t, w.writes, []string{"line 1\nline 2\n", "line 3\n", "line 4"})
+
+		// Subsequent calls find the source exhausted.
+		n, err = r.WriteTo(w)
+		ensureErrorNil(t, err)
+		if got, want := n, int64(0); got != want {
+			t.Errorf("GOT: %v; WANT: %v", got, want)
+		}
+	})
+
+	t.Run("read error", func(t *testing.T) {
+		r := &LineBufferedReader{R: &testReader{tuples: []tuple{
+			tuple{"line 1\nli", io.ErrUnexpectedEOF},
+		}}}
+		w := new(recordingWriter)
+
+		_, err := r.WriteTo(w)
+		ensureError(t, err, io.ErrUnexpectedEOF.Error())
+		ensureWrites(t, w.writes, []string{"line 1\n", "li"})
+	})
+
+	t.Run("write error", func(t *testing.T) {
+		r := &LineBufferedReader{R: &testReader{tuples: []tuple{
+			tuple{"line 1\n", io.EOF},
+		}}}
+
+		n, err := r.WriteTo(&errOnWrite{})
+		ensureError(t, err, "test write error")
+		if got, want := n, int64(0); got != want {
+			t.Errorf("GOT: %v; WANT: %v", got, want)
+		}
+	})
+}
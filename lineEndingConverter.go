@@ -0,0 +1,179 @@
+package gonl
+
+import (
+	"io"
+)
+
+// LineEnding identifies the byte sequence used to terminate lines.
+type LineEnding int
+
+const (
+	// LF terminates lines with a single line feed byte, as is
+	// common on Unix systems.
+	LF LineEnding = iota
+
+	// CRLF terminates lines with a carriage return byte followed by
+	// a line feed byte, as is common on Windows systems and in
+	// many network protocols.
+	CRLF
+)
+
+// LineEndingConverter is an io.WriteCloser that converts line endings
+// of the data written to it as that data streams to the underlying
+// io.WriteCloser, without buffering the entire stream.
+//
+// When To is LF, each CRLF sequence is converted to LF. A carriage
+// return not followed by a line feed is left alone, unless
+// ConvertLoneCR is set. Because a Write call may end with a carriage
+// return whose line feed arrives in the following Write call, that
+// carriage return is held back until the next byte is known.
+//
+// When To is CRLF, each LF not already preceded by a carriage return
+// is converted to CRLF, so existing CRLF sequences are not expanded
+// again, even when the carriage return and line feed arrive in
+// different Write calls.
+type LineEndingConverter struct {
+	// WC is io.WriteCloser where data is ultimately written.
+	WC io.WriteCloser
+
+	// To is the line ending to produce.
+	To LineEnding
+
+	// ConvertLoneCR, when set and To is LF, causes each carriage
+	// return not followed by a line feed to also be converted to LF,
+	// as used by old Mac OS text files.
+	ConvertLoneCR bool
+
+	buf []byte
+
+	// heldCR is set when converting to LF and the final byte of the
+	// previous Write was a carriage return that has not yet been
+	// written.
+	heldCR bool
+
+	// prevCR is set when converting to CRLF and the final byte of
+	// the previous Write was a carriage return.
+	prevCR bool
+}
+
+// Close writes any held carriage return, then closes the underlying
+// io.WriteCloser.
+func (lc *LineEndingConverter) Close() error {
+	if lc.heldCR {
+		lc.heldCR = false
+		if _, err := lc.WC.Write([]byte{lc.loneCR()}); err != nil {
+			_ = lc.WC.Close()
+			return err
+		}
+	}
+	lc.buf = nil
+	return lc.WC.Close()
+}
+
+// Write converts the line endings of p, then writes the result to
+// the underlying io.WriteCloser with a single Write call. On success
+// it returns len(p). Because converted bytes do not map one to one
+// with the bytes of p, when the underlying io.WriteCloser returns an
+// error, this returns 0 and that error, and the state carried between
+// Write calls is left unchanged, so retrying the same bytes produces
+// the same output.
+func (lc *LineEndingConverter) Write(p []byte) (int, error) {
+	if len(p) == 0 {
+		return 0, nil
+	}
+
+	buf := lc.buf[:0]
+	var held bool
+	if lc.To == CRLF {
+		buf, held = lc.toCRLF(buf, p)
+	} else {
+		buf, held = lc.toLF(buf, p)
+	}
+	lc.buf = buf
+
+	if len(buf) > 0 {
+		nw, err := lc.WC.Write(buf)
+		if err == nil && nw < len(buf) {
+			err = io.ErrShortWrite
+		}
+		if err != nil {
+			return 0, err
+		}
+	}
+	if lc.To == CRLF {
+		lc.prevCR = held
+	} else {
+		lc.heldCR = held
+	}
+	return len(p), nil
+}
+
+// toCRLF appends p to buf, expanding each LF not preceded by a
+// carriage return to CRLF. It also returns whether p ends with a
+// carriage return, which becomes prevCR once buf is written.
+func (lc *LineEndingConverter) toCRLF(buf, p []byte) ([]byte, bool) {
+	prevCR := lc.prevCR
+	for _, b := range p {
+		if b == '\n' && !prevCR {
+			buf = append(buf, '\r')
+		}
+		buf = append(buf, b)
+		prevCR = b == '\r'
+	}
+	return buf, prevCR
+}
+
+// toLF appends p to buf, converting each CRLF sequence to LF, and
+// holding back a final carriage return. It also returns whether a
+// carriage return is held back, which becomes heldCR once buf is
+// written.
+func (lc *LineEndingConverter) toLF(buf, p []byte) ([]byte, bool) {
+	heldCR := lc.heldCR
+	for _, b := range p {
+		if heldCR {
+			heldCR = false
+			if b != '\n' {
+				buf = append(buf, lc.loneCR())
+			}
+		}
+		if b == '\r' {
+			heldCR = true
+			continue
+		}
+		buf = append(buf, b)
+	}
+	return buf, heldCR
+}
+
+// loneCR returns the byte written in place of a carriage return not
+// followed by a line feed when converting to LF.
+func (lc *LineEndingConverter) loneCR() byte {
+	if lc.ConvertLoneCR {
+		return '\n'
+	}
+	return '\r'
+}
+
+// NewNormalizeNewlinesWriter returns a LineEndingConverter that
+// normalizes mixed line endings as data streams to wc, converting
+// each CRLF sequence, and each carriage return not followed by a line
+// feed, to LF. A CRLF sequence split across two Write calls is
+// converted to a single LF.
+func NewNormalizeNewlinesWriter(wc io.WriteCloser) *LineEndingConverter {
+	return &LineEndingConverter{WC: wc, To: LF, ConvertLoneCR: true}
+}
+
+// NewCRLFWriter returns a LineEndingConverter that expands each LF to
+// CRLF as data streams to wc, without expanding existing CRLF
+// sequences again, for producing bodies that comply with protocols
+// such as SMTP and HTTP from sources using Unix line endings. It may
+// be composed in front of a BatchLineWriter:
+//
+//	lw, err := gonl.NewBatchLineWriterDelimBytes(conn, 4096, []byte("\r\n"))
+//	if err != nil {
+//		return err
+//	}
+//	w := gonl.NewCRLFWriter(lw)
+func NewCRLFWriter(wc io.WriteCloser) *LineEndingConverter {
+	return &LineEndingConverter{WC: wc, To: CRLF}
+}
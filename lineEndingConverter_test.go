@@ -0,0 +1,115 @@
+package gonl
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestLineEndingConverter(t *testing.T) {
+	t.Run("to LF", func(t *testing.T) {
+		t.Run("single write", func(t *testing.T) {
+			output := new(testBuffer)
+			lc := &LineEndingConverter{WC: output, To: LF}
+
+			ensureWrite(t, lc, "line 1\r\nline 2\nline\r3\r\n")
+			ensureErrorNil(t, lc.Close())
+			ensureStringer(t, output, "line 1\nline 2\nline\r3\n")
+		})
+		t.Run("pair split across writes", func(t *testing.T) {
+			output := new(testBuffer)
+			lc := &LineEndingConverter{WC: output, To: LF}
+
+			ensureWrite(t, lc, "line 1\r")
+			ensureStringer(t, output, "line 1")
+			ensureWrite(t, lc, "\nline 2\r")
+			ensureWrite(t, lc, "x")
+			ensureStringer(t, output, "line 1\nline 2\rx")
+		})
+		t.Run("final carriage return written on close", func(t *testing.T) {
+			output := new(testBuffer)
+			lc := &LineEndingConverter{WC: output, To: LF}
+
+			ensureWrite(t, lc, "line 1\r")
+			ensureErrorNil(t, lc.Close())
+			ensureStringer(t, output, "line 1\r")
+		})
+	})
+
+	t.Run("normalize newlines", func(t *testing.T) {
+		output := new(testBuffer)
+		lc := NewNormalizeNewlinesWriter(output)
+
+		ensureWrite(t, lc, "line 1\r")
+		ensureWrite(t, lc, "\nline 2\rline 3\r\r\nline 4\n")
+		ensureStringer(t, output, "line 1\nline 2\nline 3\n\nline 4\n")
+		ensureWrite(t, lc, "line 5\r")
+		ensureErrorNil(t, lc.Close())
+		ensureStringer(t, output, "line 1\nline 2\nline 3\n\nline 4\nline 5\n")
+	})
+
+	t.Run("to CRLF", func(t *testing.T) {
+		t.Run("single write", func(t *testing.T) {
+			output := new(testBuffer)
+			lc := &LineEndingConverter{WC: output, To: CRLF}
+
+			ensureWrite(t, lc, "line 1\nline 2\r\n\n")
+			ensureErrorNil(t, lc.Close())
+			ensureStringer(t, output, "line 1\r\nline 2\r\n\r\n")
+		})
+		t.Run("pair split across writes", func(t *testing.T) {
+			output := new(testBuffer)
+			lc := &LineEndingConverter{WC: output, To: CRLF}
+
+			ensureWrite(t, lc, "line 1\r")
+			ensureWrite(t, lc, "\nline 2")
+			ensureWrite(t, lc, "\n")
+			ensureStringer(t, output, "line 1\r\nline 2\r\n")
+		})
+	})
+
+	t.Run("CRLF writer in front of BatchLineWriter", func(t *testing.T) {
+		output := new(testBuffer)
+		lw, err := NewBatchLineWriterDelimBytes(output, 1, crlf)
+		ensureErrorNil(t, err)
+		lc := NewCRLFWriter(lw)
+
+		ensureWrite(t, lc, "line 1\nline 2\r")
+		ensureStringer(t, output, "line 1\r\n")
+		ensureWrite(t, lc, "\nline 3")
+		ensureStringer(t, output, "line 1\r\nline 2\r\n")
+		ensureErrorNil(t, lc.Close())
+		ensureStringer(t, output, "line 1\r\nline 2\r\nline 3")
+	})
+
+	t.Run("write error", func(t *testing.T) {
+		lc := &LineEndingConverter{WC: &errOnWrite{}, To: CRLF}
+		n, err := lc.Write([]byte("line 1\n"))
+		ensureError(t, err, "test write error")
+		if got, want := n, 0; got != want {
+			t.Errorf("GOT: %v; WANT: %v", got, want)
+		}
+	})
+	t.Run("state unchanged by write error", func(t *testing.T) {
+		t.Run("to LF", func(t *testing.T) {
+			output := &flakyWriter{failures: 1, err: errors.New("transient")}
+			lc := &LineEndingConverter{WC: output, To: LF}
+
+			_, err := lc.Write([]byte("x\r"))
+			ensureError(t, err, "transient")
+			ensureWrite(t, lc, "x\r") // retry
+			ensureWrite(t, lc, "\n")
+			ensureErrorNil(t, lc.Close())
+			ensureStringer(t, output, "x\n")
+		})
+		t.Run("to CRLF", func(t *testing.T) {
+			output := &flakyWriter{failures: 1, err: errors.New("transient")}
+			lc := &LineEndingConverter{WC: output, To: CRLF}
+
+			_, err := lc.Write([]byte("\r"))
+			ensureError(t, err, "transient")
+			ensureWrite(t, lc, "\n")
+			ensureErrorNil(t, lc.Close())
+			ensureStringer(t, output, "\r\n")
+		})
+	})
+}
@@ -0,0 +1,63 @@
+package gonl
+
+import (
+	"io"
+	"regexp"
+)
+
+// LineFilterWriter is an io.WriteCloser that writes to the underlying
+// io.WriteCloser only the lines for which Keep returns true, such as
+// when filtering a stream of log lines. Lines split across multiple
+// Write calls are reassembled before being tested. The final line,
+// when not terminated by a newline, is tested and possibly written
+// when the LineFilterWriter is closed.
+//
+// Because dropped lines are nevertheless consumed, Write returns
+// len(p) on success, so callers such as io.Copy do not mistake a
+// dropped line for a short write.
+type LineFilterWriter struct {
+	// WC is io.WriteCloser where data is ultimately written.
+	WC io.WriteCloser
+
+	// Keep is invoked with each line, excluding its terminating
+	// newline, and returns true when the line should be written. The
+	// slice is only valid for the duration of the call.
+	Keep func(line []byte) bool
+
+	la lineAssembler
+}
+
+// Close tests and writes any remaining partial line, then closes the
+// underlying io.WriteCloser.
+func (fw *LineFilterWriter) Close() error {
+	if err := fw.la.close(fw.emit); err != nil {
+		_ = fw.WC.Close()
+		return err
+	}
+	return fw.WC.Close()
+}
+
+// Write writes each line completed by p for which Keep returns true to
+// the underlying io.WriteCloser, returning the number of bytes of p
+// consumed, including the bytes of dropped lines.
+func (fw *LineFilterWriter) Write(p []byte) (int, error) {
+	return fw.la.write(p, fw.emit)
+}
+
+func (fw *LineFilterWriter) emit(line []byte) error {
+	if !fw.Keep(trimNewline(line)) {
+		return nil
+	}
+	_, err := fw.WC.Write(line)
+	return err
+}
+
+// GrepWriter returns a LineFilterWriter that writes to wc only the
+// lines matching re, like the grep command, or only the lines not
+// matching re when invert is set, like `grep -v`. Each line is matched
+// excluding its terminating newline.
+func GrepWriter(wc io.WriteCloser, re *regexp.Regexp, invert bool) *LineFilterWriter {
+	return &LineFilterWriter{WC: wc, Keep: func(line []byte) bool {
+		return re.Match(line) != invert
+	}}
+}
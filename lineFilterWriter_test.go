@@ -0,0 +1,73 @@
+package gonl
+
+import (
+	"bytes"
+	"io"
+	"regexp"
+	"strings"
+	"testing"
+)
+
+func TestLineFilterWriter(t *testing.T) {
+	keepErrors := func(line []byte) bool { return bytes.HasPrefix(line, []byte("ERROR")) }
+
+	t.Run("forwards kept lines", func(t *testing.T) {
+		output := new(testBuffer)
+		fw := &LineFilterWriter{WC: output, Keep: keepErrors}
+
+		ensureWrite(t, fw, "ERROR 1\nINFO 2\nERR")
+		ensureStringer(t, output, "ERROR 1\n")
+		ensureWrite(t, fw, "OR 3\nERROR 4")
+		ensureStringer(t, output, "ERROR 1\nERROR 3\n")
+		ensureErrorNil(t, fw.Close())
+		ensureStringer(t, output, "ERROR 1\nERROR 3\nERROR 4")
+	})
+
+	t.Run("io.Copy accounting", func(t *testing.T) {
+		output := new(testBuffer)
+		fw := &LineFilterWriter{WC: output, Keep: keepErrors}
+
+		const input = "INFO 1\nINFO 2\nERROR 3\n"
+		n, err := io.Copy(fw, strings.NewReader(input))
+		ensureErrorNil(t, err)
+		if got, want := n, int64(len(input)); got != want {
+			t.Errorf("GOT: %v; WANT: %v", got, want)
+		}
+		ensureErrorNil(t, fw.Close())
+		ensureStringer(t, output, "ERROR 3\n")
+	})
+
+	t.Run("write error", func(t *testing.T) {
+		fw := &LineFilterWriter{WC: &errOnWrite{}, Keep: keepErrors}
+		n, err := fw.Write([]byte("INFO\nERROR\n"))
+		ensureError(t, err, "test write error")
+		if got, want := n, 5; got != want {
+			t.Errorf("GOT: %v; WANT: %v", got, want)
+		}
+		ensureError(t, fw.Close(), "test close error")
+	})
+}
+
+func TestGrepWriter(t *testing.T) {
+	re := regexp.MustCompile(`^ERROR|fatal$`)
+
+	t.Run("matching", func(t *testing.T) {
+		output := new(testBuffer)
+		gw := GrepWriter(output, re, false)
+
+		ensureWrite(t, gw, "ERROR 1\nINFO 2\nsomething fa")
+		ensureWrite(t, gw, "tal\nINFO 4")
+		ensureErrorNil(t, gw.Close())
+		ensureStringer(t, output, "ERROR 1\nsomething fatal\n")
+	})
+
+	t.Run("invert", func(t *testing.T) {
+		output := new(testBuffer)
+		gw := GrepWriter(output, re, true)
+
+		ensureWrite(t, gw, "ERROR 1\nINFO 2\nsomething fa")
+		ensureWrite(t, gw, "tal\nINFO 4")
+		ensureErrorNil(t, gw.Close())
+		ensureStringer(t, output, "INFO 2\nINFO 4")
+	})
+}
@@ -0,0 +1,89 @@
+package gonl
+
+import (
+	"bytes"
+	"math/bits"
+)
+
+// LineLengthHistogram returns a histogram of the lengths of the lines
+// written to the BatchLineWriter while its CollectLineLengths field
+// was set. Each key is a bucket, and its value is the number of lines
+// whose length, excluding the terminating newline, falls in that
+// bucket. Bucket 0 counts empty lines, and every other bucket is a
+// power of two, n, counting lines whose length is greater than n/2
+// and less than or equal to n. The final line, when not terminated by
+// a newline, is counted when the BatchLineWriter is closed.
+//
+// The returned map is a copy, and may be modified by the caller.
+func (lw *BatchLineWriter) LineLengthHistogram() map[int]int {
+	lw.mu.Lock()
+	defer lw.mu.Unlock()
+	histogram := make(map[int]int, len(lw.lineLengths))
+	for bucket, count := range lw.lineLengths {
+		histogram[bucket] = count
+	}
+	return histogram
+}
+
+// lineLengthBucket returns the histogram bucket for a line of length
+// n.
+func lineLengthBucket(n int) int {
+	if n == 0 {
+		return 0
+	}
+	return 1 << bits.Len(uint(n-1))
+}
+
+// recordLineLength counts a single line of length n.
+func (lw *BatchLineWriter) recordLineLength(n int) {
+	if lw.lineLengths == nil {
+		lw.lineLengths = make(map[int]int)
+	}
+	lw.lineLengths[lineLengthBucket(n)]++
+}
+
+// recordLineLengths counts the lines completed by p, and remembers the
+// length of any trailing partial line so it may be completed by a
+// subsequent write.
+func (lw *BatchLineWriter) recordLineLengths(p []byte) {
+	if len(lw.delim) > 1 {
+		lw.recordDelimitedLineLengths(p)
+		return
+	}
+	for {
+		index := bytes.IndexByte(p, lw.delim[0])
+		if index == -1 {
+			lw.partialLineLength += len(p)
+			return
+		}
+		lw.recordLineLength(lw.partialLineLength + index)
+		lw.partialLineLength = 0
+		p = p[index+1:]
+	}
+}
+
+// recordDelimitedLineLengths is recordLineLengths for delimiters longer
+// than one byte, which may be split across multiple writes.
+func (lw *BatchLineWriter) recordDelimitedLineLengths(p []byte) {
+	for _, b := range p {
+		lw.partialLineLength++
+		lw.delimMatched = advanceDelim(lw.delim, lw.delimMatched, b)
+		if lw.delimMatched == len(lw.delim) {
+			lw.recordLineLength(lw.partialLineLength - len(lw.delim))
+			lw.partialLineLength = 0
+			lw.delimMatched = 0
+		}
+	}
+}
+
+// advanceDelim returns the length of the longest prefix of delim that
+// ends the data, after b is appended to data whose final matched bytes
+// equal delim[:matched].
+func advanceDelim(delim []byte, matched int, b byte) int {
+	for k := matched + 1; k > 0; k-- {
+		if delim[k-1] == b && bytes.Equal(delim[:k-1], delim[matched-k+1:matched]) {
+			return k
+		}
+	}
+	return 0
+}
@@ -0,0 +1,72 @@
+package gonl
+
+import (
+	"io"
+	"reflect"
+	"testing"
+)
+
+func TestLineLengthBucket(t *testing.T) {
+	cases := map[int]int{0: 0, 1: 1, 2: 2, 3: 4, 4: 4, 5: 8, 8: 8, 9: 16, 1000: 1024}
+	for n, want := range cases {
+		if got := lineLengthBucket(n); got != want {
+			t.Errorf("%d: GOT: %v; WANT: %v", n, got, want)
+		}
+	}
+}
+
+func TestLineLengthHistogram(t *testing.T) {
+	t.Run("disabled", func(t *testing.T) {
+		lw, err := NewBatchLineWriter(new(DiscardCounter), 16)
+		ensureErrorNil(t, err)
+		ensureWrite(t, lw, "line 1\n")
+		if got, want := len(lw.LineLengthHistogram()), 0; got != want {
+			t.Errorf("GOT: %v; WANT: %v", got, want)
+		}
+	})
+
+	t.Run("Write", func(t *testing.T) {
+		lw, err := NewBatchLineWriter(new(DiscardCounter), 16)
+		ensureErrorNil(t, err)
+		lw.CollectLineLengths = true
+
+		ensureWrite(t, lw, "\nab\nabc")
+		ensureWrite(t, lw, "d\nabcdefghi\nabc")
+		ensureErrorNil(t, lw.Close())
+
+		want := map[int]int{0: 1, 2: 1, 4: 2, 16: 1}
+		if got := lw.LineLengthHistogram(); !reflect.DeepEqual(got, want) {
+			t.Errorf("GOT: %v; WANT: %v", got, want)
+		}
+	})
+
+	t.Run("ReadFrom", func(t *testing.T) {
+		lw, err := NewBatchLineWriter(new(DiscardCounter), 16)
+		ensureErrorNil(t, err)
+		lw.CollectLineLengths = true
+
+		_, err = lw.ReadFrom(&testReader{tuples: []tuple{
+			tuple{"abc", nil},
+			tuple{"d\nab\n", io.EOF},
+		}})
+		ensureErrorNil(t, err)
+
+		want := map[int]int{2: 1, 4: 1}
+		if got := lw.LineLengthHistogram(); !reflect.DeepEqual(got, want) {
+			t.Errorf("GOT: %v; WANT: %v", got, want)
+		}
+	})
+}
+
+func TestAdvanceDelim(t *testing.T) {
+	// The delimiter overlaps itself, so a mismatch must not discard
+	// the bytes already matched.
+	delim := []byte("aab")
+	var matched int
+	for _, b := range []byte("aaab") {
+		matched = advanceDelim(delim, matched, b)
+	}
+	if got, want := matched, 3; got != want {
+		t.Errorf("GOT: %v; WANT: %v", got, want)
+	}
+}
@@ -0,0 +1,77 @@
+package gonl
+
+import (
+	"fmt"
+	"io"
+	"strconv"
+)
+
+// LineNumberWriter is an io.WriteCloser that writes each line to the
+// underlying io.WriteCloser preceded by its line number, starting at
+// 1, like `cat -n`. It is line buffered like PerLineWriter, so the
+// number is only written once the line is complete, even when the line
+// arrives across several Write calls.
+//
+//	nw := &gonl.LineNumberWriter{WC: os.Stdout}
+type LineNumberWriter struct {
+	// WC is io.WriteCloser where data is ultimately written.
+	WC io.WriteCloser
+
+	// Format, when not empty, is the fmt format used to print each
+	// line number, which must consume a single integer operand. When
+	// empty, line numbers are right aligned in six columns and
+	// followed by a tab, like `cat -n`, as if Format were "%6d\t".
+	Format string
+
+	lines int
+	pw    PerLineWriter
+	buf   []byte
+}
+
+// Close writes any remaining partial line with its number, then closes
+// the underlying io.WriteCloser.
+func (nw *LineNumberWriter) Close() error {
+	nw.init()
+	nw.buf = nil
+	return nw.pw.Close()
+}
+
+// Lines returns the number of lines written so far, including a final
+// line not terminated by a newline written by Close.
+func (nw *LineNumberWriter) Lines() int { return nw.lines }
+
+// ReadFrom reads data from r until io.EOF or error, writing each
+// completed line with its number. The return value is the number of
+// bytes read from r.
+func (nw *LineNumberWriter) ReadFrom(r io.Reader) (int64, error) {
+	nw.init()
+	return nw.pw.ReadFrom(r)
+}
+
+// Write writes each line completed by p with its number.
+func (nw *LineNumberWriter) Write(p []byte) (int, error) {
+	nw.init()
+	return nw.pw.Write(p)
+}
+
+func (nw *LineNumberWriter) init() {
+	if nw.pw.WC == nil {
+		nw.pw = PerLineWriter{WC: nw.WC, PrefixFunc: nw.prefix}
+	}
+}
+
+// prefix returns the number of the line about to be written.
+func (nw *LineNumberWriter) prefix() []byte {
+	nw.lines++
+	if nw.Format != "" {
+		nw.buf = append(nw.buf[:0], fmt.Sprintf(nw.Format, nw.lines)...)
+		return nw.buf
+	}
+	buf := nw.buf[:0]
+	number := strconv.Itoa(nw.lines)
+	for i := len(number); i < 6; i++ {
+		buf = append(buf, ' ')
+	}
+	nw.buf = append(append(buf, number...), '\t')
+	return nw.buf
+}
@@ -0,0 +1,38 @@
+package gonl
+
+import (
+	"io"
+	"testing"
+)
+
+func TestLineNumberWriter(t *testing.T) {
+	t.Run("default format", func(t *testing.T) {
+		output := new(testBuffer)
+		nw := &LineNumberWriter{WC: output}
+
+		ensureWrite(t, nw, "one\ntw")
+		ensureWrite(t, nw, "o\nthree")
+		ensureStringer(t, output, "     1\tone\n     2\ttwo\n")
+		if got, want := nw.Lines(), 2; got != want {
+			t.Errorf("GOT: %v; WANT: %v", got, want)
+		}
+		ensureErrorNil(t, nw.Close())
+		ensureStringer(t, output, "     1\tone\n     2\ttwo\n     3\tthree")
+		if got, want := nw.Lines(), 3; got != want {
+			t.Errorf("GOT: %v; WANT: %v", got, want)
+		}
+	})
+
+	t.Run("custom format", func(t *testing.T) {
+		output := new(testBuffer)
+		nw := &LineNumberWriter{WC: output, Format: "%03d: "}
+
+		_, err := nw.ReadFrom(&testReader{tuples: []tuple{
+			tuple{"one\n", nil},
+			tuple{"two\n", io.EOF},
+		}})
+		ensureErrorNil(t, err)
+		ensureErrorNil(t, nw.Close())
+		ensureStringer(t, output, "001: one\n002: two\n")
+	})
+}
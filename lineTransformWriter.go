@@ -0,0 +1,74 @@
+package gonl
+
+import (
+	"io"
+	"regexp"
+)
+
+// LineTransformWriter is an io.WriteCloser that applies Transform to
+// each complete line written to it, then writes the result to the
+// underlying io.WriteCloser, so lines may be rewritten in a streaming
+// pipeline without reimplementing line reassembly. Lines split across
+// multiple Write calls are reassembled before being transformed. The
+// final line, when not terminated by a newline, is transformed and
+// written when the LineTransformWriter is closed.
+//
+//	tw := &gonl.LineTransformWriter{WC: os.Stdout, Transform: bytes.ToUpper}
+type LineTransformWriter struct {
+	// WC is io.WriteCloser where data is ultimately written.
+	WC io.WriteCloser
+
+	// Transform is invoked with each line, excluding its terminating
+	// newline, and returns the line to write in its place, to which
+	// the newline is appended again. The slice it is given may refer
+	// to the bytes passed to Write, so Transform must not modify it,
+	// and it is only valid for the duration of the call. Transform
+	// may return it unchanged, or a subslice of it.
+	Transform func(line []byte) []byte
+
+	la lineAssembler
+
+	scratch []byte
+}
+
+// Close transforms and writes any remaining partial line, then closes
+// the underlying io.WriteCloser.
+func (tw *LineTransformWriter) Close() error {
+	err := tw.la.close(tw.emit)
+	tw.scratch = nil
+	if err != nil {
+		_ = tw.WC.Close()
+		return err
+	}
+	return tw.WC.Close()
+}
+
+// Write transforms and writes each line completed by p to the
+// underlying io.WriteCloser, returning the number of bytes of p
+// consumed.
+func (tw *LineTransformWriter) Write(p []byte) (int, error) {
+	return tw.la.write(p, tw.emit)
+}
+
+func (tw *LineTransformWriter) emit(line []byte) error {
+	body := trimNewline(line)
+	out := tw.Transform(body)
+	if len(body) < len(line) {
+		tw.scratch = append(append(tw.scratch[:0], out...), '\n')
+		out = tw.scratch
+	}
+	_, err := tw.WC.Write(out)
+	return err
+}
+
+// ReplaceWriter returns a LineTransformWriter that replaces each match
+// of re in every line written to it with repl, like the substitute
+// command of sed, before writing the line to wc. Inside repl, `$`
+// signs are interpreted as in regexp.Regexp.Expand. Each line is
+// matched excluding its terminating newline, regardless of how many
+// Write calls it spans or how long it is.
+func ReplaceWriter(wc io.WriteCloser, re *regexp.Regexp, repl []byte) *LineTransformWriter {
+	return &LineTransformWriter{WC: wc, Transform: func(line []byte) []byte {
+		return re.ReplaceAll(line, repl)
+	}}
+}
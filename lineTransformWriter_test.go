@@ -0,0 +1,73 @@
+package gonl
+
+import (
+	"bytes"
+	"regexp"
+	"strings"
+	"testing"
+)
+
+func TestLineTransformWriter(t *testing.T) {
+	t.Run("transforms each line", func(t *testing.T) {
+		output := new(testBuffer)
+		tw := &LineTransformWriter{WC: output, Transform: bytes.ToUpper}
+
+		ensureWrite(t, tw, "line 1\nli")
+		ensureStringer(t, output, "LINE 1\n")
+		ensureWrite(t, tw, "ne 2\n\nline 4")
+		ensureStringer(t, output, "LINE 1\nLINE 2\n\n")
+		ensureErrorNil(t, tw.Close())
+		ensureStringer(t, output, "LINE 1\nLINE 2\n\nLINE 4")
+	})
+
+	t.Run("transform changes length", func(t *testing.T) {
+		output := new(testBuffer)
+		tw := &LineTransformWriter{WC: output, Transform: func(line []byte) []byte {
+			return bytes.Repeat(line, 2)
+		}}
+
+		n, err := tw.Write([]byte("ab\ncd\n"))
+		ensureErrorNil(t, err)
+		if got, want := n, 6; got != want {
+			t.Errorf("GOT: %v; WANT: %v", got, want)
+		}
+		ensureErrorNil(t, tw.Close())
+		ensureStringer(t, output, "abab\ncdcd\n")
+	})
+
+	t.Run("write error", func(t *testing.T) {
+		tw := &LineTransformWriter{WC: &errOnWrite{}, Transform: bytes.ToUpper}
+		n, err := tw.Write([]byte("a\n"))
+		ensureError(t, err, "test write error")
+		if got, want := n, 0; got != want {
+			t.Errorf("GOT: %v; WANT: %v", got, want)
+		}
+		ensureError(t, tw.Close(), "test close error")
+	})
+}
+
+func TestReplaceWriter(t *testing.T) {
+	re := regexp.MustCompile(`(\w+)@example\.com`)
+
+	t.Run("replaces with expansion", func(t *testing.T) {
+		output := new(testBuffer)
+		rw := ReplaceWriter(output, re, []byte("$1 at example"))
+
+		ensureWrite(t, rw, "mail alice@exa")
+		ensureWrite(t, rw, "mple.com and bob@example.com\nnone\n")
+		ensureErrorNil(t, rw.Close())
+		ensureStringer(t, output, "mail alice at example and bob at example\nnone\n")
+	})
+
+	t.Run("long line across many writes", func(t *testing.T) {
+		output := new(testBuffer)
+		rw := ReplaceWriter(output, regexp.MustCompile(`x+`), []byte("y"))
+
+		for i := 0; i < 1000; i++ {
+			ensureWrite(t, rw, strings.Repeat("x", 64))
+		}
+		ensureWrite(t, rw, "\n")
+		ensureErrorNil(t, rw.Close())
+		ensureStringer(t, output, "y\n")
+	})
+}
@@ -0,0 +1,160 @@
+package gonl
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"unicode"
+	"unicode/utf8"
+)
+
+// ErrMissingValue is returned by LogfmtWriter.Log when it is passed an
+// odd number of arguments.
+var ErrMissingValue = errors.New("gonl: missing value for key")
+
+// LogfmtWriter serializes records of key/value pairs as logfmt, one
+// record per line, such as `level=info msg="request done" status=200`,
+// batching the lines with a BatchLineWriter before writing them to the
+// underlying io.WriteCloser. Values containing whitespace, quotes, an
+// equals sign, or control characters are quoted, with newlines and
+// other control characters escaped, so each record always occupies
+// exactly one line. Characters of keys that are not permitted in a
+// logfmt key are replaced by underscores.
+//
+// A LogfmtWriter is not safe for concurrent use by multiple goroutines.
+//
+//	fw, err := gonl.NewLogfmtWriter(os.Stderr)
+//	if err != nil {
+//		return err
+//	}
+//	defer fw.Close()
+//	err = fw.Log("level", "info", "msg", "request done", "status", 200)
+type LogfmtWriter struct {
+	lw      *BatchLineWriter
+	scratch []byte
+}
+
+// NewLogfmtWriter returns a new LogfmtWriter that writes to wc through
+// a BatchLineWriter configured by the specified options, as
+// NewBatchLineWriterOptions does.
+func NewLogfmtWriter(wc io.WriteCloser, options ...Option) (*LogfmtWriter, error) {
+	lw, err := NewBatchLineWriterOptions(wc, options...)
+	if err != nil {
+		return nil, err
+	}
+	return &LogfmtWriter{lw: lw}, nil
+}
+
+// Close flushes any buffered records, then closes the underlying
+// io.WriteCloser.
+func (fw *LogfmtWriter) Close() error {
+	fw.scratch = nil
+	return fw.lw.Close()
+}
+
+// Log writes a record of the alternating keys and values in keyvals.
+// Each key is formatted with fmt.Sprint when it is not a string. Each
+// value is formatted by its Error method when it is an error, by its
+// String method when it is a fmt.Stringer, and otherwise with
+// fmt.Sprint, with a nil value written as null. When keyvals has an
+// odd number of elements, Log returns ErrMissingValue and nothing is
+// written.
+func (fw *LogfmtWriter) Log(keyvals ...interface{}) error {
+	if len(keyvals)%2 != 0 {
+		return ErrMissingValue
+	}
+	buf := fw.scratch[:0]
+	for i := 0; i < len(keyvals); i += 2 {
+		buf = appendLogfmtPair(buf, keyvals[i], keyvals[i+1])
+	}
+	return fw.writeRecord(buf)
+}
+
+// LogMap writes a record of the pairs in m, in order of their keys.
+// Values are formatted as they are by Log.
+func (fw *LogfmtWriter) LogMap(m map[string]interface{}) error {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	buf := fw.scratch[:0]
+	for _, key := range keys {
+		buf = appendLogfmtPair(buf, key, m[key])
+	}
+	return fw.writeRecord(buf)
+}
+
+// Stats returns the statistics of the underlying BatchLineWriter.
+func (fw *LogfmtWriter) Stats() Stats { return fw.lw.Stats() }
+
+func (fw *LogfmtWriter) writeRecord(buf []byte) error {
+	buf = append(buf, '\n')
+	fw.scratch = buf
+	_, err := fw.lw.Write(buf)
+	return err
+}
+
+// appendLogfmtPair appends key=value to buf, preceded by a space when
+// buf is not empty.
+func appendLogfmtPair(buf []byte, key, value interface{}) []byte {
+	if len(buf) > 0 {
+		buf = append(buf, ' ')
+	}
+	buf = appendLogfmtKey(buf, logfmtString(key))
+	buf = append(buf, '=')
+	return appendLogfmtValue(buf, logfmtString(value))
+}
+
+// logfmtString returns the text of a key or value.
+func logfmtString(v interface{}) string {
+	switch v := v.(type) {
+	case nil:
+		return "null"
+	case string:
+		return v
+	case error:
+		return v.Error()
+	case fmt.Stringer:
+		return v.String()
+	default:
+		return fmt.Sprint(v)
+	}
+}
+
+// appendLogfmtKey appends key to buf, replacing each character not
+// permitted in a logfmt key with an underscore.
+func appendLogfmtKey(buf []byte, key string) []byte {
+	if key == "" {
+		return append(buf, '_')
+	}
+	for _, r := range key {
+		if needsLogfmtQuote(r) {
+			r = '_'
+		}
+		buf = append(buf, string(r)...)
+	}
+	return buf
+}
+
+// appendLogfmtValue appends value to buf, quoted when necessary.
+func appendLogfmtValue(buf []byte, value string) []byte {
+	if value == "" {
+		return append(buf, `""`...)
+	}
+	for _, r := range value {
+		if needsLogfmtQuote(r) {
+			return strconv.AppendQuote(buf, value)
+		}
+	}
+	return append(buf, value...)
+}
+
+// needsLogfmtQuote returns true when r may not appear in an unquoted
+// logfmt value.
+func needsLogfmtQuote(r rune) bool {
+	return r <= ' ' || r == '=' || r == '"' || r == utf8.RuneError || unicode.IsSpace(r) || unicode.IsControl(r)
+}
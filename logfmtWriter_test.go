@@ -0,0 +1,50 @@
+package gonl
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestLogfmtWriter(t *testing.T) {
+	t.Run("log", func(t *testing.T) {
+		output := new(testBuffer)
+		fw, err := NewLogfmtWriter(output, WithFlushThreshold(256))
+		ensureErrorNil(t, err)
+
+		ensureErrorNil(t, fw.Log("level", "info", "msg", "request done", "status", 200))
+		ensureErrorNil(t, fw.Log("err", errors.New("line 1\nline 2"), "took", 1500*time.Millisecond, "empty", "", "nil", nil))
+		ensureErrorNil(t, fw.Log("bad key=", `a"b`, 7, true))
+		ensureStringer(t, output, "") // batched
+		ensureErrorNil(t, fw.Close())
+		ensureStringer(t, output, "level=info msg=\"request done\" status=200\n"+
+			"err=\"line 1\\nline 2\" took=1.5s empty=\"\" nil=null\n"+
+			"bad_key_=\"a\\\"b\" 7=true\n")
+
+		if got, want := fw.Stats().Lines, int64(3); got != want {
+			t.Errorf("GOT: %v; WANT: %v", got, want)
+		}
+	})
+
+	t.Run("log map", func(t *testing.T) {
+		output := new(testBuffer)
+		fw, err := NewLogfmtWriter(output)
+		ensureErrorNil(t, err)
+
+		ensureErrorNil(t, fw.LogMap(map[string]interface{}{"b": 2, "a": "x y", "c": "ünïcode"}))
+		ensureErrorNil(t, fw.Close())
+		ensureStringer(t, output, "a=\"x y\" b=2 c=ünïcode\n")
+	})
+
+	t.Run("missing value", func(t *testing.T) {
+		output := new(testBuffer)
+		fw, err := NewLogfmtWriter(output)
+		ensureErrorNil(t, err)
+
+		if got, want := fw.Log("key"), ErrMissingValue; got != want {
+			t.Errorf("GOT: %v; WANT: %v", got, want)
+		}
+		ensureErrorNil(t, fw.Close())
+		ensureStringer(t, output, "")
+	})
+}
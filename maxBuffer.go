@@ -0,0 +1,93 @@
+package gonl
+
+import (
+	"bytes"
+	"errors"
+)
+
+// ErrBufferFull is returned by a BatchLineWriter using the
+// OverflowError policy when writing would cause its buffer to exceed
+// MaxBuffer.
+var ErrBufferFull = errors.New("gonl: buffer full")
+
+// OverflowPolicy determines what a BatchLineWriter does when its
+// buffer reaches MaxBuffer and none of its contents may be flushed as
+// completed lines.
+type OverflowPolicy int
+
+const (
+	// OverflowError rejects the bytes that do not fit, returning the
+	// number of bytes accepted along with ErrBufferFull.
+	OverflowError OverflowPolicy = iota
+
+	// OverflowFlush flushes the entire buffer, including the partial
+	// line, which is therefore split across multiple Write calls to
+	// the underlying io.WriteCloser.
+	OverflowFlush
+
+	// OverflowTruncate discards the bytes of the partial line that do
+	// not fit, up to its delimiter, which is kept, so the line is
+	// truncated to the bytes that fit. The discarded bytes are
+	// reported as written. When a delimiter of more than one byte is
+	// split across Write calls while discarding, the bytes of it
+	// written by the first call are discarded too.
+	OverflowTruncate
+)
+
+// writeBounded is write when MaxBuffer is set. It appends p to the
+// buffer in pieces that fit, applying the Overflow policy when the
+// buffer is full.
+func (lw *BatchLineWriter) writeBounded(p []byte) (int, error) {
+	var n int
+	for len(p) > 0 {
+		if lw.truncating {
+			i := bytes.Index(p, lw.delim)
+			if i == -1 {
+				return n + len(p), nil // discard the rest of p
+			}
+			lw.truncating = false
+			// Keep the delimiter even though it exceeds MaxBuffer.
+			end := i + len(lw.delim)
+			if _, err := lw.writeBatch(p[i:end]); err != nil {
+				return n + i, err
+			}
+			n += end
+			p = p[end:]
+			continue
+		}
+
+		room := lw.MaxBuffer - lw.bufferLength()
+		if room <= 0 {
+			if index := lw.releaseIndex(); index >= lw.off {
+				if _, err := lw.flush(lw.bufferLength(), 0, index+1); err != nil {
+					return n, err
+				}
+				continue
+			}
+			switch lw.Overflow {
+			case OverflowFlush:
+				if _, err := lw.flush(lw.bufferLength(), 0, len(lw.buf)); err != nil {
+					return n, err
+				}
+				continue
+			case OverflowTruncate:
+				lw.truncating = true
+				continue
+			default:
+				return n, ErrBufferFull
+			}
+		}
+
+		chunk := p
+		if len(chunk) > room {
+			chunk = chunk[:room]
+		}
+		nw, err := lw.writeBatch(chunk)
+		n += nw
+		if err != nil {
+			return n, err
+		}
+		p = p[len(chunk):]
+	}
+	return n, nil
+}
@@ -0,0 +1,88 @@
+package gonl
+
+import (
+	"io"
+	"testing"
+)
+
+func TestMaxBuffer(t *testing.T) {
+	t.Run("option", func(t *testing.T) {
+		_, err := NewBatchLineWriterOptions(new(testBuffer), WithMaxBuffer(0, OverflowError))
+		ensureError(t, err, "maxBuffer less than or equal to 0")
+	})
+
+	t.Run("flushes completed lines first", func(t *testing.T) {
+		output := new(testBuffer)
+		lw, err := NewBatchLineWriterOptions(output, WithFlushThreshold(64), WithMaxBuffer(8, OverflowError))
+		ensureErrorNil(t, err)
+
+		ensureWrite(t, lw, "line 1\nline 2\n")
+		ensureStringer(t, output, "line 1\n")
+		ensureErrorNil(t, lw.Close())
+		ensureStringer(t, output, "line 1\nline 2\n")
+	})
+
+	t.Run("error", func(t *testing.T) {
+		output := new(testBuffer)
+		lw, err := NewBatchLineWriterOptions(output, WithFlushThreshold(4), WithMaxBuffer(8, OverflowError))
+		ensureErrorNil(t, err)
+
+		n, err := lw.Write([]byte("0123456789\n"))
+		if err != ErrBufferFull {
+			t.Errorf("GOT: %v; WANT: %v", err, ErrBufferFull)
+		}
+		if got, want := n, 8; got != want {
+			t.Errorf("GOT: %v; WANT: %v", got, want)
+		}
+		ensureStringer(t, output, "")
+		ensureErrorNil(t, lw.Close())
+		ensureStringer(t, output, "01234567")
+	})
+
+	t.Run("flush", func(t *testing.T) {
+		output := new(testBuffer)
+		lw, err := NewBatchLineWriterOptions(output, WithFlushThreshold(4), WithMaxBuffer(8, OverflowFlush))
+		ensureErrorNil(t, err)
+
+		ensureWrite(t, lw, "0123456789abcdefghij\nline 2")
+		ensureStringer(t, output, "0123456789abcdefghij\n")
+		ensureErrorNil(t, lw.Close())
+		ensureStringer(t, output, "0123456789abcdefghij\nline 2")
+	})
+
+	t.Run("truncate", func(t *testing.T) {
+		output := new(testBuffer)
+		lw, err := NewBatchLineWriterOptions(output, WithFlushThreshold(4), WithMaxBuffer(8, OverflowTruncate))
+		ensureErrorNil(t, err)
+
+		ensureWrite(t, lw, "0123456789")
+		ensureWrite(t, lw, "abcdef")
+		ensureWrite(t, lw, "ghij\nline 2\n")
+		ensureErrorNil(t, lw.Close())
+		ensureStringer(t, output, "01234567\nline 2\n")
+	})
+
+	t.Run("truncate multi-byte delimiter", func(t *testing.T) {
+		output := new(testBuffer)
+		lw, err := NewBatchLineWriterOptions(output, WithFlushThreshold(4), WithDelimiterBytes(crlf), WithMaxBuffer(4, OverflowTruncate))
+		ensureErrorNil(t, err)
+
+		ensureWrite(t, lw, "0123456789\r\nab\r\n")
+		ensureErrorNil(t, lw.Close())
+		ensureStringer(t, output, "0123\r\nab\r\n")
+	})
+
+	t.Run("ReadFrom", func(t *testing.T) {
+		output := new(testBuffer)
+		lw, err := NewBatchLineWriterOptions(output, WithFlushThreshold(4), WithMaxBuffer(8, OverflowTruncate))
+		ensureErrorNil(t, err)
+
+		_, err = lw.ReadFrom(&testReader{tuples: []tuple{
+			tuple{"0123456789", nil},
+			tuple{"abc\nline 2\n", io.EOF},
+		}})
+		ensureErrorNil(t, err)
+		ensureErrorNil(t, lw.Close())
+		ensureStringer(t, output, "01234567\nline 2\n")
+	})
+}
@@ -0,0 +1,146 @@
+package gonl
+
+import (
+	"bytes"
+	"fmt"
+)
+
+// LineTooLongError is returned by a BatchLineWriter when a line is
+// longer than its MaxLineLength.
+type LineTooLongError struct {
+	// Offset is the offset of the first byte of the line within the
+	// stream of bytes written to the BatchLineWriter.
+	Offset int64
+
+	// Length is the number of bytes of the line written when it was
+	// rejected, which exceeds MaxLineLength, but may be less than the
+	// full length of the line.
+	Length int
+}
+
+func (e *LineTooLongError) Error() string {
+	return fmt.Sprintf("gonl: line at offset %d too long: %d bytes", e.Offset, e.Length)
+}
+
+// writeLimited is write when MaxLineLength is set. It passes the bytes
+// of p belonging to lines not longer than MaxLineLength to
+// writeBuffer, rejecting or truncating longer lines.
+func (lw *BatchLineWriter) writeLimited(p []byte) (int, error) {
+	var n int
+	if m := lw.delimPending; m > 0 {
+		lw.delimPending = 0
+		if k := len(lw.delim) - m; bytes.HasPrefix(p, lw.delim[m:]) {
+			// p completes a delimiter begun by the previous Write.
+			var err error
+			switch {
+			case !lw.discarding:
+				_, err = lw.writeBuffer(p[:k])
+			case lw.TruncateLongLines:
+				_, err = lw.writeBuffer(lw.delim) // its start was discarded
+			}
+			if err != nil {
+				return 0, err
+			}
+			lw.endLine(k)
+			n = k
+		}
+	}
+	if len(lw.delim) > 1 && !bytes.HasSuffix(p, lw.delim) {
+		defer func(p []byte) { lw.delimPending = delimPrefixSuffix(p, lw.delim) }(p)
+	}
+
+	for p = p[n:]; len(p) > 0; {
+		i := bytes.Index(p, lw.delim)
+		end := len(p) // end of the bytes of p belonging to this line
+		if i >= 0 {
+			end = i + len(lw.delim)
+		}
+
+		if lw.discarding {
+			start := end
+			if i >= 0 && lw.TruncateLongLines {
+				start = i // keep the delimiter
+			}
+			if start < end {
+				if _, err := lw.writeBuffer(p[start:end]); err != nil {
+					return n + start, err
+				}
+			}
+			if i >= 0 {
+				lw.endLine(end)
+			} else {
+				lw.written += int64(end)
+			}
+			n += end
+			p = p[end:]
+			continue
+		}
+
+		length := end
+		if i >= 0 {
+			length = i
+		}
+		if lw.lineLength+length <= lw.MaxLineLength {
+			nw, err := lw.writeBuffer(p[:end])
+			n += nw
+			if err != nil {
+				lw.written += int64(nw)
+				lw.lineLength += nw
+				return n, err
+			}
+			if i >= 0 {
+				lw.endLine(end)
+			} else {
+				lw.written += int64(end)
+				lw.lineLength += end
+			}
+			p = p[end:]
+			continue
+		}
+
+		// This line is too long.
+		allowed := lw.MaxLineLength - lw.lineLength
+		lw.discarding = true
+		if lw.TruncateLongLines {
+			nw, err := lw.writeBuffer(p[:allowed])
+			lw.written += int64(nw)
+			lw.lineLength += nw
+			n += nw
+			if err != nil {
+				return n, err
+			}
+			p = p[allowed:]
+			continue
+		}
+		err := &LineTooLongError{Offset: lw.lineOffset, Length: lw.lineLength + length}
+		// Discard the bytes of the line that remain buffered.
+		m := len(lw.buf) - lw.lineLength
+		if m < lw.off {
+			m = lw.off // some were flushed
+		}
+		lw.buf = lw.buf[:m]
+		lw.written += int64(allowed)
+		return n + allowed, err
+	}
+	return n, nil
+}
+
+// endLine records that the bytes of the current line, including its
+// delimiter, end at index end of the bytes being written.
+func (lw *BatchLineWriter) endLine(end int) {
+	lw.written += int64(end)
+	lw.lineOffset = lw.written
+	lw.lineLength = 0
+	lw.discarding = false
+}
+
+// delimPrefixSuffix returns the length of the longest proper prefix of
+// delim with which p ends.
+func delimPrefixSuffix(p, delim []byte) int {
+	for k := len(delim) - 1; k > 0; k-- {
+		if bytes.HasSuffix(p, delim[:k]) {
+			return k
+		}
+	}
+	return 0
+}
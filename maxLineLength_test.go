@@ -0,0 +1,116 @@
+package gonl
+
+import (
+	"errors"
+	"io"
+	"testing"
+)
+
+func ensureLineTooLong(t *testing.T, err error, offset int64, length int) {
+	t.Helper()
+	var lerr *LineTooLongError
+	if !errors.As(err, &lerr) {
+		t.Fatalf("GOT: %v; WANT: %T", err, lerr)
+	}
+	if lerr.Offset != offset || lerr.Length != length {
+		t.Errorf("GOT: %+v; WANT: offset %d; length %d", lerr, offset, length)
+	}
+}
+
+func TestMaxLineLength(t *testing.T) {
+	t.Run("option", func(t *testing.T) {
+		_, err := NewBatchLineWriterOptions(new(testBuffer), WithMaxLineLength(0, false))
+		ensureError(t, err, "maxLineLength less than or equal to 0")
+	})
+
+	t.Run("reject", func(t *testing.T) {
+		output := new(testBuffer)
+		lw, err := NewBatchLineWriterOptions(output, WithFlushThreshold(64), WithMaxLineLength(6, false))
+		ensureErrorNil(t, err)
+
+		ensureWrite(t, lw, "line 1\nlong")
+		n, err := lw.Write([]byte(" line 2\nline 3\n"))
+		ensureLineTooLong(t, err, 7, 11)
+		if got, want := n, 2; got != want {
+			t.Errorf("GOT: %v; WANT: %v", got, want)
+		}
+		ensureWrite(t, lw, "line 2\nline 3\n"[2:])
+		ensureErrorNil(t, lw.Close())
+		ensureStringer(t, output, "line 1\nline 3\n")
+	})
+
+	t.Run("reject across writes", func(t *testing.T) {
+		output := new(testBuffer)
+		lw, err := NewBatchLineWriterOptions(output, WithFlushThreshold(64), WithMaxLineLength(4, false))
+		ensureErrorNil(t, err)
+
+		_, err = lw.Write([]byte("a\n0123456"))
+		ensureLineTooLong(t, err, 2, 7)
+		ensureWrite(t, lw, "789")
+		ensureWrite(t, lw, "\nb\n")
+		ensureErrorNil(t, lw.Close())
+		ensureStringer(t, output, "a\nb\n")
+	})
+
+	t.Run("FlushOnlyOnClose", func(t *testing.T) {
+		output := new(testBuffer)
+		lw, err := NewBatchLineWriterOptions(output, WithFlushOnlyOnClose(), WithMaxLineLength(6, false))
+		ensureErrorNil(t, err)
+
+		ensureWrite(t, lw, "line 1\n")
+		_, err = lw.Write([]byte("line 2 is too long\nline 3\n"))
+		ensureLineTooLong(t, err, 7, 18)
+		ensureWrite(t, lw, " still too long\nline 4")
+		ensureStringer(t, output, "")
+		ensureErrorNil(t, lw.Close())
+		ensureStringer(t, output, "line 1\nline 4")
+	})
+
+	t.Run("FlushOnlyOnClose truncate", func(t *testing.T) {
+		output := new(testBuffer)
+		lw, err := NewBatchLineWriterOptions(output, WithFlushOnlyOnClose(), WithMaxLineLength(6, true))
+		ensureErrorNil(t, err)
+
+		ensureWrite(t, lw, "line 1\nline 2 is too long\nline 3")
+		ensureErrorNil(t, lw.Close())
+		ensureStringer(t, output, "line 1\nline 2\nline 3")
+	})
+
+	t.Run("truncate", func(t *testing.T) {
+		output := new(testBuffer)
+		lw, err := NewBatchLineWriterOptions(output, WithFlushThreshold(64), WithMaxLineLength(4, true))
+		ensureErrorNil(t, err)
+
+		ensureWrite(t, lw, "012")
+		ensureWrite(t, lw, "3456")
+		ensureWrite(t, lw, "789\nab\nabcdef")
+		ensureErrorNil(t, lw.Close())
+		ensureStringer(t, output, "0123\nab\nabcd")
+	})
+
+	t.Run("multi-byte delimiter split across writes", func(t *testing.T) {
+		output := new(testBuffer)
+		lw, err := NewBatchLineWriterOptions(output, WithFlushThreshold(64), WithDelimiterBytes(crlf), WithMaxLineLength(4, true))
+		ensureErrorNil(t, err)
+
+		ensureWrite(t, lw, "123\r")
+		ensureWrite(t, lw, "\nabcd\r")
+		ensureWrite(t, lw, "\n0123456\r")
+		ensureWrite(t, lw, "\nab\r\n")
+		ensureErrorNil(t, lw.Close())
+		ensureStringer(t, output, "123\r\nabcd\r\n0123\r\nab\r\n")
+	})
+
+	t.Run("ReadFrom", func(t *testing.T) {
+		output := new(testBuffer)
+		lw, err := NewBatchLineWriterOptions(output, WithFlushThreshold(64), WithMaxLineLength(4, false))
+		ensureErrorNil(t, err)
+
+		_, err = lw.ReadFrom(&testReader{tuples: []tuple{
+			tuple{"a\nbb\n0123456789\nc\n", io.EOF},
+		}})
+		ensureLineTooLong(t, err, 5, 10)
+		ensureErrorNil(t, lw.Close())
+		ensureStringer(t, output, "a\nbb\n")
+	})
+}
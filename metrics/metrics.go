@@ -0,0 +1,128 @@
+// Package metrics publishes the statistics gathered by the writers of
+// the gonl package through the expvar package, so they appear on the
+// /debug/vars page of a program, keyed by writer name, under the
+// "gonl" variable.
+//
+//	lw, err := gonl.NewBatchLineWriter(conn, 32*1024)
+//	if err != nil {
+//		return err
+//	}
+//	lw.CollectFlushLatency = true
+//	metrics.Publish("upload", lw)
+//	defer metrics.Unpublish("upload")
+//
+// The values are also available from Values, to feed other metrics
+// systems, such as Prometheus, without this package depending on them.
+package metrics
+
+import (
+	"expvar"
+	"sync"
+	"time"
+
+	"github.com/Maxime2/gonl"
+)
+
+// Source is implemented by writers that gather statistics, such as
+// *gonl.BatchLineWriter and *gonl.PerLineWriter.
+type Source interface {
+	Stats() gonl.Stats
+}
+
+// latencySource is implemented by writers that measure the duration of
+// each flush, such as *gonl.BatchLineWriter.
+type latencySource interface {
+	FlushLatency() (p50, p90, p99 time.Duration)
+}
+
+// bufferedSource is implemented by writers that report the number of
+// bytes currently buffered.
+type bufferedSource interface {
+	Buffered() int
+}
+
+// The names of the expvar variable holding the published writers. The
+// fallback is used when another package already published a variable
+// that is not an *expvar.Map under the primary name.
+const (
+	varName         = "gonl"
+	fallbackVarName = "github.com/Maxime2/gonl"
+)
+
+var (
+	writersOnce sync.Once
+	writers     *expvar.Map // published writers, keyed by name
+)
+
+// writersMap returns the expvar map holding the published writers,
+// registering it on first use rather than at package initialization, so
+// that importing this package never panics because of a name collision.
+func writersMap() *expvar.Map {
+	writersOnce.Do(func() {
+		var ok bool
+		if writers, ok = mapVar(varName); !ok {
+			writers, _ = mapVar(fallbackVarName)
+		}
+	})
+	return writers
+}
+
+// mapVar returns the *expvar.Map published under name, publishing a new
+// one when name is not yet in use. It returns false when name is used
+// by a variable of another type.
+func mapVar(name string) (*expvar.Map, bool) {
+	switch v := expvar.Get(name).(type) {
+	case nil:
+		return expvar.NewMap(name), true
+	case *expvar.Map:
+		return v, true
+	default:
+		return nil, false
+	}
+}
+
+// Publish publishes the values returned by Values for src under the
+// specified name within the "gonl" expvar variable, which is created on
+// first use. When that variable already exists, it is reused if it is
+// an *expvar.Map, and otherwise the "github.com/Maxime2/gonl" variable
+// is used instead. The values are computed each time the variable is
+// read. Publishing another writer with the same name replaces the
+// previous one.
+func Publish(name string, src Source) {
+	writersMap().Set(name, expvar.Func(func() interface{} { return Values(src) }))
+}
+
+// Unpublish removes the writer published under the specified name, so
+// that it may be garbage collected once it is no longer used.
+func Unpublish(name string) {
+	writersMap().Delete(name)
+}
+
+// Values returns the current statistics of src, keyed by metric name.
+// The "bytes", "lines", "flushes", "errors", and "max_buffered_bytes"
+// values are always present. When src measures flush latency, the
+// "flush_latency_p50_ns", "flush_latency_p90_ns", and
+// "flush_latency_p99_ns" values hold the percentiles of the most
+// recent flush durations, in nanoseconds. When src reports how much it
+// buffers, the "buffered_bytes" value holds the number of bytes
+// currently buffered.
+func Values(src Source) map[string]int64 {
+	stats := src.Stats()
+	values := map[string]int64{
+		"bytes":              stats.Bytes,
+		"lines":              stats.Lines,
+		"flushes":            stats.Flushes,
+		"errors":             stats.Errors,
+		"max_buffered_bytes": stats.MaxBuffered,
+	}
+	if ls, ok := src.(latencySource); ok {
+		p50, p90, p99 := ls.FlushLatency()
+		values["flush_latency_p50_ns"] = int64(p50)
+		values["flush_latency_p90_ns"] = int64(p90)
+		values["flush_latency_p99_ns"] = int64(p99)
+	}
+	if bs, ok := src.(bufferedSource); ok {
+		values["buffered_bytes"] = int64(bs.Buffered())
+	}
+	return values
+}
@@ -0,0 +1,100 @@
+package metrics
+
+import (
+	"encoding/json"
+	"expvar"
+	"testing"
+
+	"github.com/Maxime2/gonl"
+)
+
+func TestPublish(t *testing.T) {
+	lw, err := gonl.NewBatchLineWriter(new(gonl.DiscardCounter), 8)
+	if err != nil {
+		t.Fatal(err)
+	}
+	lw.CollectFlushLatency = true
+	Publish("TestPublish", lw)
+	defer Unpublish("TestPublish")
+
+	if _, err = lw.Write([]byte("line 1\nline 2\nline 3")); err != nil {
+		t.Fatal(err)
+	}
+	if err = lw.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	var got map[string]map[string]int64
+	if err := json.Unmarshal([]byte(expvar.Get("gonl").String()), &got); err != nil {
+		t.Fatal(err)
+	}
+	values, ok := got["TestPublish"]
+	if !ok {
+		t.Fatalf("GOT: %v; WANT: TestPublish", got)
+	}
+	want := map[string]int64{"bytes": 20, "lines": 2, "flushes": 2, "errors": 0, "max_buffered_bytes": 20}
+	for k, v := range want {
+		if values[k] != v {
+			t.Errorf("%s: GOT: %v; WANT: %v", k, values[k], v)
+		}
+	}
+	for _, k := range []string{"flush_latency_p50_ns", "flush_latency_p90_ns", "flush_latency_p99_ns"} {
+		if _, ok := values[k]; !ok {
+			t.Errorf("GOT: %v; WANT: %s", values, k)
+		}
+	}
+
+	Unpublish("TestPublish")
+	if got := expvar.Get("gonl").(*expvar.Map).Get("TestPublish"); got != nil {
+		t.Errorf("GOT: %v; WANT: nil", got)
+	}
+}
+
+func TestValues(t *testing.T) {
+	lw := gonl.NewPerLineWriter(new(gonl.DiscardCounter))
+	if _, err := lw.Write([]byte("line 1\nline 2\n")); err != nil {
+		t.Fatal(err)
+	}
+
+	values := Values(lw)
+	if got, want := len(values), 5; got != want {
+		t.Errorf("GOT: %v; WANT: %v", got, want)
+	}
+	if got, want := values["lines"], int64(2); got != want {
+		t.Errorf("GOT: %v; WANT: %v", got, want)
+	}
+	if got, want := values["flushes"], int64(2); got != want {
+		t.Errorf("GOT: %v; WANT: %v", got, want)
+	}
+}
+
+func TestValuesBuffered(t *testing.T) {
+	lw, err := gonl.NewBatchLineWriter(new(gonl.DiscardCounter), 64)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err = lw.Write([]byte("line 1\npartial")); err != nil {
+		t.Fatal(err)
+	}
+
+	values := Values(lw)
+	if got, want := values["buffered_bytes"], int64(14); got != want {
+		t.Errorf("GOT: %v; WANT: %v", got, want)
+	}
+}
+
+func TestMapVar(t *testing.T) {
+	published := expvar.NewMap("TestMapVar map")
+	if got, ok := mapVar("TestMapVar map"); !ok || got != published {
+		t.Errorf("GOT: %v, %v; WANT: %v, true", got, ok, published)
+	}
+
+	expvar.NewInt("TestMapVar int")
+	if got, ok := mapVar("TestMapVar int"); ok || got != nil {
+		t.Errorf("GOT: %v, %v; WANT: nil, false", got, ok)
+	}
+
+	if got, ok := mapVar("TestMapVar new"); !ok || expvar.Get("TestMapVar new") != got {
+		t.Errorf("GOT: %v, %v; WANT: published map, true", got, ok)
+	}
+}
@@ -0,0 +1,189 @@
+package gonl
+
+import (
+	"fmt"
+	"io"
+	"strings"
+)
+
+// MultiPolicy determines how a MultiLineWriter handles an error
+// returned by one of its destinations.
+type MultiPolicy int
+
+const (
+	// FailFast stops writing a batch at the first destination that
+	// returns an error, and returns that error. Destinations after it
+	// do not receive the batch, which is reported as not written
+	// following the same rules as for a BatchLineWriter, even though
+	// the destinations before it did receive the batch.
+	FailFast MultiPolicy = iota
+
+	// ContinueAndAggregate writes each batch to every destination,
+	// even after one of them returns an error, and then returns a
+	// *MultiError describing each destination that failed. The batch
+	// is considered written, so it is not written again to the
+	// destinations that succeeded.
+	ContinueAndAggregate
+)
+
+// MultiError is returned by a MultiLineWriter using the
+// ContinueAndAggregate policy when one or more of its destinations
+// return an error.
+type MultiError struct {
+	// Errors has one element per destination, in the order the
+	// destinations were given to NewMultiLineWriter, which is nil for
+	// each destination that succeeded.
+	Errors []error
+}
+
+// Error returns a message listing each destination that failed along
+// with its error.
+func (e *MultiError) Error() string {
+	var msgs []string
+	for i, err := range e.Errors {
+		if err != nil {
+			msgs = append(msgs, fmt.Sprintf("destination %d: %s", i, err))
+		}
+	}
+	return strings.Join(msgs, "; ")
+}
+
+// Unwrap returns the non-nil errors, so errors.Is and errors.As
+// inspect the error of each destination that failed.
+func (e *MultiError) Unwrap() []error {
+	var errs []error
+	for _, err := range e.Errors {
+		if err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errs
+}
+
+// MultiLineWriter is an io.WriteCloser that fans out to multiple
+// destinations, like io.MultiWriter, but buffers its input like
+// BatchLineWriter, and writes each batch of complete lines to every
+// destination. No destination ever receives a torn line, which makes
+// it suitable for mirroring logs to a file and a socket at the same
+// time.
+type MultiLineWriter struct {
+	lw     *BatchLineWriter
+	wcs    []io.WriteCloser
+	policy MultiPolicy
+	err    error // aggregate error of the first failed flush
+}
+
+// NewMultiLineWriter returns a new MultiLineWriter that batches lines
+// using size as its flush threshold, like NewBatchLineWriter does, and
+// writes each batch to every one of wcs, handling errors according to
+// policy.
+func NewMultiLineWriter(size int, policy MultiPolicy, wcs ...io.WriteCloser) (*MultiLineWriter, error) {
+	if len(wcs) == 0 {
+		return nil, fmt.Errorf("cannot create MultiLineWriter without destinations: %d", len(wcs))
+	}
+	mw := &MultiLineWriter{
+		wcs:    append([]io.WriteCloser(nil), wcs...),
+		policy: policy,
+	}
+	lw, err := NewBatchLineWriterFunc(mw.flush, size)
+	if err != nil {
+		return nil, err
+	}
+	mw.lw = lw
+	return mw, nil
+}
+
+// Close flushes any remaining buffered bytes to every destination,
+// regardless of whether they are terminated by a newline, then closes
+// every destination. Using the FailFast policy it returns the first
+// error encountered, and using the ContinueAndAggregate policy it
+// returns a *MultiError describing the destinations that failed to
+// either write or close.
+func (mw *MultiLineWriter) Close() error {
+	err := mw.lw.Close()
+	if err == nil {
+		err = mw.takeError()
+	}
+
+	if mw.policy == FailFast {
+		for _, wc := range mw.wcs {
+			if cerr := wc.Close(); err == nil {
+				err = cerr
+			}
+		}
+		return err
+	}
+
+	errs := make([]error, len(mw.wcs))
+	if merr, ok := err.(*MultiError); ok {
+		copy(errs, merr.Errors)
+	}
+	var failed bool
+	for i, wc := range mw.wcs {
+		if cerr := wc.Close(); errs[i] == nil {
+			errs[i] = cerr
+		}
+		if errs[i] != nil {
+			failed = true
+		}
+	}
+	if !failed {
+		return nil
+	}
+	return &MultiError{Errors: errs}
+}
+
+// ReadFrom reads data from r until io.EOF or error, writing each batch
+// of complete lines to every destination. The return value is the
+// number of bytes read from r.
+func (mw *MultiLineWriter) ReadFrom(r io.Reader) (int64, error) {
+	n, err := mw.lw.ReadFrom(r)
+	if err == nil {
+		err = mw.takeError()
+	}
+	return n, err
+}
+
+// Write buffers p, writing each batch of complete lines to every
+// destination once the flush threshold is reached.
+func (mw *MultiLineWriter) Write(p []byte) (int, error) {
+	n, err := mw.lw.Write(p)
+	if err == nil {
+		err = mw.takeError()
+	}
+	return n, err
+}
+
+func (mw *MultiLineWriter) flush(data []byte) error {
+	var errs []error
+	for i, wc := range mw.wcs {
+		nw, err := wc.Write(data)
+		if err == nil && nw < len(data) {
+			err = io.ErrShortWrite
+		}
+		if err == nil {
+			continue
+		}
+		if mw.policy == FailFast {
+			return err
+		}
+		if errs == nil {
+			errs = make([]error, len(mw.wcs))
+		}
+		errs[i] = err
+	}
+	if errs != nil && mw.err == nil {
+		// Report the batch as written to the BatchLineWriter, so it
+		// is not written again to the destinations that succeeded.
+		mw.err = &MultiError{Errors: errs}
+	}
+	return nil
+}
+
+// takeError returns and clears the aggregate error of the first flush
+// that failed since it was last called.
+func (mw *MultiLineWriter) takeError() error {
+	err := mw.err
+	mw.err = nil
+	return err
+}
@@ -0,0 +1,75 @@
+package gonl
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestMultiLineWriter(t *testing.T) {
+	t.Run("requires destinations", func(t *testing.T) {
+		_, err := NewMultiLineWriter(16, FailFast)
+		ensureError(t, err, "without destinations")
+	})
+
+	t.Run("writes batches to every destination", func(t *testing.T) {
+		first := new(testBuffer)
+		second := new(testBuffer)
+		mw, err := NewMultiLineWriter(16, FailFast, first, second)
+		ensureErrorNil(t, err)
+
+		ensureWrite(t, mw, "line 1\nli")
+		ensureStringer(t, first, "")
+		ensureWrite(t, mw, "ne 2\nline 3")
+		ensureStringer(t, first, "line 1\nline 2\n")
+		ensureStringer(t, second, "line 1\nline 2\n")
+
+		ensureErrorNil(t, mw.Close())
+		ensureStringer(t, first, "line 1\nline 2\nline 3")
+		ensureStringer(t, second, "line 1\nline 2\nline 3")
+	})
+
+	t.Run("fail fast", func(t *testing.T) {
+		first := new(testBuffer)
+		third := new(testBuffer)
+		mw, err := NewMultiLineWriter(4, FailFast, first, &errOnWrite{}, third)
+		ensureErrorNil(t, err)
+
+		_, err = mw.Write([]byte("line 1\n"))
+		ensureError(t, err, "test write error")
+		ensureStringer(t, first, "line 1\n")
+		ensureStringer(t, third, "")
+
+		ensureError(t, mw.Close(), "test close error")
+	})
+
+	t.Run("continue and aggregate", func(t *testing.T) {
+		first := new(testBuffer)
+		third := new(testBuffer)
+		mw, err := NewMultiLineWriter(4, ContinueAndAggregate, first, &errOnWrite{}, third)
+		ensureErrorNil(t, err)
+
+		n, err := mw.Write([]byte("line 1\n"))
+		ensureError(t, err, "destination 1: test write error")
+		if got, want := n, 7; got != want {
+			t.Errorf("GOT: %v; WANT: %v", got, want)
+		}
+		var merr *MultiError
+		if !errors.As(err, &merr) {
+			t.Fatalf("GOT: %T; WANT: %T", err, merr)
+		}
+		if merr.Errors[0] != nil || merr.Errors[1] == nil || merr.Errors[2] != nil {
+			t.Errorf("GOT: %v; WANT: only destination 1 to fail", merr.Errors)
+		}
+		if !errors.Is(err, errWrite{}) {
+			t.Errorf("GOT: %v; WANT: %v", err, errWrite{})
+		}
+		ensureStringer(t, first, "line 1\n")
+		ensureStringer(t, third, "line 1\n")
+
+		ensureWrite(t, mw, "line 2")
+		err = mw.Close()
+		ensureError(t, err, "destination 1: test write error")
+		ensureStringer(t, first, "line 1\nline 2")
+		ensureStringer(t, third, "line 1\nline 2")
+	})
+}
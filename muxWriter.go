@@ -0,0 +1,77 @@
+package gonl
+
+import (
+	"bytes"
+	"io"
+)
+
+// MuxWriter combines the lines written to multiple io.WriteCloser
+// instances onto a single shared io.WriteCloser, such as a
+// BatchLineWriter, stamping each line with the tag of the writer it was
+// written to, followed by a '|'. Lines from different writers are
+// interleaved only at line boundaries, never in the middle of a line.
+// This is useful for combining stdout and stderr of subprocesses onto
+// one stream, which a DemuxWriter can later split apart again.
+//
+//	mw := gonl.NewMuxWriter(lw)
+//	cmd.Stdout = mw.Writer("stdout")
+//	cmd.Stderr = mw.Writer("stderr")
+type MuxWriter struct {
+	sw *SyncLineWriter
+}
+
+// NewMuxWriter returns a new MuxWriter that writes tagged lines to wc.
+func NewMuxWriter(wc io.WriteCloser) *MuxWriter {
+	return &MuxWriter{sw: NewSyncLineWriter(wc)}
+}
+
+// Close writes the partial line held by each writer not yet closed,
+// terminated by a newline, then closes the underlying io.WriteCloser.
+// After Close, writes to any of its writers return ErrClosed.
+func (mw *MuxWriter) Close() error { return mw.sw.Close() }
+
+// Writer returns a new io.WriteCloser that stamps each line written to
+// it with tag before writing it to the MuxWriter. Each writer must be
+// used by a single goroutine at a time, but different writers may be
+// used concurrently. Closing the writer writes any partial line it
+// holds, terminated by a newline, but does not close the MuxWriter.
+func (mw *MuxWriter) Writer(tag string) io.WriteCloser {
+	return &muxWriter{h: mw.sw.Handle(), tag: append([]byte(tag), '|')}
+}
+
+// muxWriter is the io.WriteCloser returned by MuxWriter.Writer.
+type muxWriter struct {
+	h       *SyncLineWriterHandle
+	tag     []byte // tag followed by separator
+	midLine bool   // true when the previous write ended mid line
+	scratch []byte
+}
+
+func (w *muxWriter) Close() error { return w.h.Close() }
+
+// Write inserts the tag at the start of each line of p, then writes the
+// result to the handle, which holds back any trailing partial line.
+// Like the handle, it returns either len(p), or 0 along with an error.
+func (w *muxWriter) Write(p []byte) (int, error) {
+	tagged := w.scratch[:0]
+	midLine := w.midLine
+	for rest := p; len(rest) > 0; {
+		if !midLine {
+			tagged = append(tagged, w.tag...)
+		}
+		line := rest
+		if i := bytes.IndexByte(rest, '\n'); i >= 0 {
+			line = rest[:i+1]
+		}
+		tagged = append(tagged, line...)
+		midLine = line[len(line)-1] != '\n'
+		rest = rest[len(line):]
+	}
+	w.scratch = tagged
+
+	if _, err := w.h.Write(tagged); err != nil {
+		return 0, err
+	}
+	w.midLine = midLine
+	return len(p), nil
+}
@@ -0,0 +1,59 @@
+package gonl
+
+import (
+	"io"
+	"strings"
+	"sync"
+	"testing"
+)
+
+func TestMuxWriter(t *testing.T) {
+	t.Run("tags lines", func(t *testing.T) {
+		output := new(testBuffer)
+		mw := NewMuxWriter(output)
+		stdout := mw.Writer("stdout")
+		stderr := mw.Writer("stderr")
+
+		ensureWrite(t, stdout, "line 1\nli")
+		ensureWrite(t, stderr, "oops\n")
+		ensureWrite(t, stdout, "ne 2\nline 3")
+		ensureStringer(t, output, "stdout|line 1\nstderr|oops\nstdout|line 2\n")
+
+		ensureErrorNil(t, mw.Close())
+		ensureStringer(t, output, "stdout|line 1\nstderr|oops\nstdout|line 2\nstdout|line 3\n")
+
+		_, err := stderr.Write([]byte("late\n"))
+		ensureError(t, err, ErrClosed.Error())
+	})
+
+	t.Run("round trip through DemuxWriter", func(t *testing.T) {
+		stdout := new(testBuffer)
+		stderr := new(testBuffer)
+		dw := &DemuxWriter{Routes: map[string]io.WriteCloser{"stdout": stdout, "stderr": stderr}}
+		lw, err := NewBatchLineWriter(dw, 64)
+		ensureErrorNil(t, err)
+		mw := NewMuxWriter(lw)
+
+		var wg sync.WaitGroup
+		for _, tag := range []string{"stdout", "stderr"} {
+			wg.Add(1)
+			go func(tag string, w io.WriteCloser) {
+				defer wg.Done()
+				for i := 0; i < 100; i++ {
+					if _, err := io.WriteString(w, tag); err != nil {
+						t.Error(err)
+					}
+					if _, err := io.WriteString(w, " line\n"); err != nil {
+						t.Error(err)
+					}
+				}
+				ensureErrorNil(t, w.Close())
+			}(tag, mw.Writer(tag))
+		}
+		wg.Wait()
+		ensureErrorNil(t, mw.Close())
+
+		ensureStringer(t, stdout, strings.Repeat("stdout line\n", 100))
+		ensureStringer(t, stderr, strings.Repeat("stderr line\n", 100))
+	})
+}
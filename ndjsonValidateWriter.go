@@ -0,0 +1,72 @@
+package gonl
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+)
+
+// NDJSONValidateWriter is an io.WriteCloser that checks that each
+// complete line written to it is a standalone JSON document before
+// writing it to the underlying io.WriteCloser, counting the lines that
+// are not, so an ingest stream may be validated as it passes through
+// rather than in a second pass. Blank lines are ignored, as they are by
+// JSONLReader, and written unchanged. Lines split across multiple Write
+// calls are reassembled before being checked. The final line, when not
+// terminated by a newline, is checked and written when the
+// NDJSONValidateWriter is closed.
+//
+// Because rejected lines are nevertheless consumed, Write returns
+// len(p) on success.
+//
+//	vw := &gonl.NDJSONValidateWriter{WC: f, Reject: true}
+type NDJSONValidateWriter struct {
+	// WC is io.WriteCloser where data is ultimately written.
+	WC io.WriteCloser
+
+	// Reject, when set, causes lines that are not valid JSON to be
+	// dropped rather than written.
+	Reject bool
+
+	la lineAssembler
+
+	lines   int64
+	invalid int64
+}
+
+// Close checks and writes any remaining partial line, then closes the
+// underlying io.WriteCloser.
+func (vw *NDJSONValidateWriter) Close() error {
+	if err := vw.la.close(vw.emit); err != nil {
+		_ = vw.WC.Close()
+		return err
+	}
+	return vw.WC.Close()
+}
+
+// Invalid returns the number of lines that were not valid JSON.
+func (vw *NDJSONValidateWriter) Invalid() int64 { return vw.invalid }
+
+// Lines returns the number of lines checked, excluding blank lines.
+func (vw *NDJSONValidateWriter) Lines() int64 { return vw.lines }
+
+// Write checks and writes each line completed by p to the underlying
+// io.WriteCloser, returning the number of bytes of p consumed,
+// including the bytes of rejected lines.
+func (vw *NDJSONValidateWriter) Write(p []byte) (int, error) {
+	return vw.la.write(p, vw.emit)
+}
+
+func (vw *NDJSONValidateWriter) emit(line []byte) error {
+	if body := bytes.TrimSpace(line); len(body) > 0 {
+		vw.lines++
+		if !json.Valid(body) {
+			vw.invalid++
+			if vw.Reject {
+				return nil
+			}
+		}
+	}
+	_, err := vw.WC.Write(line)
+	return err
+}
@@ -0,0 +1,44 @@
+package gonl
+
+import (
+	"testing"
+)
+
+func TestNDJSONValidateWriter(t *testing.T) {
+	const input = "{\"a\":1}\n{\"b\":\n\n[1,2]\r\n{oops}\n\"tail\""
+
+	t.Run("counts invalid lines", func(t *testing.T) {
+		output := new(testBuffer)
+		vw := &NDJSONValidateWriter{WC: output}
+
+		ensureWrite(t, vw, input[:10])
+		ensureWrite(t, vw, input[10:])
+		ensureErrorNil(t, vw.Close())
+		ensureStringer(t, output, input)
+		if got, want := vw.Lines(), int64(5); got != want {
+			t.Errorf("GOT: %v; WANT: %v", got, want)
+		}
+		if got, want := vw.Invalid(), int64(2); got != want {
+			t.Errorf("GOT: %v; WANT: %v", got, want)
+		}
+	})
+
+	t.Run("rejects invalid lines", func(t *testing.T) {
+		output := new(testBuffer)
+		vw := &NDJSONValidateWriter{WC: output, Reject: true}
+
+		ensureWrite(t, vw, input)
+		ensureErrorNil(t, vw.Close())
+		ensureStringer(t, output, "{\"a\":1}\n\n[1,2]\r\n\"tail\"")
+		if got, want := vw.Invalid(), int64(2); got != want {
+			t.Errorf("GOT: %v; WANT: %v", got, want)
+		}
+	})
+
+	t.Run("write error", func(t *testing.T) {
+		vw := &NDJSONValidateWriter{WC: &errOnWrite{}}
+		_, err := vw.Write([]byte("{}\n"))
+		ensureError(t, err, "test write error")
+		ensureError(t, vw.Close(), "test close error")
+	})
+}
@@ -0,0 +1,82 @@
+package gonl
+
+import (
+	"bytes"
+	"io"
+	"net"
+	"time"
+)
+
+// DefaultDialTimeout is the timeout for each attempt to dial made by
+// the writers returned by NewTCPLineWriter and NewUnixLineWriter.
+const DefaultDialTimeout = 10 * time.Second
+
+// NewTCPLineWriter returns a new BatchLineWriter, configured by the
+// specified options as NewBatchLineWriterOptions does, that sends
+// complete lines over a TCP connection to addr. The connection is made
+// by a ReconnectWriter with its default settings, so lines written
+// while disconnected are held until the connection is restored. No
+// connection is attempted until the first flush. To change how
+// ReconnectWriter behaves, create one and pass it to
+// NewBatchLineWriterOptions instead.
+//
+//	lw, err := gonl.NewTCPLineWriter("collector:5140", gonl.WithFlushInterval(time.Second))
+func NewTCPLineWriter(addr string, options ...Option) (*BatchLineWriter, error) {
+	rw := &ReconnectWriter{Dial: func() (io.WriteCloser, error) {
+		return net.DialTimeout("tcp", addr, DefaultDialTimeout)
+	}}
+	return NewBatchLineWriterOptions(rw, options...)
+}
+
+// NewUnixLineWriter returns a new BatchLineWriter, configured by the
+// specified options as NewBatchLineWriterOptions does, that sends
+// complete lines to the Unix domain socket at path, such as a local
+// log collector. Like NewTCPLineWriter, it reconnects using a
+// ReconnectWriter with its default settings.
+//
+// When datagram is false, lines are sent over a stream socket
+// (SOCK_STREAM). When datagram is true, each line is sent as a
+// separate datagram over a datagram socket (SOCK_DGRAM), without its
+// terminating newline, because the datagram boundary delimits the
+// line. Each flushed batch is then sent as one datagram per line, so
+// lines must fit within the datagram size limit of the socket.
+func NewUnixLineWriter(path string, datagram bool, options ...Option) (*BatchLineWriter, error) {
+	network := "unix"
+	if datagram {
+		network = "unixgram"
+	}
+	rw := &ReconnectWriter{Dial: func() (io.WriteCloser, error) {
+		conn, err := net.DialTimeout(network, path, DefaultDialTimeout)
+		if err != nil || !datagram {
+			return conn, err
+		}
+		return datagramWriter{conn}, nil
+	}}
+	return NewBatchLineWriterOptions(rw, options...)
+}
+
+// datagramWriter is an io.WriteCloser that writes each line of each
+// Write as a separate datagram, without its terminating newline.
+type datagramWriter struct {
+	conn net.Conn
+}
+
+func (dw datagramWriter) Close() error { return dw.conn.Close() }
+
+// Write writes each line of p as a datagram, returning the number of
+// bytes of p belonging to the lines sent.
+func (dw datagramWriter) Write(p []byte) (int, error) {
+	var n int
+	for n < len(p) {
+		end, next := len(p), len(p)
+		if i := bytes.IndexByte(p[n:], '\n'); i >= 0 {
+			end = n + i
+			next = end + 1
+		}
+		if _, err := dw.conn.Write(p[n:end]); err != nil {
+			return n, err
+		}
+		n = next
+	}
+	return n, nil
+}
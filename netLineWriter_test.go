@@ -0,0 +1,93 @@
+package gonl
+
+import (
+	"io/ioutil"
+	"net"
+	"path/filepath"
+	"testing"
+)
+
+func TestNewTCPLineWriter(t *testing.T) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Skip(err)
+	}
+	defer ln.Close()
+
+	received := make(chan string, 1)
+	go func() {
+		conn, err := ln.Accept()
+		if err != nil {
+			received <- err.Error()
+			return
+		}
+		buf, _ := ioutil.ReadAll(conn)
+		received <- string(buf)
+	}()
+
+	lw, err := NewTCPLineWriter(ln.Addr().String(), WithFlushThreshold(8))
+	ensureErrorNil(t, err)
+	ensureWrite(t, lw, "line 1\nline 2\nli")
+	ensureWrite(t, lw, "ne 3\n")
+	ensureErrorNil(t, lw.Close())
+
+	if got, want := <-received, "line 1\nline 2\nline 3\n"; got != want {
+		t.Errorf("GOT: %q; WANT: %q", got, want)
+	}
+}
+
+func TestNewUnixLineWriter(t *testing.T) {
+	t.Run("stream", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "sock")
+		ln, err := net.Listen("unix", path)
+		if err != nil {
+			t.Skip(err)
+		}
+		defer ln.Close()
+
+		received := make(chan string, 1)
+		go func() {
+			conn, err := ln.Accept()
+			if err != nil {
+				received <- err.Error()
+				return
+			}
+			buf, _ := ioutil.ReadAll(conn)
+			received <- string(buf)
+		}()
+
+		lw, err := NewUnixLineWriter(path, false, WithFlushThreshold(8))
+		ensureErrorNil(t, err)
+		ensureWrite(t, lw, "line 1\nline 2\nli")
+		ensureWrite(t, lw, "ne 3\n")
+		ensureErrorNil(t, lw.Close())
+
+		if got, want := <-received, "line 1\nline 2\nline 3\n"; got != want {
+			t.Errorf("GOT: %q; WANT: %q", got, want)
+		}
+	})
+
+	t.Run("datagram", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "sock")
+		conn, err := net.ListenUnixgram("unixgram", &net.UnixAddr{Name: path, Net: "unixgram"})
+		if err != nil {
+			t.Skip(err)
+		}
+		defer conn.Close()
+
+		lw, err := NewUnixLineWriter(path, true, WithFlushThreshold(8))
+		ensureErrorNil(t, err)
+		ensureWrite(t, lw, "line 1\nline 2\nli")
+		ensureWrite(t, lw, "ne 3\n")
+		ensureErrorNil(t, lw.Close())
+
+		buf := make([]byte, 64)
+		for _, want := range []string{"line 1", "line 2", "line 3"} {
+			n, err := conn.Read(buf)
+			ensureErrorNil(t, err)
+			if got := string(buf[:n]); got != want {
+				t.Errorf("GOT: %q; WANT: %q", got, want)
+			}
+		}
+	})
+}
@@ -0,0 +1,56 @@
+package gonl
+
+import "io"
+
+// NopCloseWriter returns a structure that implements io.WriteCloser,
+// but provides a no-op Close method. It is useful when you have an
+// io.Writer that needs to be passed to a method that requires an
+// io.WriteCloser, or an io.WriteCloser that must not be closed, such
+// as os.Stdout. It is the counter-part to io.NopCloser, but for
+// io.Writer.
+//
+//	wc := gonl.NopCloseWriter(w)
+//	_ = wc.Close() // does nothing; always returns nil
+func NopCloseWriter(w io.Writer) io.WriteCloser {
+	return nopCloseWriter{w}
+}
+
+func (nopCloseWriter) Close() error { return nil }
+
+type nopCloseWriter struct{ io.Writer }
+
+// underlying returns the io.Writer wrapped by NopCloseWriter, or w
+// itself, so that optional interfaces, such as those providing Sync or
+// SetWriteDeadline, are detected on the writer that implements them.
+func underlying(w io.Writer) io.Writer {
+	if nw, ok := w.(nopCloseWriter); ok {
+		return nw.Writer
+	}
+	return w
+}
+
+// writeCloser returns w when it is an io.WriteCloser, and otherwise
+// wraps it with NopCloseWriter.
+func writeCloser(w io.Writer) io.WriteCloser {
+	if wc, ok := w.(io.WriteCloser); ok {
+		return wc
+	}
+	return NopCloseWriter(w)
+}
+
+// NewBatchLineWriterTo returns a new BatchLineWriter with the specified
+// flush threshold, as NewBatchLineWriter does, that writes to w. When w
+// is also an io.Closer, Close closes it; otherwise Close only flushes
+// the remaining data to w. To avoid closing an io.WriteCloser such as
+// os.Stdout, wrap it with NopCloseWriter.
+func NewBatchLineWriterTo(w io.Writer, flushThreshold int) (*BatchLineWriter, error) {
+	return NewBatchLineWriter(writeCloser(w), flushThreshold)
+}
+
+// NewPerLineWriterTo returns a new PerLineWriter, as NewPerLineWriter
+// does, that writes to w. When w is also an io.Closer, Close closes it;
+// otherwise Close only writes the remaining data to w. To avoid closing
+// an io.WriteCloser such as os.Stdout, wrap it with NopCloseWriter.
+func NewPerLineWriterTo(w io.Writer) *PerLineWriter {
+	return NewPerLineWriter(writeCloser(w))
+}
@@ -0,0 +1,99 @@
+package gonl
+
+import (
+	"bytes"
+	"net"
+	"testing"
+	"time"
+)
+
+func TestNopCloseWriter(t *testing.T) {
+	wc := NopCloseWriter(&errOnClose{})
+	ensureErrorNil(t, wc.Close())
+}
+
+func TestNewBatchLineWriterTo(t *testing.T) {
+	t.Run("writer", func(t *testing.T) {
+		bb := new(bytes.Buffer)
+		lw, err := NewBatchLineWriterTo(bb, 8)
+		ensureErrorNil(t, err)
+
+		ensureWrite(t, lw, "line 1\npartial")
+		ensureErrorNil(t, lw.Close())
+		if got, want := bb.String(), "line 1\npartial"; got != want {
+			t.Errorf("GOT: %q; WANT: %q", got, want)
+		}
+	})
+
+	t.Run("closer", func(t *testing.T) {
+		lw, err := NewBatchLineWriterTo(&errOnClose{}, 8)
+		ensureErrorNil(t, err)
+		ensureError(t, lw.Close(), "test close error")
+	})
+
+	t.Run("vectored", func(t *testing.T) {
+		output := new(testBuffersWriter)
+		lw, err := NewBatchLineWriter(NopCloseWriter(output), 8)
+		ensureErrorNil(t, err)
+
+		ensureWrite(t, lw, "line ")
+		ensureWrite(t, lw, "1\nline 2\n")
+		ensureErrorNil(t, lw.Close())
+		ensureStringer(t, output, "line 1\nline 2\n")
+		if got, want := output.bufferWrites, 1; got != want {
+			t.Errorf("GOT: %v; WANT: %v", got, want)
+		}
+	})
+}
+
+func TestNopCloseWriterInterfaces(t *testing.T) {
+	t.Run("Sync", func(t *testing.T) {
+		output := new(syncBuffer)
+		lw, err := NewBatchLineWriterOptions(NopCloseWriter(output), WithFlushThreshold(4), WithSyncOnFlush(1))
+		ensureErrorNil(t, err)
+
+		ensureWrite(t, lw, "line 1\n")
+		ensureErrorNil(t, lw.Close())
+		if got, want := output.syncs, 1; got != want {
+			t.Errorf("GOT: %v; WANT: %v", got, want)
+		}
+	})
+
+	t.Run("SetWriteDeadline", func(t *testing.T) {
+		client, server := net.Pipe()
+		defer client.Close()
+		defer server.Close()
+
+		lw, err := NewBatchLineWriterOptions(NopCloseWriter(client), WithFlushThreshold(1), WithWriteTimeout(10*time.Millisecond))
+		ensureErrorNil(t, err)
+
+		_, err = lw.Write([]byte("line 1\n"))
+		ensureError(t, err, ErrWriteTimeout.Error())
+
+		// Only a deadline, rather than the watchdog, lets later writes
+		// succeed.
+		go func() {
+			buf := make([]byte, 64)
+			_, _ = server.Read(buf)
+		}()
+		ensureWrite(t, lw, "line 2\n")
+	})
+}
+
+func TestNewPerLineWriterTo(t *testing.T) {
+	t.Run("writer", func(t *testing.T) {
+		bb := new(bytes.Buffer)
+		lw := NewPerLineWriterTo(bb)
+
+		ensureWrite(t, lw, "line 1\npartial")
+		ensureErrorNil(t, lw.Close())
+		if got, want := bb.String(), "line 1\npartial"; got != want {
+			t.Errorf("GOT: %q; WANT: %q", got, want)
+		}
+	})
+
+	t.Run("closer", func(t *testing.T) {
+		lw := NewPerLineWriterTo(&errOnClose{})
+		ensureError(t, lw.Close(), "test close error")
+	})
+}
@@ -0,0 +1,248 @@
+package gonl
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"time"
+)
+
+// DefaultFlushThreshold is the flush threshold of a BatchLineWriter
+// created by NewBatchLineWriterOptions without WithFlushThreshold. It
+// matches the size of the buffer io.Copy uses by default.
+const DefaultFlushThreshold = 32 * 1024
+
+// Option configures a BatchLineWriter created by
+// NewBatchLineWriterOptions.
+type Option func(lw *BatchLineWriter) error
+
+// NewBatchLineWriterOptions returns a new BatchLineWriter that writes
+// to wc, configured by the specified options, which are applied in
+// order. Without options, it is equivalent to calling
+// NewBatchLineWriter with DefaultFlushThreshold.
+//
+//	lw, err := gonl.NewBatchLineWriterOptions(conn,
+//		gonl.WithFlushThreshold(4096),
+//		gonl.WithDelimiterBytes([]byte("\r\n")),
+//		gonl.WithFlushInterval(100*time.Millisecond),
+//	)
+func NewBatchLineWriterOptions(wc io.WriteCloser, options ...Option) (*BatchLineWriter, error) {
+	lw, err := NewBatchLineWriter(wc, DefaultFlushThreshold)
+	if err != nil {
+		return nil, err
+	}
+	for _, option := range options {
+		if err = option(lw); err != nil {
+			return nil, err
+		}
+	}
+	return lw, nil
+}
+
+// WithFlushThreshold sets the number of buffered bytes at or above
+// which the BatchLineWriter flushes its completed lines.
+func WithFlushThreshold(flushThreshold int) Option {
+	return func(lw *BatchLineWriter) error {
+		if flushThreshold <= 0 {
+			return fmt.Errorf("cannot create BatchLineWriter when flushThreshold less than or equal to 0: %d", flushThreshold)
+		}
+		lw.flushThreshold = flushThreshold
+		return nil
+	}
+}
+
+// WithInitialCapacity preallocates the buffer with the specified
+// capacity, as NewBatchLineWriterCap does, avoiding reallocations
+// early on when the size of batches is predictable.
+func WithInitialCapacity(initialCap int) Option {
+	return func(lw *BatchLineWriter) error {
+		if initialCap < 0 {
+			return fmt.Errorf("cannot create BatchLineWriter when initialCap less than 0: %d", initialCap)
+		}
+		lw.buf = make([]byte, 0, initialCap)
+		return nil
+	}
+}
+
+// WithGrowFunc sets the Grow field.
+func WithGrowFunc(grow GrowFunc) Option {
+	return func(lw *BatchLineWriter) error {
+		lw.Grow = grow
+		return nil
+	}
+}
+
+// WithMaxBuffer sets the MaxBuffer and Overflow fields.
+func WithMaxBuffer(maxBuffer int, overflow OverflowPolicy) Option {
+	return func(lw *BatchLineWriter) error {
+		if maxBuffer <= 0 {
+			return fmt.Errorf("cannot create BatchLineWriter when maxBuffer less than or equal to 0: %d", maxBuffer)
+		}
+		lw.MaxBuffer = maxBuffer
+		lw.Overflow = overflow
+		return nil
+	}
+}
+
+// WithMaxLineLength sets the MaxLineLength and TruncateLongLines
+// fields.
+func WithMaxLineLength(maxLineLength int, truncate bool) Option {
+	return func(lw *BatchLineWriter) error {
+		if maxLineLength <= 0 {
+			return fmt.Errorf("cannot create BatchLineWriter when maxLineLength less than or equal to 0: %d", maxLineLength)
+		}
+		lw.MaxLineLength = maxLineLength
+		lw.TruncateLongLines = truncate
+		return nil
+	}
+}
+
+// WithDelimiter sets the byte terminating each line, as
+// NewBatchLineWriterDelim does.
+func WithDelimiter(delim byte) Option {
+	return WithDelimiterBytes([]byte{delim})
+}
+
+// WithDelimiterBytes sets the sequence of bytes terminating each line,
+// as NewBatchLineWriterDelimBytes does.
+func WithDelimiterBytes(delim []byte) Option {
+	return func(lw *BatchLineWriter) error {
+		if len(delim) == 0 {
+			return errors.New("cannot create BatchLineWriter when delimiter is empty")
+		}
+		lw.delim = append([]byte(nil), delim...)
+		return nil
+	}
+}
+
+// WithFlushInterval sets the FlushInterval field.
+func WithFlushInterval(interval time.Duration) Option {
+	return func(lw *BatchLineWriter) error {
+		lw.FlushInterval = interval
+		return nil
+	}
+}
+
+// WithOnError sets the OnError callback.
+func WithOnError(callback func(err error)) Option {
+	return func(lw *BatchLineWriter) error {
+		lw.OnError = callback
+		return nil
+	}
+}
+
+// WithErrorHandler sets the ErrorHandler callback.
+func WithErrorHandler(handler func(err error) error) Option {
+	return func(lw *BatchLineWriter) error {
+		lw.ErrorHandler = handler
+		return nil
+	}
+}
+
+// WithOnFlush sets the OnFlush callback.
+func WithOnFlush(callback func(bytes, lines int)) Option {
+	return func(lw *BatchLineWriter) error {
+		lw.OnFlush = callback
+		return nil
+	}
+}
+
+// WithFlushTracer sets the FlushTracer field.
+func WithFlushTracer(tracer FlushTracer) Option {
+	return func(lw *BatchLineWriter) error {
+		lw.FlushTracer = tracer
+		return nil
+	}
+}
+
+// WithDiscardOnCancel sets the DiscardOnCancel field.
+func WithDiscardOnCancel() Option {
+	return func(lw *BatchLineWriter) error {
+		lw.DiscardOnCancel = true
+		return nil
+	}
+}
+
+// WithWriteTimeout sets the WriteTimeout field.
+func WithWriteTimeout(timeout time.Duration) Option {
+	return func(lw *BatchLineWriter) error {
+		lw.WriteTimeout = timeout
+		return nil
+	}
+}
+
+// WithRetry sets the Retry field to a copy of policy.
+func WithRetry(policy RetryPolicy) Option {
+	return func(lw *BatchLineWriter) error {
+		if policy.MaxRetries < 0 {
+			return fmt.Errorf("cannot create BatchLineWriter when max retries less than 0: %d", policy.MaxRetries)
+		}
+		lw.Retry = &policy
+		return nil
+	}
+}
+
+// WithSyncOnFlush sets the SyncEvery field, so the underlying
+// io.WriteCloser is synced after every specified number of flushes.
+func WithSyncOnFlush(every int) Option {
+	return func(lw *BatchLineWriter) error {
+		if every <= 0 {
+			return fmt.Errorf("cannot create BatchLineWriter when sync every less than or equal to 0: %d", every)
+		}
+		lw.SyncEvery = every
+		return nil
+	}
+}
+
+// WithFlushOnlyOnClose sets the FlushOnlyOnClose field.
+func WithFlushOnlyOnClose() Option {
+	return func(lw *BatchLineWriter) error {
+		lw.FlushOnlyOnClose = true
+		return nil
+	}
+}
+
+// WithFlushPartialWhenFull sets the FlushPartialWhenFull field.
+func WithFlushPartialWhenFull() Option {
+	return func(lw *BatchLineWriter) error {
+		lw.FlushPartialWhenFull = true
+		return nil
+	}
+}
+
+// WithHoldLines sets the HoldLines field.
+func WithHoldLines(lines int) Option {
+	return func(lw *BatchLineWriter) error {
+		if lines < 0 {
+			return fmt.Errorf("cannot create BatchLineWriter when hold lines less than 0: %d", lines)
+		}
+		lw.HoldLines = lines
+		return nil
+	}
+}
+
+// WithJoinContinuations sets the JoinContinuations field, using the
+// specified continuation byte, or a backslash when it is zero.
+func WithJoinContinuations(continuation byte) Option {
+	return func(lw *BatchLineWriter) error {
+		lw.JoinContinuations = true
+		lw.ContinuationByte = continuation
+		return nil
+	}
+}
+
+// WithCollectLineLengths sets the CollectLineLengths field.
+func WithCollectLineLengths() Option {
+	return func(lw *BatchLineWriter) error {
+		lw.CollectLineLengths = true
+		return nil
+	}
+}
+
+// WithCollectFlushLatency sets the CollectFlushLatency field.
+func WithCollectFlushLatency() Option {
+	return func(lw *BatchLineWriter) error {
+		lw.CollectFlushLatency = true
+		return nil
+	}
+}
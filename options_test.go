@@ -0,0 +1,112 @@
+package gonl
+
+import (
+	"testing"
+	"time"
+)
+
+func TestNewBatchLineWriterOptions(t *testing.T) {
+	t.Run("defaults", func(t *testing.T) {
+		lw, err := NewBatchLineWriterOptions(new(DiscardCounter))
+		ensureErrorNil(t, err)
+		if got, want := lw.Size(), DefaultFlushThreshold; got != want {
+			t.Errorf("GOT: %v; WANT: %v", got, want)
+		}
+		if got, want := lw.Delimiter(), byte('\n'); got != want {
+			t.Errorf("GOT: %q; WANT: %q", got, want)
+		}
+	})
+
+	t.Run("options", func(t *testing.T) {
+		onError := func(error) {}
+		lw, err := NewBatchLineWriterOptions(new(DiscardCounter),
+			WithFlushThreshold(42),
+			WithDelimiter(';'),
+			WithFlushInterval(time.Second),
+			WithOnError(onError),
+			WithErrorHandler(func(err error) error { return err }),
+			WithOnFlush(func(int, int) {}),
+			WithFlushTracer(new(testTracer)),
+			WithFlushOnlyOnClose(),
+			WithDiscardOnCancel(),
+			WithWriteTimeout(time.Minute),
+			WithSyncOnFlush(2),
+			WithRetry(RetryPolicy{MaxRetries: 4}),
+			WithFlushPartialWhenFull(),
+			WithHoldLines(3),
+			WithJoinContinuations('&'),
+			WithCollectLineLengths(),
+			WithCollectFlushLatency(),
+		)
+		ensureErrorNil(t, err)
+
+		if got, want := lw.Size(), 42; got != want {
+			t.Errorf("GOT: %v; WANT: %v", got, want)
+		}
+		if got, want := lw.Delimiter(), byte(';'); got != want {
+			t.Errorf("GOT: %q; WANT: %q", got, want)
+		}
+		if got, want := lw.FlushInterval, time.Second; got != want {
+			t.Errorf("GOT: %v; WANT: %v", got, want)
+		}
+		if lw.OnError == nil {
+			t.Error("GOT: nil; WANT: OnError")
+		}
+		if lw.ErrorHandler == nil {
+			t.Error("GOT: nil; WANT: ErrorHandler")
+		}
+		if got, want := lw.WriteTimeout, time.Minute; got != want {
+			t.Errorf("GOT: %v; WANT: %v", got, want)
+		}
+		if got, want := lw.SyncEvery, 2; got != want {
+			t.Errorf("GOT: %v; WANT: %v", got, want)
+		}
+		if lw.Retry == nil || lw.Retry.MaxRetries != 4 {
+			t.Errorf("GOT: %+v; WANT: MaxRetries 4", lw.Retry)
+		}
+		if lw.OnFlush == nil {
+			t.Error("GOT: nil; WANT: OnFlush")
+		}
+		if lw.FlushTracer == nil {
+			t.Error("GOT: nil; WANT: FlushTracer")
+		}
+		if !lw.FlushOnlyOnClose || !lw.FlushPartialWhenFull || !lw.JoinContinuations || !lw.CollectLineLengths || !lw.CollectFlushLatency || !lw.DiscardOnCancel {
+			t.Errorf("GOT: %+v; WANT: all options set", lw)
+		}
+		if got, want := lw.HoldLines, 3; got != want {
+			t.Errorf("GOT: %v; WANT: %v", got, want)
+		}
+		if got, want := lw.ContinuationByte, byte('&'); got != want {
+			t.Errorf("GOT: %q; WANT: %q", got, want)
+		}
+	})
+
+	t.Run("delimiter bytes", func(t *testing.T) {
+		output := new(testBuffer)
+		lw, err := NewBatchLineWriterOptions(output, WithFlushThreshold(1), WithDelimiterBytes(crlf))
+		ensureErrorNil(t, err)
+
+		ensureWrite(t, lw, "a\nb\r")
+		ensureStringer(t, output, "")
+		ensureWrite(t, lw, "\n")
+		ensureStringer(t, output, "a\nb\r\n")
+		ensureErrorNil(t, lw.Close())
+	})
+
+	t.Run("invalid", func(t *testing.T) {
+		_, err := NewBatchLineWriterOptions(new(DiscardCounter), WithFlushThreshold(0))
+		ensureError(t, err, "flushThreshold less than or equal to 0")
+
+		_, err = NewBatchLineWriterOptions(new(DiscardCounter), WithDelimiterBytes(nil))
+		ensureError(t, err, "delimiter is empty")
+
+		_, err = NewBatchLineWriterOptions(new(DiscardCounter), WithHoldLines(-1))
+		ensureError(t, err, "hold lines less than 0")
+
+		_, err = NewBatchLineWriterOptions(new(DiscardCounter), WithSyncOnFlush(0))
+		ensureError(t, err, "sync every less than or equal to 0")
+
+		_, err = NewBatchLineWriterOptions(new(DiscardCounter), WithRetry(RetryPolicy{MaxRetries: -1}))
+		ensureError(t, err, "max retries less than 0")
+	})
+}
@@ -0,0 +1,51 @@
+package gonl
+
+import (
+	"io"
+)
+
+// PerLineReader reads from the source io.Reader and returns exactly
+// one newline terminated line from each Read, holding back any
+// remaining data until the following Read. It is the read side analog
+// of PerLineWriter, and is useful when the consumer is a line at a
+// time protocol handler.
+//
+// Unlike bufio.Scanner, there is no limit on the length of a line.
+// When a line is longer than the provided buffer, the buffer is
+// filled with as much of that line as fits, and the remainder of the
+// line is returned by the following Read calls. After the source
+// io.Reader returns an error, the final bytes not terminated by a
+// newline are returned before the error.
+//
+//	r := &gonl.PerLineReader{R: conn}
+//	n, err := r.Read(buf) // buf[:n] is a single line
+type PerLineReader struct {
+	// R is the io.Reader from which data is read.
+	R io.Reader
+
+	// Delim, when not empty, is the sequence of bytes terminating
+	// each line, such as NUL, rather than a newline.
+	Delim []byte
+
+	rb readBuffer
+}
+
+// Read reads a single line into p, ending on a newline boundary unless
+// the line is longer than p or the source io.Reader has returned an
+// error. It returns the number of bytes read (0 <= n <= len(p)) and
+// any error encountered.
+func (r *PerLineReader) Read(p []byte) (int, error) {
+	return r.rb.read(r.R, p, r.Delim, false)
+}
+
+// WriteTo writes data to w until the source io.Reader returns an error,
+// such as io.EOF, with exactly one line in each Write, except for the
+// final bytes not terminated by a newline, which are written by
+// themselves. It returns the number of bytes written, along with any
+// error encountered other than io.EOF.
+//
+// This method is provided to satisfy the io.WriterTo interface, which
+// the io.Copy function uses if available.
+func (r *PerLineReader) WriteTo(w io.Writer) (int64, error) {
+	return r.rb.writeTo(r.R, w, r.Delim, false)
+}
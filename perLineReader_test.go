@@ -0,0 +1,86 @@
+package gonl
+
+import (
+	"io"
+	"testing"
+)
+
+func TestPerLineReader(t *testing.T) {
+	t.Run("empty", func(t *testing.T) {
+		r := &PerLineReader{R: &testReader{tuples: []tuple{
+			tuple{"", io.EOF},
+		}}}
+		buf := make([]byte, 64)
+
+		n, err := r.Read(buf)
+		ensureError(t, err, "EOF")
+		ensureBufferLimit(t, buf, n, "")
+	})
+
+	t.Run("one line per read", func(t *testing.T) {
+		r := &PerLineReader{R: &testReader{tuples: []tuple{
+			tuple{"line 1\nline 2\nli", nil},
+			tuple{"ne 3\nline 4", io.EOF},
+		}}}
+		buf := make([]byte, 64)
+
+		for _, want := range []string{"line 1\n", "line 2\n", "line 3\n", "line 4"} {
+			n, err := r.Read(buf)
+			ensureErrorNil(t, err)
+			ensureBufferLimit(t, buf, n, want)
+		}
+
+		n, err := r.Read(buf)
+		ensureError(t, err, "EOF")
+		ensureBufferLimit(t, buf, n, "")
+	})
+
+	t.Run("line longer than buffer", func(t *testing.T) {
+		r := &PerLineReader{R: &testReader{tuples: []tuple{
+			tuple{"0123456789\nab\n", io.EOF},
+		}}}
+		buf := make([]byte, 4)
+
+		for _, want := range []string{"0123", "4567", "89\n", "ab\n"} {
+			n, err := r.Read(buf)
+			ensureErrorNil(t, err)
+			ensureBufferLimit(t, buf, n, want)
+		}
+
+		n, err := r.Read(buf)
+		ensureError(t, err, "EOF")
+		ensureBufferLimit(t, buf, n, "")
+	})
+}
+
+func TestPerLineReaderDelim(t *testing.T) {
+	r := &PerLineReader{R: &testReader{tuples: []tuple{
+		tuple{"file\nname 1\x00file 2\x00", io.EOF},
+	}}, Delim: []byte{NUL}}
+	buf := make([]byte, 64)
+
+	for _, want := range []string{"file\nname 1\x00", "file 2\x00"} {
+		n, err := r.Read(buf)
+		ensureErrorNil(t, err)
+		ensureBufferLimit(t, buf, n, want)
+	}
+
+	n, err := r.Read(buf)
+	ensureError(t, err, "EOF")
+	ensureBufferLimit(t, buf, n, "")
+}
+
+func TestPerLineReaderWriteTo(t *testing.T) {
+	r := &PerLineReader{R: &testReader{tuples: []tuple{
+		tuple{"line 1\nline 2\nli", nil},
+		tuple{"ne 3\nline 4", io.EOF},
+	}}}
+	w := new(recordingWriter)
+
+	n, err := r.WriteTo(w)
+	ensureErrorNil(t, err)
+	if got, want := n, int64(27); got != want {
+		t.Errorf("GOT: %v; WANT: %v", got, want)
+	}
+	ensureWrites(t, w.writes, []string{"line 1\n", "line 2\n", "line 3\n", "line 4"})
+}
@@ -26,6 +26,40 @@ type PerLineWriter struct {
 	WC io.WriteCloser
 
 	off int // read at buf[off:]; write at buf[:len(buf)]
+
+	// PrefixFunc, when not nil, is invoked exactly once for each
+	// line immediately before that line is written to WC, including
+	// the final line without a newline written during Close, and the
+	// bytes it returns are written before the line in the same Write
+	// call. Because it is evaluated when the line is emitted rather
+	// than when its first byte arrives, the prefix may change over
+	// time, for instance to reflect a current request ID.
+	PrefixFunc func() []byte
+
+	// FlushPartialOnWrite, when set, causes each Write call to also
+	// write any trailing bytes not terminated by a newline, treating
+	// them as a complete line for that call. This suits interactive
+	// prompts, where the Write boundaries of the producer are
+	// meaningful even without a newline. It weakens the one Write per
+	// line guarantee: each Write to WC is then either a newline
+	// terminated line, or the trailing bytes of a single Write call.
+	FlushPartialOnWrite bool
+
+	// Delim, when not empty, is the sequence of bytes terminating
+	// each line, such as CRLF, rather than a newline. A line is never
+	// written before all bytes of its delimiter have arrived, even
+	// when the delimiter is split across multiple Write calls.
+	Delim []byte
+
+	// DiscardOnCancel, when set, causes ReadFromContext to discard
+	// any buffered partial line when its context is done.
+	DiscardOnCancel bool
+
+	// scratch is used to join prefix and line bytes.
+	scratch []byte
+
+	// counters describe the data written; see Stats.
+	counters writerCounters
 }
 
 // NewPerLineWriter returns a new PerLineWriter that individually
@@ -106,29 +140,73 @@ func (lw *PerLineWriter) bufferReset() {
 
 // Close will transform then write any data remaining in the
 // PerLineWriter that was not newline terminated, then closes the
-// underlying io.WriteCloser.
+// underlying io.WriteCloser. The allocated buffer is kept for Reset.
 func (lw *PerLineWriter) Close() error {
 	var err error
 
 	if lw.bufferLength() > 0 {
 		// When additional bytes are available to be written, flush
 		// them without a newline before we close the stream.
-		if _, err = lw.WC.Write(lw.buf[lw.off:]); err != nil {
+		if err = lw.emit(lw.buf[lw.off:]); err != nil {
 			_ = lw.WC.Close()
 			lw.WC = nil
-			lw.buf = nil
-			lw.off = 0
+			lw.bufferReset()
 			return err
 		}
 	}
 
 	err = lw.WC.Close()
 	lw.WC = nil
-	lw.buf = nil
-	lw.off = 0
+	lw.bufferReset()
 	return err
 }
 
+// Reset discards any buffered data and the counters returned by
+// Stats, then configures the PerLineWriter to write to wc, much like
+// bufio.Writer.Reset. The configuration is kept, including Delim,
+// PrefixFunc, FlushPartialOnWrite and DiscardOnCancel, as are the
+// allocated buffers, so a PerLineWriter may be reused for each
+// connection without allocating. Buffered data is discarded rather
+// than written, and the previous io.WriteCloser is not closed, so
+// callers normally Close the PerLineWriter before calling Reset.
+func (lw *PerLineWriter) Reset(wc io.WriteCloser) {
+	lw.WC = wc
+	lw.bufferReset()
+	lw.scratch = lw.scratch[:0]
+	lw.counters = writerCounters{}
+}
+
+// emit writes a single line to the underlying io.WriteCloser,
+// preceded by the prefix when PrefixFunc is not nil.
+func (lw *PerLineWriter) emit(line []byte) error {
+	data := line
+	if lw.PrefixFunc != nil {
+		lw.scratch = append(append(lw.scratch[:0], lw.PrefixFunc()...), line...)
+		data = lw.scratch
+	}
+	nw, err := lw.WC.Write(data)
+	if nw >= 0 {
+		var lines int
+		if nw == len(data) && lw.delimited(line) {
+			lines = 1
+		}
+		lw.counters.addWrite(nw, lines)
+	}
+	if err != nil {
+		lw.counters.addError()
+	}
+	return err
+}
+
+// delimited returns true when line ends with a delimiter.
+func (lw *PerLineWriter) delimited(line []byte) bool {
+	delim := lw.Delim
+	if len(delim) == 0 {
+		delim = newline
+	}
+	return bytes.HasSuffix(line, delim)
+}
+
 // ReadFrom reads data from r until io.EOF or error, periodically
 // flushing one completed newline to the underlying io.WriteCloser.
 // The return value is the number of bytes read from r. Any error
@@ -147,30 +225,29 @@ func (lw *PerLineWriter) ReadFrom(r io.Reader) (int64, error) {
 		lw.buf = lw.buf[:m]
 
 		nr, rerr := r.Read(lw.buf[m:cap(lw.buf)])
-		if nr < 0 {
+		if nr < 0 || nr > cap(lw.buf)-m {
 			return totalRead, errors.New("invalid read result")
 		}
 
 		lw.buf = lw.buf[:m+nr]
 		totalRead += int64(nr)
+		lw.counters.noteBuffered(lw.bufferLength())
 
 		// NEWLINE LOGIC
 		//
-		index := bytes.IndexByte(lw.buf[m:], '\n')
+		index := lw.lineEnd(m)
 		if index >= 0 {
-			// POST: lw.buf[m+index] is a newline.
-			index += m + 1 // extra byte to include newline
+			// POST: lw.buf[index-1] is the end of a delimiter.
 			for {
-				if _, err := lw.WC.Write(lw.buf[lw.off:index]); err != nil {
+				if err := lw.emit(lw.buf[lw.off:index]); err != nil {
 					return totalRead, err // ???
 				}
 				lw.off = index // advance buf to consume bytes processed
 
-				index = bytes.IndexByte(lw.buf[lw.off:], '\n')
+				index = lw.lineEnd(lw.off)
 				if index == -1 {
 					break
 				}
-				index += lw.off + 1 // extra byte to include newline
 			}
 		}
 		//
@@ -194,35 +271,85 @@ func (lw *PerLineWriter) ReadFrom(r io.Reader) (int64, error) {
 // may result in 0, 1, or many Write calls to the underlying
 // io.WriteCloser, depending on how many newline characters are in p.
 func (lw *PerLineWriter) Write(p []byte) (int, error) {
-	var err error
-	var index int
-
 	m, ok := lw.bufferGrowInline(len(p))
 	if !ok {
 		m = lw.bufferGrow(len(p))
 	}
 	copy(lw.buf[m:], p)
+	return lw.writeAppended(m, len(p))
+}
+
+// WriteString is like Write, but accepts a string, which is copied
+// directly into the buffer, avoiding the allocation of converting it
+// to a byte slice.
+func (lw *PerLineWriter) WriteString(s string) (int, error) {
+	m, ok := lw.bufferGrowInline(len(s))
+	if !ok {
+		m = lw.bufferGrow(len(s))
+	}
+	copy(lw.buf[m:], s)
+	return lw.writeAppended(m, len(s))
+}
+
+// writeAppended writes each line completed by the n bytes just
+// appended to the buffer at index m, returning n on success.
+func (lw *PerLineWriter) writeAppended(m, n int) (int, error) {
+	var err error
+	var index int
+
+	lw.counters.noteBuffered(lw.bufferLength())
 	// POST: lw.buf[m:] is new data, however lw.buf[lw.off:m] also
 	// needs processing.
 
 	// We know remaining bytes lw.buf[lw.off:m] does not have a
-	// newline, so start searching at offset m.
-	index = bytes.IndexByte(lw.buf[m:], '\n')
+	// delimiter, so start searching at offset m.
+	index = lw.lineEnd(m)
 	if index == -1 {
-		return len(p), nil
+		return lw.flushPartial(n)
 	}
-	// POST: lw.buf[m+index] is a newline.
-	index += m + 1 // extra byte to include newline
+	// POST: lw.buf[index-1] is the end of a delimiter.
 
 	for {
-		if _, err = lw.WC.Write(lw.buf[lw.off:index]); err != nil {
-			return len(p), err // ???
+		if err = lw.emit(lw.buf[lw.off:index]); err != nil {
+			return n, err // ???
 		}
 		lw.off = index // advance buf to consume bytes processed
-		index = bytes.IndexByte(lw.buf[lw.off:], '\n')
+		index = lw.lineEnd(lw.off)
 		if index == -1 {
-			return len(p), nil
+			return lw.flushPartial(n)
 		}
-		index += lw.off + 1 // extra byte to include newline
 	}
 }
+
+// lineEnd returns the index just past the first delimiter in the
+// buffer that ends after index m, or -1 when there is none. A
+// delimiter longer than one byte may begin before m, when it was split
+// across multiple writes.
+func (lw *PerLineWriter) lineEnd(m int) int {
+	delim := lw.Delim
+	if len(delim) == 0 {
+		delim = newline
+	}
+	from := m - (len(delim) - 1)
+	if from < lw.off {
+		from = lw.off
+	}
+	index := bytes.Index(lw.buf[from:], delim)
+	if index == -1 {
+		return -1
+	}
+	return from + index + len(delim)
+}
+
+// flushPartial writes any remaining bytes not terminated by a newline
+// when FlushPartialOnWrite is set, returning n on success.
+func (lw *PerLineWriter) flushPartial(n int) (int, error) {
+	if !lw.FlushPartialOnWrite || lw.bufferLength() == 0 {
+		return n, nil
+	}
+	if err := lw.emit(lw.buf[lw.off:]); err != nil {
+		return n, err
+	}
+	lw.off = len(lw.buf)
+	return n, nil
+}
@@ -2,6 +2,8 @@ package gonl
 
 import (
 	"bytes"
+	"fmt"
+	"io"
 	"testing"
 )
 
@@ -75,6 +77,115 @@ func TestPerLineWriter(t *testing.T) {
 		}
 	})
 
+	t.Run("PrefixFunc", func(t *testing.T) {
+		t.Run("nil", func(t *testing.T) {
+			bb := new(testBuffer)
+			lw := &PerLineWriter{WC: bb}
+
+			ensureWrite(t, lw, "line 1\nline 2")
+			ensureErrorNil(t, lw.Close())
+			ensureStringer(t, bb, "line 1\nline 2")
+		})
+		t.Run("once per line", func(t *testing.T) {
+			var count int
+			bb := new(testBuffer)
+			lw := &PerLineWriter{WC: bb, PrefixFunc: func() []byte {
+				count++
+				return []byte(fmt.Sprintf("%d: ", count))
+			}}
+
+			ensureWrite(t, lw, "line 1\nli")
+			ensureWrite(t, lw, "ne ")
+			ensureWrite(t, lw, "2\nline 3\nline 4")
+			ensureStringer(t, bb, "1: line 1\n2: line 2\n3: line 3\n")
+
+			ensureErrorNil(t, lw.Close())
+			ensureStringer(t, bb, "1: line 1\n2: line 2\n3: line 3\n4: line 4")
+			if got, want := count, 4; got != want {
+				t.Errorf("GOT: %v; WANT: %v", got, want)
+			}
+		})
+		t.Run("ReadFrom", func(t *testing.T) {
+			bb := new(testBuffer)
+			lw := &PerLineWriter{WC: bb, PrefixFunc: func() []byte { return []byte("> ") }}
+
+			_, err := lw.ReadFrom(&testReader{tuples: []tuple{
+				tuple{"line 1\nli", nil},
+				tuple{"ne 2\n", io.EOF},
+			}})
+			ensureErrorNil(t, err)
+			ensureStringer(t, bb, "> line 1\n> line 2\n")
+		})
+	})
+
+	t.Run("FlushPartialOnWrite", func(t *testing.T) {
+		bb := new(testBuffer)
+		lw := &PerLineWriter{WC: bb, FlushPartialOnWrite: true}
+
+		ensureWrite(t, lw, "prompt> ")
+		ensureStringer(t, bb, "prompt> ")
+
+		ensureWrite(t, lw, "answer\nline 2\nnext> ")
+		ensureStringer(t, bb, "prompt> answer\nline 2\nnext> ")
+
+		ensureErrorNil(t, lw.Close())
+		ensureStringer(t, bb, "prompt> answer\nline 2\nnext> ")
+	})
+
+	t.Run("ReadFrom invalid read result", func(t *testing.T) {
+		lw := NewPerLineWriter(new(DiscardCounter))
+
+		n, err := lw.ReadFrom(overReader{})
+		ensureError(t, err, "invalid read result")
+		if got, want := n, int64(0); got != want {
+			t.Errorf("GOT: %v; WANT: %v", got, want)
+		}
+	})
+
+	t.Run("Delim", func(t *testing.T) {
+		t.Run("Write", func(t *testing.T) {
+			output := new(testBuffersWriter)
+			lw := &PerLineWriter{WC: output, Delim: crlf}
+
+			ensureWrite(t, lw, "a\nb\r")
+			ensureWrite(t, lw, "\nc\r\nd")
+			ensureStringer(t, output, "a\nb\r\nc\r\n")
+			if got, want := output.writes, 2; got != want {
+				t.Errorf("GOT: %v; WANT: %v", got, want)
+			}
+			ensureErrorNil(t, lw.Close())
+			ensureStringer(t, output, "a\nb\r\nc\r\nd")
+		})
+
+		t.Run("ReadFrom", func(t *testing.T) {
+			output := new(testBuffersWriter)
+			lw := &PerLineWriter{WC: output, Delim: crlf}
+
+			_, err := lw.ReadFrom(&testReader{tuples: []tuple{
+				tuple{"line 1\r", nil},
+				tuple{"\nline 2\r\n", io.EOF},
+			}})
+			ensureErrorNil(t, err)
+			ensureStringer(t, output, "line 1\r\nline 2\r\n")
+			if got, want := output.writes, 2; got != want {
+				t.Errorf("GOT: %v; WANT: %v", got, want)
+			}
+			ensureErrorNil(t, lw.Close())
+		})
+	})
+
+	t.Run("NUL delimiter", func(t *testing.T) {
+		output := new(testBuffersWriter)
+		lw := &PerLineWriter{WC: output, Delim: []byte{NUL}}
+
+		ensureWrite(t, lw, "file\nname 1\x00file 2\x00file")
+		ensureStringer(t, output, "file\nname 1\x00file 2\x00")
+		if got, want := output.writes, 2; got != want {
+			t.Errorf("GOT: %v; WANT: %v", got, want)
+		}
+		ensureErrorNil(t, lw.Close())
+	})
+
 	t.Run("digest", func(t *testing.T) {
 		// ??? not really worried about true message authentication
 		// codes. Just want to shove data into an io.Writer that does a
@@ -127,3 +238,71 @@ func TestPerLineWriter(t *testing.T) {
 		})
 	})
 }
+
+func TestPerLineWriterReset(t *testing.T) {
+	t.Run("reuses buffer", func(t *testing.T) {
+		first := new(testBuffer)
+		lw := NewPerLineWriter(first)
+		lw.Delim = []byte(";")
+		ensureWrite(t, lw, "a;b;c")
+		ensureErrorNil(t, lw.Close())
+		buf := lw.buf
+
+		second := new(testBuffer)
+		lw.Reset(second)
+		if got, want := string(lw.Delim), ";"; got != want {
+			t.Errorf("GOT: %q; WANT: %q", got, want)
+		}
+		if got, want := lw.Stats(), (Stats{}); got != want {
+			t.Errorf("GOT: %+v; WANT: %+v", got, want)
+		}
+		if got, want := cap(lw.buf), cap(buf); got != want {
+			t.Errorf("GOT: %v; WANT: %v", got, want)
+		}
+
+		ensureWrite(t, lw, "d;e")
+		ensureErrorNil(t, lw.Close())
+		ensureStringer(t, first, "a;b;c")
+		ensureStringer(t, second, "d;e")
+	})
+
+	t.Run("keeps configuration", func(t *testing.T) {
+		lw := NewPerLineWriter(new(testBuffer))
+		lw.Delim = crlf
+		lw.FlushPartialOnWrite = true
+		ensureErrorNil(t, lw.Close())
+
+		output := new(recordingWriter)
+		lw.Reset(NopCloseWriter(output))
+		ensureWrite(t, lw, "a\nb\r\nc")
+		ensureErrorNil(t, lw.Close())
+		ensureWrites(t, output.writes, []string{"a\nb\r\n", "c"})
+	})
+
+	t.Run("discards buffered data", func(t *testing.T) {
+		first := new(testBuffer)
+		lw := NewPerLineWriter(first)
+		ensureWrite(t, lw, "partial")
+
+		second := new(testBuffer)
+		lw.Reset(second)
+		ensureErrorNil(t, lw.Close())
+		ensureStringer(t, first, "")
+		ensureStringer(t, second, "")
+	})
+}
+
+func TestPerLineWriterWriteString(t *testing.T) {
+	output := new(recordingWriter)
+	lw := NewPerLineWriterTo(output)
+
+	nw, err := io.WriteString(lw, "line 1\nline 2\npartial")
+	ensureErrorNil(t, err)
+	if got, want := nw, 21; got != want {
+		t.Errorf("GOT: %v; WANT: %v", got, want)
+	}
+	ensureWrites(t, output.writes, []string{"line 1\n", "line 2\n"})
+
+	ensureErrorNil(t, lw.Close())
+	ensureWrites(t, output.writes, []string{"line 1\n", "line 2\n", "partial"})
+}
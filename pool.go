@@ -0,0 +1,150 @@
+package gonl
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"sync"
+)
+
+// BatchLineWriterPool recycles BatchLineWriter instances, along with
+// their buffers, that share the same flush threshold. It is useful
+// when a short lived BatchLineWriter is created and discarded for
+// each request, because it reduces the number of allocations. It is
+// safe for concurrent use.
+//
+//	pool, err := gonl.NewBatchLineWriterPool(4096)
+//	if err != nil {
+//	    return err
+//	}
+//	lw := pool.Get(wc)
+//	// write to lw
+//	err = lw.Close()
+//	pool.Put(lw)
+type BatchLineWriterPool struct {
+	pool           sync.Pool
+	flushThreshold int
+}
+
+// NewBatchLineWriterPool returns a new BatchLineWriterPool whose
+// writers use the specified flush threshold.
+func NewBatchLineWriterPool(flushThreshold int) (*BatchLineWriterPool, error) {
+	if flushThreshold <= 0 {
+		return nil, fmt.Errorf("cannot create BatchLineWriterPool when flushThreshold less than or equal to 0: %d", flushThreshold)
+	}
+	return &BatchLineWriterPool{flushThreshold: flushThreshold}, nil
+}
+
+// Get returns a BatchLineWriter that writes to wc, either recycled
+// from the pool or newly allocated. A recycled BatchLineWriter has
+// its state and its exported fields reset, as though it were newly
+// created by NewBatchLineWriter.
+func (p *BatchLineWriterPool) Get(wc io.WriteCloser) *BatchLineWriter {
+	if lw, ok := p.pool.Get().(*BatchLineWriter); ok {
+		lw.mu.Lock()
+		lw.reset(wc)
+		lw.resetConfig()
+		lw.mu.Unlock()
+		return lw
+	}
+	lw, _ := NewBatchLineWriter(wc, p.flushThreshold) // threshold validated when pool created
+	return lw
+}
+
+// Put returns a BatchLineWriter to the pool. Because a BatchLineWriter
+// that has not been closed may still hold buffered data that would be
+// lost, Put panics when the BatchLineWriter has not been closed. A
+// BatchLineWriter whose flush threshold does not match the pool, or
+// whose delimiter is not a newline, such as one created by
+// NewBatchLineWriterDelimBytes, is not recycled.
+func (p *BatchLineWriterPool) Put(lw *BatchLineWriter) {
+	lw.mu.Lock()
+	if !lw.closed {
+		lw.mu.Unlock()
+		panic(errors.New("gonl.BatchLineWriterPool: Put called with BatchLineWriter that was not closed"))
+	}
+	if lw.flushThreshold != p.flushThreshold || !bytes.Equal(lw.delim, newline) {
+		lw.mu.Unlock()
+		return
+	}
+	lw.reset(nil) // do not keep references to previous destination
+	lw.resetConfig()
+	lw.mu.Unlock()
+	p.pool.Put(lw)
+}
+
+// Reset discards any buffered data, the counters returned by Stats,
+// and all other state, including any error, then configures the
+// BatchLineWriter to write to wc, much like bufio.Writer.Reset. The
+// configuration is kept, including the flush threshold, the delimiter
+// and the exported fields, as is the allocated buffer, so a long lived
+// BatchLineWriter may be reused for each request of a busy server
+// without allocating, much like BatchLineWriterPool does. Buffered
+// data is discarded rather than written, and the previous
+// io.WriteCloser is not closed, so callers normally Close the
+// BatchLineWriter before calling Reset. Reset must not be called
+// concurrently with any other method.
+func (lw *BatchLineWriter) Reset(wc io.WriteCloser) {
+	lw.mu.Lock()
+	defer lw.mu.Unlock()
+	lw.reset(wc)
+}
+
+// reset discards all state while keeping the configuration and the
+// allocated buffer, then configures the BatchLineWriter to write to wc.
+// It is called with mu held, and stops any pending FlushInterval timer.
+func (lw *BatchLineWriter) reset(wc io.WriteCloser) {
+	lw.stopFlushTimer()
+	if lw.stalled {
+		lw.buf = nil // an abandoned write may still be using it
+	}
+	lw.bufferReset()
+	lw.wc = wc
+	lw.writeBuffers = buffersWriterFor(wc)
+	lw.vectors = [2][]byte{}
+	lw.closed = false
+	lw.stalled = false
+
+	lw.counters = writerCounters{}
+	lw.lineLengths = nil
+	lw.latencies = nil
+	lw.partialLineLength = 0
+	lw.delimMatched = 0
+	lw.continued = false
+	lw.unsynced = 0
+
+	lw.written = 0
+	lw.lineOffset = 0
+	lw.lineLength = 0
+	lw.discarding = false
+	lw.delimPending = 0
+	lw.truncating = false
+}
+
+// resetConfig restores the exported fields to their zero values, as
+// though the BatchLineWriter were newly created by NewBatchLineWriter.
+// It is called with mu held.
+func (lw *BatchLineWriter) resetConfig() {
+	lw.FlushOnlyOnClose = false
+	lw.OnError = nil
+	lw.ErrorHandler = nil
+	lw.CollectLineLengths = false
+	lw.FlushPartialWhenFull = false
+	lw.JoinContinuations = false
+	lw.ContinuationByte = 0
+	lw.CollectFlushLatency = false
+	lw.HoldLines = 0
+	lw.FlushInterval = 0
+	lw.OnFlush = nil
+	lw.FlushTracer = nil
+	lw.DiscardOnCancel = false
+	lw.WriteTimeout = 0
+	lw.SyncEvery = 0
+	lw.Retry = nil
+	lw.Grow = nil
+	lw.MaxBuffer = 0
+	lw.Overflow = OverflowError
+	lw.MaxLineLength = 0
+	lw.TruncateLongLines = false
+}
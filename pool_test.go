@@ -0,0 +1,148 @@
+package gonl
+
+import (
+	"testing"
+	"time"
+)
+
+func TestBatchLineWriterPool(t *testing.T) {
+	t.Run("NewBatchLineWriterPool", func(t *testing.T) {
+		_, err := NewBatchLineWriterPool(0)
+		ensureError(t, err, "flushThreshold")
+	})
+
+	t.Run("Get resets state", func(t *testing.T) {
+		pool, err := NewBatchLineWriterPool(8)
+		ensureErrorNil(t, err)
+
+		first := new(testBuffer)
+		lw := pool.Get(first)
+		lw.FlushOnlyOnClose = true
+		ensureWrite(t, lw, "line 1\nline 2")
+		ensureErrorNil(t, lw.Close())
+		ensureStringer(t, first, "line 1\nline 2")
+		pool.Put(lw)
+
+		second := new(testBuffer)
+		lw = pool.Get(second)
+		if got, want := lw.Size(), 8; got != want {
+			t.Errorf("GOT: %v; WANT: %v", got, want)
+		}
+		if lw.FlushOnlyOnClose {
+			t.Errorf("GOT: %v; WANT: %v", lw.FlushOnlyOnClose, false)
+		}
+		ensureWrite(t, lw, "line 3\nline 4\n")
+		ensureStringer(t, second, "line 3\nline 4\n")
+		ensureErrorNil(t, lw.Close())
+		ensureStringer(t, first, "line 1\nline 2")
+	})
+
+	t.Run("Put does not recycle other delimiters", func(t *testing.T) {
+		pool, err := NewBatchLineWriterPool(8)
+		ensureErrorNil(t, err)
+
+		lw, err := NewBatchLineWriterDelimBytes(new(testBuffer), 8, crlf)
+		ensureErrorNil(t, err)
+		ensureErrorNil(t, lw.Close())
+		pool.Put(lw)
+
+		output := new(testBuffer)
+		lw = pool.Get(output)
+		if got, want := string(lw.DelimiterBytes()), "\n"; got != want {
+			t.Errorf("GOT: %q; WANT: %q", got, want)
+		}
+		ensureWrite(t, lw, "line 1\nline 2")
+		ensureStringer(t, output, "line 1\n")
+		ensureErrorNil(t, lw.Close())
+		ensureStringer(t, output, "line 1\nline 2")
+	})
+
+	t.Run("Put with pending FlushInterval", func(t *testing.T) {
+		pool, err := NewBatchLineWriterPool(64)
+		ensureErrorNil(t, err)
+
+		for i := 0; i < 1000; i++ {
+			output := new(testBuffer)
+			lw := pool.Get(output)
+			lw.FlushInterval = time.Microsecond
+			ensureWrite(t, lw, "line 1\n")
+			time.Sleep(time.Duration(i%4) * time.Microsecond)
+			ensureErrorNil(t, lw.Close())
+			pool.Put(lw)
+			ensureStringer(t, output, "line 1\n")
+		}
+	})
+
+	t.Run("Put requires closed writer", func(t *testing.T) {
+		pool, err := NewBatchLineWriterPool(8)
+		ensureErrorNil(t, err)
+
+		lw := pool.Get(new(testBuffer))
+		ensurePanic(t, "gonl.BatchLineWriterPool: Put called with BatchLineWriter that was not closed", func() {
+			pool.Put(lw)
+		})
+	})
+}
+
+func TestBatchLineWriterReset(t *testing.T) {
+	t.Run("reuses buffer", func(t *testing.T) {
+		first := new(testBuffer)
+		lw, err := NewBatchLineWriterDelim(first, 64, ';')
+		ensureErrorNil(t, err)
+		lw.FlushOnlyOnClose = true
+		ensureWrite(t, lw, "a;b;c")
+		ensureErrorNil(t, lw.Close())
+		buf := lw.buf
+
+		second := new(testBuffer)
+		lw.Reset(second)
+		if !lw.FlushOnlyOnClose {
+			t.Errorf("GOT: %v; WANT: %v", lw.FlushOnlyOnClose, true)
+		}
+		if got, want := lw.Stats(), (Stats{}); got != want {
+			t.Errorf("GOT: %+v; WANT: %+v", got, want)
+		}
+		if got, want := lw.Delimiter(), byte(';'); got != want {
+			t.Errorf("GOT: %q; WANT: %q", got, want)
+		}
+		if got, want := cap(lw.buf), cap(buf); got != want {
+			t.Errorf("GOT: %v; WANT: %v", got, want)
+		}
+
+		ensureWrite(t, lw, "d;e")
+		ensureErrorNil(t, lw.Close())
+		ensureStringer(t, first, "a;b;c")
+		ensureStringer(t, second, "d;e")
+	})
+
+	t.Run("keeps configuration", func(t *testing.T) {
+		first := new(testBuffer)
+		lw, err := NewBatchLineWriterOptions(first, WithFlushThreshold(1), WithMaxLineLength(4, true), WithWriteTimeout(time.Minute))
+		ensureErrorNil(t, err)
+		ensureWrite(t, lw, "line 1\nline 2")
+		ensureErrorNil(t, lw.Close())
+
+		second := new(testBuffer)
+		lw.Reset(second)
+		if got, want := lw.WriteTimeout, time.Minute; got != want {
+			t.Errorf("GOT: %v; WANT: %v", got, want)
+		}
+		ensureWrite(t, lw, "line 3\n")
+		ensureErrorNil(t, lw.Close())
+		ensureStringer(t, first, "line\nline")
+		ensureStringer(t, second, "line\n")
+	})
+
+	t.Run("discards buffered data", func(t *testing.T) {
+		first := new(testBuffer)
+		lw, err := NewBatchLineWriter(first, 64)
+		ensureErrorNil(t, err)
+		ensureWrite(t, lw, "line 1\n")
+
+		second := new(testBuffer)
+		lw.Reset(second)
+		ensureErrorNil(t, lw.Close())
+		ensureStringer(t, first, "")
+		ensureStringer(t, second, "")
+	})
+}
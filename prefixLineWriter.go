@@ -0,0 +1,53 @@
+package gonl
+
+import (
+	"bytes"
+	"io"
+	"time"
+)
+
+// NewPrefixLineWriter returns a PerLineWriter that writes each line to
+// wc preceded by prefix, such as "worker-3: ", to label output from
+// multiple sources that is multiplexed onto one stream. The prefix is
+// written only once per line, at its start, even when the line
+// arrives across several Write calls. The prefix is copied, so the
+// caller may reuse the slice.
+func NewPrefixLineWriter(wc io.WriteCloser, prefix []byte) *PerLineWriter {
+	prefix = append([]byte(nil), prefix...)
+	return &PerLineWriter{WC: wc, PrefixFunc: func() []byte { return prefix }}
+}
+
+// NewTimestampLineWriter returns a PerLineWriter that writes each line
+// to wc preceded by the time the line was completed, formatted using
+// the layout accepted by time.Time.Format, and followed by a single
+// space. Because the timestamp is added when the line is written, it
+// never appears in the middle of a line. To format the prefix some
+// other way, set the PrefixFunc field of a PerLineWriter directly.
+//
+//	lw := gonl.NewTimestampLineWriter(os.Stderr, time.RFC3339)
+func NewTimestampLineWriter(wc io.WriteCloser, layout string) *PerLineWriter {
+	var buf []byte
+	return &PerLineWriter{WC: wc, PrefixFunc: func() []byte {
+		buf = append(time.Now().AppendFormat(buf[:0], layout), ' ')
+		return buf
+	}}
+}
+
+// NewIndentWriter returns a PerLineWriter that writes each line to wc
+// indented by n spaces, or by n tabs when tab is true, such as when
+// nesting the output of a subprocess beneath a heading. Because it
+// line buffers its input, lines are indented correctly regardless of
+// how the bytes are split across Write calls. To reduce the number of
+// writes to a destination, wrap it in a BatchLineWriter and pass that
+// as wc.
+func NewIndentWriter(wc io.WriteCloser, n int, tab bool) *PerLineWriter {
+	c := byte(' ')
+	if tab {
+		c = '\t'
+	}
+	var indent []byte
+	if n > 0 {
+		indent = bytes.Repeat([]byte{c}, n)
+	}
+	return NewPrefixLineWriter(wc, indent)
+}
@@ -0,0 +1,83 @@
+package gonl
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestNewPrefixLineWriter(t *testing.T) {
+	output := new(testBuffer)
+	prefix := []byte("worker-3: ")
+	lw := NewPrefixLineWriter(output, prefix)
+	prefix[0] = 'W' // prefix was copied
+
+	ensureWrite(t, lw, "line 1\nli")
+	ensureWrite(t, lw, "ne 2\n\nline")
+	ensureStringer(t, output, "worker-3: line 1\nworker-3: line 2\nworker-3: \n")
+	ensureErrorNil(t, lw.Close())
+	ensureStringer(t, output, "worker-3: line 1\nworker-3: line 2\nworker-3: \nworker-3: line")
+}
+
+func TestNewTimestampLineWriter(t *testing.T) {
+	output := new(testBuffer)
+	lw := NewTimestampLineWriter(output, time.RFC3339)
+
+	before := time.Now().Truncate(time.Second)
+	ensureWrite(t, lw, "line 1\nli")
+	ensureWrite(t, lw, "ne 2\n")
+	ensureErrorNil(t, lw.Close())
+	after := time.Now()
+
+	lines := strings.Split(strings.TrimSuffix(output.String(), "\n"), "\n")
+	if got, want := len(lines), 2; got != want {
+		t.Fatalf("GOT: %v; WANT: %v", got, want)
+	}
+	for i, line := range lines {
+		fields := strings.SplitN(line, " ", 2)
+		if len(fields) != 2 {
+			t.Fatalf("GOT: %q; WANT: timestamp prefix", line)
+		}
+		when, err := time.Parse(time.RFC3339, fields[0])
+		ensureErrorNil(t, err)
+		if when.Before(before) || when.After(after) {
+			t.Errorf("GOT: %v; WANT: between %v and %v", when, before, after)
+		}
+		if got, want := fields[1], []string{"line 1", "line 2"}[i]; got != want {
+			t.Errorf("GOT: %q; WANT: %q", got, want)
+		}
+	}
+}
+
+func TestNewIndentWriter(t *testing.T) {
+	t.Run("spaces", func(t *testing.T) {
+		output := new(testBuffer)
+		lw := NewIndentWriter(output, 4, false)
+
+		ensureWrite(t, lw, "line 1\nli")
+		ensureWrite(t, lw, "ne 2\nline 3")
+		ensureErrorNil(t, lw.Close())
+		ensureStringer(t, output, "    line 1\n    line 2\n    line 3")
+	})
+
+	t.Run("tabs", func(t *testing.T) {
+		output := new(testBuffer)
+		lw := NewIndentWriter(output, 2, true)
+
+		ensureWrite(t, lw, "line 1\n")
+		ensureErrorNil(t, lw.Close())
+		ensureStringer(t, output, "\t\tline 1\n")
+	})
+
+	t.Run("nested in BatchLineWriter", func(t *testing.T) {
+		output := new(testBuffer)
+		bw, err := NewBatchLineWriter(output, 64)
+		ensureErrorNil(t, err)
+		lw := NewIndentWriter(bw, 2, false)
+
+		ensureWrite(t, lw, "line 1\nline 2\n")
+		ensureStringer(t, output, "")
+		ensureErrorNil(t, lw.Close())
+		ensureStringer(t, output, "  line 1\n  line 2\n")
+	})
+}
@@ -0,0 +1,73 @@
+package gonl
+
+import (
+	"errors"
+	"io"
+)
+
+// ErrQuotaExceeded is returned by QuotaWriter when writing a line
+// would cause more than its maximum number of bytes to be written.
+var ErrQuotaExceeded = errors.New("gonl: quota exceeded")
+
+// QuotaWriter is an io.WriteCloser that refuses to write more than Max
+// bytes in total to the underlying io.WriteCloser.
+//
+// Enforcement is line aware: each complete line is written only when
+// the entire line fits within the remaining quota. The first line that
+// does not fit is not written, not even in part, and the Write call
+// that completed it returns ErrQuotaExceeded along with the number of
+// bytes that preceded that line. From then on, every Write returns
+// ErrQuotaExceeded without writing anything. Lines preceding the
+// offending line in the same Write call are still written.
+type QuotaWriter struct {
+	// WC is io.WriteCloser where data is ultimately written.
+	WC io.WriteCloser
+
+	// Max is the maximum number of bytes to write to WC.
+	Max int64
+
+	la       lineAssembler
+	written  int64
+	exceeded bool
+}
+
+// Close writes the final line not terminated by a newline when it fits
+// within the remaining quota, then closes the underlying
+// io.WriteCloser. When the final line does not fit, or the quota was
+// previously exceeded, it closes the underlying io.WriteCloser and
+// returns ErrQuotaExceeded.
+func (qw *QuotaWriter) Close() error {
+	var err error
+	if qw.exceeded {
+		err = ErrQuotaExceeded
+	} else {
+		err = qw.la.close(qw.emit)
+	}
+	if cerr := qw.WC.Close(); err == nil {
+		err = cerr
+	}
+	return err
+}
+
+// Written returns the number of bytes written to the underlying
+// io.WriteCloser.
+func (qw *QuotaWriter) Written() int64 { return qw.written }
+
+// Write writes each line completed by p that fits within the
+// remaining quota, returning the number of bytes of p consumed.
+func (qw *QuotaWriter) Write(p []byte) (int, error) {
+	if qw.exceeded {
+		return 0, ErrQuotaExceeded
+	}
+	return qw.la.write(p, qw.emit)
+}
+
+func (qw *QuotaWriter) emit(line []byte) error {
+	if qw.written+int64(len(line)) > qw.Max {
+		qw.exceeded = true
+		return ErrQuotaExceeded
+	}
+	nw, err := qw.WC.Write(line)
+	qw.written += int64(nw)
+	return err
+}
@@ -0,0 +1,59 @@
+package gonl
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestQuotaWriter(t *testing.T) {
+	t.Run("within quota", func(t *testing.T) {
+		output := new(testBuffer)
+		qw := &QuotaWriter{WC: output, Max: 14}
+
+		ensureWrite(t, qw, "line 1\nline 2")
+		ensureWrite(t, qw, "\n")
+		ensureErrorNil(t, qw.Close())
+		ensureStringer(t, output, "line 1\nline 2\n")
+		if got, want := qw.Written(), int64(14); got != want {
+			t.Errorf("GOT: %v; WANT: %v", got, want)
+		}
+	})
+
+	t.Run("stops before line straddling quota", func(t *testing.T) {
+		output := new(testBuffer)
+		qw := &QuotaWriter{WC: output, Max: 10}
+
+		n, err := qw.Write([]byte("line 1\nline 2\nline 3\n"))
+		if !errors.Is(err, ErrQuotaExceeded) {
+			t.Errorf("GOT: %v; WANT: %v", err, ErrQuotaExceeded)
+		}
+		if got, want := n, 7; got != want {
+			t.Errorf("GOT: %v; WANT: %v", got, want)
+		}
+		ensureStringer(t, output, "line 1\n")
+
+		n, err = qw.Write([]byte("x\n"))
+		if !errors.Is(err, ErrQuotaExceeded) {
+			t.Errorf("GOT: %v; WANT: %v", err, ErrQuotaExceeded)
+		}
+		if got, want := n, 0; got != want {
+			t.Errorf("GOT: %v; WANT: %v", got, want)
+		}
+
+		if err = qw.Close(); !errors.Is(err, ErrQuotaExceeded) {
+			t.Errorf("GOT: %v; WANT: %v", err, ErrQuotaExceeded)
+		}
+		ensureStringer(t, output, "line 1\n")
+	})
+
+	t.Run("final partial line", func(t *testing.T) {
+		output := new(testBuffer)
+		qw := &QuotaWriter{WC: output, Max: 10}
+
+		ensureWrite(t, qw, "line 1\nline 2")
+		if err := qw.Close(); !errors.Is(err, ErrQuotaExceeded) {
+			t.Errorf("GOT: %v; WANT: %v", err, ErrQuotaExceeded)
+		}
+		ensureStringer(t, output, "line 1\n")
+	})
+}
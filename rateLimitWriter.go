@@ -0,0 +1,108 @@
+package gonl
+
+import (
+	"bytes"
+	"io"
+	"time"
+)
+
+// RateLimitWriter is an io.WriteCloser that throttles the data written
+// to the underlying io.WriteCloser to LinesPerSecond lines and
+// BytesPerSecond bytes per second, using a token bucket for each, such
+// as when feeding an ingestion API that rejects bursts. Rather than
+// splitting a Write to stay within the budget, it delays the whole
+// Write until the budget allows it, so when wrapped by a
+// BatchLineWriter, whose flushes consist of complete lines, lines are
+// never split. A Write larger than the burst size is allowed once
+// enough time has passed to pay for it.
+//
+// A RateLimitWriter is not safe for concurrent use by multiple
+// goroutines, but a BatchLineWriter wrapping it serializes its Write
+// calls.
+//
+//	rw := &gonl.RateLimitWriter{WC: conn, LinesPerSecond: 1000}
+//	lw, err := gonl.NewBatchLineWriter(rw, 16*1024)
+type RateLimitWriter struct {
+	// WC is io.WriteCloser where data is ultimately written.
+	WC io.WriteCloser
+
+	// LinesPerSecond, when greater than 0, is the sustained number of
+	// newlines written per second.
+	LinesPerSecond float64
+
+	// BytesPerSecond, when greater than 0, is the sustained number of
+	// bytes written per second.
+	BytesPerSecond float64
+
+	// Burst is the number of seconds of budget that may accumulate
+	// while idle, and be spent at once. When zero, 1 is used.
+	Burst float64
+
+	lines, bytes tokenBucket
+
+	// now and sleep may be replaced by tests.
+	now   func() time.Time
+	sleep func(time.Duration)
+}
+
+// tokenBucket holds the budget available for one rate.
+type tokenBucket struct {
+	tokens  float64
+	updated time.Time
+}
+
+// take removes n tokens from the bucket, which refills at rate tokens
+// per second up to burst seconds worth, returning how long to wait
+// before the tokens have been paid for.
+func (tb *tokenBucket) take(now time.Time, rate, burst, n float64) time.Duration {
+	if tb.updated.IsZero() {
+		tb.tokens = rate * burst // start full
+	} else if elapsed := now.Sub(tb.updated).Seconds(); elapsed > 0 {
+		tb.tokens += elapsed * rate
+		if full := rate * burst; tb.tokens > full {
+			tb.tokens = full
+		}
+	}
+	tb.updated = now
+	tb.tokens -= n
+	if tb.tokens >= 0 {
+		return 0
+	}
+	return time.Duration(-tb.tokens / rate * float64(time.Second))
+}
+
+// Close closes the underlying io.WriteCloser.
+func (rw *RateLimitWriter) Close() error {
+	return rw.WC.Close()
+}
+
+// Write waits until the budget allows writing p, then writes it to the
+// underlying io.WriteCloser.
+func (rw *RateLimitWriter) Write(p []byte) (int, error) {
+	now, sleep := rw.now, rw.sleep
+	if now == nil {
+		now = time.Now
+	}
+	if sleep == nil {
+		sleep = time.Sleep
+	}
+	burst := rw.Burst
+	if burst <= 0 {
+		burst = 1
+	}
+
+	t := now()
+	var wait time.Duration
+	if rw.LinesPerSecond > 0 {
+		wait = rw.lines.take(t, rw.LinesPerSecond, burst, float64(bytes.Count(p, newline)))
+	}
+	if rw.BytesPerSecond > 0 {
+		if d := rw.bytes.take(t, rw.BytesPerSecond, burst, float64(len(p))); d > wait {
+			wait = d
+		}
+	}
+	if wait > 0 {
+		sleep(wait)
+	}
+	return rw.WC.Write(p)
+}
@@ -0,0 +1,61 @@
+package gonl
+
+import (
+	"testing"
+	"time"
+)
+
+// fakeClock is a clock advanced only by sleeping.
+type fakeClock struct {
+	t      time.Time
+	sleeps []time.Duration
+}
+
+func (fc *fakeClock) now() time.Time { return fc.t }
+
+func (fc *fakeClock) sleep(d time.Duration) {
+	fc.sleeps = append(fc.sleeps, d)
+	fc.t = fc.t.Add(d)
+}
+
+func TestRateLimitWriter(t *testing.T) {
+	t.Run("lines per second", func(t *testing.T) {
+		output := new(testBuffer)
+		fc := &fakeClock{t: time.Unix(1000, 0)}
+		rw := &RateLimitWriter{WC: output, LinesPerSecond: 2, now: fc.now, sleep: fc.sleep}
+
+		ensureWrite(t, rw, "line 1\nline 2\n")         // burst
+		ensureWrite(t, rw, "line 3\n")                 // waits 0.5s
+		ensureWrite(t, rw, "line 4\nline 5\nline 6\n") // waits 1.5s
+		ensureStringer(t, output, "line 1\nline 2\nline 3\nline 4\nline 5\nline 6\n")
+		if got, want := fc.sleeps, []time.Duration{500 * time.Millisecond, 1500 * time.Millisecond}; len(got) != len(want) || got[0] != want[0] || got[1] != want[1] {
+			t.Errorf("GOT: %v; WANT: %v", got, want)
+		}
+
+		fc.t = fc.t.Add(time.Hour) // refills only up to the burst
+		ensureWrite(t, rw, "line 7\nline 8\nline 9\n")
+		if got, want := fc.sleeps[len(fc.sleeps)-1], 500*time.Millisecond; len(fc.sleeps) != 3 || got != want {
+			t.Errorf("GOT: %v; WANT: %v", fc.sleeps, want)
+		}
+	})
+
+	t.Run("bytes per second", func(t *testing.T) {
+		output := new(testBuffer)
+		fc := &fakeClock{t: time.Unix(1000, 0)}
+		rw := &RateLimitWriter{WC: output, BytesPerSecond: 10, LinesPerSecond: 100, Burst: 0.5, now: fc.now, sleep: fc.sleep}
+
+		ensureWrite(t, rw, "line 1\n")       // 7 bytes of the 5 byte burst
+		ensureWrite(t, rw, "line 2\nline 3") // 13 more bytes
+		if got, want := fc.sleeps, []time.Duration{200 * time.Millisecond, 1300 * time.Millisecond}; len(got) != len(want) || got[0] != want[0] || got[1] != want[1] {
+			t.Errorf("GOT: %v; WANT: %v", got, want)
+		}
+		ensureErrorNil(t, rw.Close())
+	})
+
+	t.Run("unlimited", func(t *testing.T) {
+		output := new(testBuffer)
+		rw := &RateLimitWriter{WC: output}
+		ensureWrite(t, rw, "line 1\n")
+		ensureStringer(t, output, "line 1\n")
+	})
+}
@@ -0,0 +1,118 @@
+package gonl
+
+import (
+	"context"
+	"io"
+	"time"
+)
+
+// ReadFromContext is like ReadFrom, but stops reading from r as soon as
+// ctx is done, returning the number of bytes read from r along with
+// ctx.Err(). The bytes already read are processed before it returns.
+// Unless DiscardOnCancel is set, all completed lines not held because
+// of HoldLines are then flushed, even when the flush threshold has not
+// been reached, while any partial line remains buffered for a later
+// Write or Close. When DiscardOnCancel is set, all buffered bytes are
+// discarded instead.
+//
+// When r has a SetReadDeadline method, like net.Conn and *os.File, a
+// Read blocked when ctx is done is interrupted by setting the read
+// deadline of r to a time in the past, which remains in effect after
+// ReadFromContext returns. Otherwise cancellation is noticed when the
+// blocked Read returns.
+func (lw *BatchLineWriter) ReadFromContext(ctx context.Context, r io.Reader) (int64, error) {
+	cr, stop := newContextReader(ctx, r)
+	n, err := lw.ReadFrom(cr)
+	stop()
+	if err == nil || err != ctx.Err() {
+		return n, err
+	}
+
+	lw.mu.Lock()
+	defer lw.mu.Unlock()
+	if lw.DiscardOnCancel {
+		lw.bufferReset()
+		lw.partialLineLength = 0
+		lw.delimMatched = 0
+		return n, err
+	}
+	if index := lw.releaseIndex(); index >= lw.off {
+		if _, werr := lw.flush(lw.bufferLength(), 0, index+1); werr != nil {
+			return n, werr
+		}
+	}
+	return n, err
+}
+
+// ReadFromContext is like ReadFrom, but stops reading from r as soon as
+// ctx is done, returning the number of bytes read from r along with
+// ctx.Err(). The completed lines among the bytes already read are
+// written before it returns. Any partial line remains buffered for a
+// later Write or Close, unless DiscardOnCancel is set, in which case it
+// is discarded.
+//
+// When r has a SetReadDeadline method, like net.Conn and *os.File, a
+// Read blocked when ctx is done is interrupted by setting the read
+// deadline of r to a time in the past, which remains in effect after
+// ReadFromContext returns. Otherwise cancellation is noticed when the
+// blocked Read returns.
+func (lw *PerLineWriter) ReadFromContext(ctx context.Context, r io.Reader) (int64, error) {
+	cr, stop := newContextReader(ctx, r)
+	n, err := lw.ReadFrom(cr)
+	stop()
+	if err != nil && err == ctx.Err() && lw.DiscardOnCancel {
+		lw.bufferReset()
+	}
+	return n, err
+}
+
+// readDeadliner is implemented by readers whose blocked Read calls may
+// be interrupted by setting a deadline.
+type readDeadliner interface {
+	SetReadDeadline(t time.Time) error
+}
+
+// contextReader is an io.Reader that returns the error of ctx from Read
+// once ctx is done.
+type contextReader struct {
+	ctx context.Context
+	r   io.Reader
+}
+
+// newContextReader returns a contextReader for ctx and r, along with a
+// function that must be called when done reading from it. When r is a
+// readDeadliner, a goroutine interrupts a blocked Read when ctx is
+// done, until the returned function is called.
+func newContextReader(ctx context.Context, r io.Reader) (io.Reader, func()) {
+	cr := &contextReader{ctx: ctx, r: r}
+	rd, ok := r.(readDeadliner)
+	if !ok || ctx.Done() == nil {
+		return cr, func() {}
+	}
+	stop := make(chan struct{})
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		select {
+		case <-ctx.Done():
+			_ = rd.SetReadDeadline(time.Unix(1, 0))
+		case <-stop:
+		}
+	}()
+	return cr, func() {
+		close(stop)
+		<-done
+	}
+}
+
+func (cr *contextReader) Read(p []byte) (int, error) {
+	if err := cr.ctx.Err(); err != nil {
+		return 0, err
+	}
+	n, err := cr.r.Read(p)
+	if cerr := cr.ctx.Err(); cerr != nil && err != io.EOF {
+		// Also replaces the error caused by the read deadline.
+		return n, cerr
+	}
+	return n, err
+}
@@ -0,0 +1,125 @@
+package gonl
+
+import (
+	"context"
+	"net"
+	"testing"
+	"time"
+)
+
+// cancelReader returns data from its first Read, then cancels its
+// context before returning from the second Read.
+type cancelReader struct {
+	data   string
+	cancel context.CancelFunc
+	reads  int
+}
+
+func (cr *cancelReader) Read(p []byte) (int, error) {
+	cr.reads++
+	if cr.reads == 1 {
+		return copy(p, cr.data), nil
+	}
+	cr.cancel()
+	return 0, nil
+}
+
+func TestReadFromContext(t *testing.T) {
+	t.Run("BatchLineWriter already cancelled", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+
+		lw, err := NewBatchLineWriter(new(testBuffer), 64)
+		ensureErrorNil(t, err)
+		cr := &cancelReader{data: "line 1\n", cancel: cancel}
+
+		n, err := lw.ReadFromContext(ctx, cr)
+		ensureError(t, err, context.Canceled.Error())
+		if got, want := n, int64(0); got != want {
+			t.Errorf("GOT: %v; WANT: %v", got, want)
+		}
+		if got, want := cr.reads, 0; got != want {
+			t.Errorf("GOT: %v; WANT: %v", got, want)
+		}
+	})
+
+	t.Run("BatchLineWriter flushes completed lines", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+
+		output := new(testBuffer)
+		lw, err := NewBatchLineWriter(output, 64)
+		ensureErrorNil(t, err)
+
+		n, err := lw.ReadFromContext(ctx, &cancelReader{data: "line 1\nline 2\npart", cancel: cancel})
+		ensureError(t, err, context.Canceled.Error())
+		if got, want := n, int64(18); got != want {
+			t.Errorf("GOT: %v; WANT: %v", got, want)
+		}
+		ensureStringer(t, output, "line 1\nline 2\n")
+		ensureErrorNil(t, lw.Close())
+		ensureStringer(t, output, "line 1\nline 2\npart")
+	})
+
+	t.Run("BatchLineWriter discards on cancel", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+
+		output := new(testBuffer)
+		lw, err := NewBatchLineWriterOptions(output, WithFlushThreshold(64), WithDiscardOnCancel())
+		ensureErrorNil(t, err)
+
+		_, err = lw.ReadFromContext(ctx, &cancelReader{data: "line 1\nline 2\npart", cancel: cancel})
+		ensureError(t, err, context.Canceled.Error())
+		ensureErrorNil(t, lw.Close())
+		ensureStringer(t, output, "")
+	})
+
+	t.Run("BatchLineWriter interrupts blocked read", func(t *testing.T) {
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+		defer cancel()
+
+		client, server := net.Pipe()
+		defer client.Close()
+		defer server.Close()
+		go func() { _, _ = client.Write([]byte("line 1\n")) }()
+
+		output := new(testBuffer)
+		lw, err := NewBatchLineWriter(output, 64)
+		ensureErrorNil(t, err)
+
+		n, err := lw.ReadFromContext(ctx, server)
+		ensureError(t, err, context.DeadlineExceeded.Error())
+		if got, want := n, int64(7); got != want {
+			t.Errorf("GOT: %v; WANT: %v", got, want)
+		}
+		ensureStringer(t, output, "line 1\n")
+	})
+
+	t.Run("PerLineWriter", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+
+		output := new(testBuffer)
+		lw := &PerLineWriter{WC: output}
+
+		_, err := lw.ReadFromContext(ctx, &cancelReader{data: "line 1\npart", cancel: cancel})
+		ensureError(t, err, context.Canceled.Error())
+		ensureStringer(t, output, "line 1\n")
+		ensureErrorNil(t, lw.Close())
+		ensureStringer(t, output, "line 1\npart")
+	})
+
+	t.Run("PerLineWriter discards on cancel", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+
+		output := new(testBuffer)
+		lw := &PerLineWriter{WC: output, DiscardOnCancel: true}
+
+		_, err := lw.ReadFromContext(ctx, &cancelReader{data: "line 1\npart", cancel: cancel})
+		ensureError(t, err, context.Canceled.Error())
+		ensureErrorNil(t, lw.Close())
+		ensureStringer(t, output, "line 1\n")
+	})
+}
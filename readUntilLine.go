@@ -0,0 +1,135 @@
+package gonl
+
+import (
+	"bufio"
+	"bytes"
+	"io"
+)
+
+// sliceReader is the subset of bufio.Reader methods used by
+// ReadUntilLine.
+type sliceReader interface {
+	ReadSlice(delim byte) ([]byte, error)
+}
+
+// byteSliceReader implements sliceReader for an arbitrary io.Reader by
+// reading a single byte at a time, so that it never consumes bytes
+// past the delimiter from its source.
+type byteSliceReader struct {
+	r   io.Reader
+	buf []byte
+}
+
+func (br *byteSliceReader) ReadSlice(delim byte) ([]byte, error) {
+	var b [1]byte
+	br.buf = br.buf[:0]
+	for {
+		n, err := br.r.Read(b[:])
+		if n == 1 {
+			br.buf = append(br.buf, b[0])
+			if b[0] == delim {
+				return br.buf, nil
+			}
+		}
+		if err != nil {
+			return br.buf, err
+		}
+	}
+}
+
+// ReadUntilLine reads data from r until it reads a line whose
+// contents, excluding its terminating delimiter, equal sentinel, which
+// must not contain the final byte of the delimiter. Each line
+// preceding the sentinel line is written to the BatchLineWriter as
+// though by Write, and when inclusive is true, so is the sentinel
+// line. After reading the sentinel line, all buffered data is flushed
+// to the underlying io.WriteCloser. The return value is the number of
+// bytes read from r, including the sentinel line. When r returns
+// io.EOF before the sentinel line is read, io.ErrUnexpectedEOF is
+// returned, and the data read remains buffered.
+//
+// This method is useful for protocols where a line such as "." or
+// "END" terminates each message, because r is left positioned just
+// after the sentinel line, allowing the next message to be read from
+// the same io.Reader. When r is a *bufio.Reader, its buffer is used to
+// read each line; otherwise r is read one byte at a time so that no
+// bytes following the sentinel line are consumed.
+//
+// After Close has been called, it returns ErrClosed without reading
+// from r.
+func (lw *BatchLineWriter) ReadUntilLine(r io.Reader, sentinel []byte, inclusive bool) (int64, error) {
+	var totalRead int64
+
+	lw.mu.Lock()
+	closed := lw.closed
+	lw.mu.Unlock()
+	if closed {
+		return 0, ErrClosed
+	}
+
+	sr, ok := r.(sliceReader)
+	if !ok {
+		sr = &byteSliceReader{r: r}
+	}
+
+	delim := lw.delim
+	atLineStart := true
+	var tail []byte // final bytes of the line so far, to match a split delimiter
+
+	for {
+		line, rerr := sr.ReadSlice(delim[len(delim)-1])
+		totalRead += int64(len(line))
+
+		isComplete := len(line) > 0 && endsWithDelim(tail, line, delim)
+		if isComplete {
+			tail = tail[:0]
+		} else {
+			tail = lineTail(tail, line, len(delim)-1)
+		}
+
+		if atLineStart && isComplete && len(line) >= len(delim) && bytes.Equal(line[:len(line)-len(delim)], sentinel) {
+			if inclusive {
+				if _, err := lw.Write(line); err != nil {
+					return totalRead, err
+				}
+			}
+			return totalRead, lw.FlushAll()
+		}
+
+		if len(line) > 0 {
+			if _, err := lw.Write(line); err != nil {
+				return totalRead, err
+			}
+		}
+		atLineStart = isComplete
+
+		if rerr == io.EOF {
+			return totalRead, io.ErrUnexpectedEOF
+		}
+		if rerr != nil && rerr != bufio.ErrBufferFull {
+			return totalRead, rerr
+		}
+	}
+}
+
+// endsWithDelim returns true when line, preceded by tail holding the
+// final bytes of the same line already read, ends with delim.
+func endsWithDelim(tail, line, delim []byte) bool {
+	if len(line) >= len(delim) {
+		return bytes.HasSuffix(line, delim)
+	}
+	return bytes.HasSuffix(append(tail, line...), delim)
+}
+
+// lineTail appends to tail the final bytes of line, returning at most
+// the final n bytes of both.
+func lineTail(tail, line []byte, n int) []byte {
+	if len(line) >= n {
+		return append(tail[:0], line[len(line)-n:]...)
+	}
+	tail = append(tail, line...)
+	if len(tail) > n {
+		tail = append(tail[:0], tail[len(tail)-n:]...)
+	}
+	return tail
+}
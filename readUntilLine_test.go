@@ -0,0 +1,122 @@
+package gonl
+
+import (
+	"bufio"
+	"io"
+	"strings"
+	"testing"
+)
+
+func TestReadUntilLine(t *testing.T) {
+	const input = "line 1\nline 2\n.\nline 3\n.\nline 4"
+
+	t.Run("exclusive", func(t *testing.T) {
+		r := strings.NewReader(input)
+		output := new(testBuffer)
+		lw, err := NewBatchLineWriter(output, 1024)
+		ensureErrorNil(t, err)
+
+		nr, err := lw.ReadUntilLine(r, []byte("."), false)
+		ensureErrorNil(t, err)
+		if got, want := nr, int64(16); got != want {
+			t.Errorf("GOT: %v; WANT: %v", got, want)
+		}
+		ensureStringer(t, output, "line 1\nline 2\n")
+
+		// Reader is left positioned just after the sentinel line.
+		rest, err := io.ReadAll(r)
+		ensureErrorNil(t, err)
+		if got, want := string(rest), "line 3\n.\nline 4"; got != want {
+			t.Errorf("GOT: %q; WANT: %q", got, want)
+		}
+	})
+
+	t.Run("inclusive", func(t *testing.T) {
+		r := strings.NewReader(input)
+		output := new(testBuffer)
+		lw, err := NewBatchLineWriter(output, 1024)
+		ensureErrorNil(t, err)
+
+		_, err = lw.ReadUntilLine(r, []byte("."), true)
+		ensureErrorNil(t, err)
+		ensureStringer(t, output, "line 1\nline 2\n.\n")
+	})
+
+	t.Run("bufio.Reader", func(t *testing.T) {
+		r := bufio.NewReaderSize(strings.NewReader(input), 16)
+		output := new(testBuffer)
+		lw, err := NewBatchLineWriter(output, 1024)
+		ensureErrorNil(t, err)
+
+		_, err = lw.ReadUntilLine(r, []byte("."), false)
+		ensureErrorNil(t, err)
+		ensureStringer(t, output, "line 1\nline 2\n")
+
+		_, err = lw.ReadUntilLine(r, []byte("."), false)
+		ensureErrorNil(t, err)
+		ensureStringer(t, output, "line 1\nline 2\nline 3\n")
+
+		_, err = lw.ReadUntilLine(r, []byte("."), false)
+		ensureError(t, err, io.ErrUnexpectedEOF.Error())
+
+		ensureErrorNil(t, lw.Close())
+		ensureStringer(t, output, "line 1\nline 2\nline 3\nline 4")
+	})
+
+	t.Run("sentinel only matches entire line", func(t *testing.T) {
+		// Line longer than bufio buffer ending with sentinel bytes
+		// must not match.
+		r := bufio.NewReaderSize(strings.NewReader("0123456789abcdef.\n.\n"), 16)
+		output := new(testBuffer)
+		lw, err := NewBatchLineWriter(output, 1024)
+		ensureErrorNil(t, err)
+
+		_, err = lw.ReadUntilLine(r, []byte("."), false)
+		ensureErrorNil(t, err)
+		ensureStringer(t, output, "0123456789abcdef.\n")
+	})
+
+	t.Run("CRLF", func(t *testing.T) {
+		r := strings.NewReader("line 1\nstill 1\r\n.\r\nline 2\r\n")
+		output := new(testBuffer)
+		lw, err := NewBatchLineWriterDelimBytes(output, 1024, crlf)
+		ensureErrorNil(t, err)
+
+		nr, err := lw.ReadUntilLine(r, []byte("."), false)
+		ensureErrorNil(t, err)
+		if got, want := nr, int64(19); got != want {
+			t.Errorf("GOT: %v; WANT: %v", got, want)
+		}
+		ensureStringer(t, output, "line 1\nstill 1\r\n")
+
+		rest, err := io.ReadAll(r)
+		ensureErrorNil(t, err)
+		if got, want := string(rest), "line 2\r\n"; got != want {
+			t.Errorf("GOT: %q; WANT: %q", got, want)
+		}
+	})
+
+	t.Run("CRLF split by bufio.Reader", func(t *testing.T) {
+		// The bufio buffer fills just after the CR, so the LF arrives
+		// in the next slice.
+		r := bufio.NewReaderSize(strings.NewReader("0123456789abcde\r\n.\r\n"), 16)
+		output := new(testBuffer)
+		lw, err := NewBatchLineWriterDelimBytes(output, 1024, crlf)
+		ensureErrorNil(t, err)
+
+		_, err = lw.ReadUntilLine(r, []byte("."), false)
+		ensureErrorNil(t, err)
+		ensureStringer(t, output, "0123456789abcde\r\n")
+	})
+
+	t.Run("read error", func(t *testing.T) {
+		r := &testReader{tuples: []tuple{
+			tuple{"line 1\n", io.ErrClosedPipe},
+		}}
+		lw, err := NewBatchLineWriter(new(testBuffer), 1024)
+		ensureErrorNil(t, err)
+
+		_, err = lw.ReadUntilLine(r, []byte("."), false)
+		ensureError(t, err, io.ErrClosedPipe.Error())
+	})
+}
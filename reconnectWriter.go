@@ -0,0 +1,204 @@
+package gonl
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"time"
+)
+
+// Default values used by a ReconnectWriter whose corresponding fields
+// are zero.
+const (
+	DefaultMinBackoff = 100 * time.Millisecond
+	DefaultMaxBackoff = 30 * time.Second
+	DefaultMaxPending = 1024 * 1024
+)
+
+// ReconnectWriter is an io.WriteCloser that writes to a connection
+// obtained from Dial, such as a TCP connection, and transparently
+// replaces the connection when it fails. While disconnected, it holds
+// the data written to it, up to MaxPending bytes, and sends that data
+// once reconnected. Failed attempts to dial are retried with
+// exponential backoff, between MinBackoff and MaxBackoff.
+//
+// Dialing is attempted only from Write and Close, and never while a
+// backoff period is in effect, so a Write never waits for more than a
+// single attempt to dial. Because a connection may fail after some of
+// the data written to it has been sent, the data sent to a new
+// connection begins with the start of the line that was interrupted,
+// so the line is sent complete, though its start may also have been
+// received over the failed connection.
+//
+// ReconnectWriter is intended to be wrapped by a BatchLineWriter, which
+// serializes calls to it, and which ensures each Write holds complete
+// lines. It is not safe for concurrent use by multiple goroutines.
+//
+//	rw := &gonl.ReconnectWriter{
+//		Dial: func() (io.WriteCloser, error) {
+//			return net.DialTimeout("tcp", addr, 5*time.Second)
+//		},
+//		MaxPending: 16 << 20,
+//	}
+//	lw, err := gonl.NewBatchLineWriterOptions(rw, gonl.WithFlushInterval(time.Second))
+type ReconnectWriter struct {
+	// Dial returns a new connection.
+	Dial func() (io.WriteCloser, error)
+
+	// MinBackoff is the delay before the first attempt to redial after
+	// dialing fails, which doubles after each further failure up to
+	// MaxBackoff. When zero, DefaultMinBackoff and DefaultMaxBackoff
+	// are used respectively.
+	MinBackoff, MaxBackoff time.Duration
+
+	// MaxPending is the largest number of bytes held while
+	// disconnected. When zero, DefaultMaxPending is used. A Write that
+	// would exceed it returns ErrBufferFull.
+	MaxPending int
+
+	// OnError, when not nil, is invoked with each error from dialing
+	// or writing to a connection, which ReconnectWriter otherwise
+	// handles without returning.
+	OnError func(err error)
+
+	conn     io.WriteCloser
+	pending  []byte // data not yet sent
+	backoff  time.Duration
+	nextDial time.Time
+	lastErr  error
+}
+
+// Close attempts to send any pending data, dialing once if necessary
+// regardless of backoff, then closes the connection. It returns an
+// error when pending data could not be sent.
+func (rw *ReconnectWriter) Close() error {
+	var err error
+	if len(rw.pending) > 0 {
+		rw.nextDial = time.Time{}
+		rw.drain()
+	}
+	if len(rw.pending) > 0 {
+		err = fmt.Errorf("gonl: %d bytes not sent: %w", len(rw.pending), rw.lastErr)
+		rw.pending = nil
+	}
+	if rw.conn != nil {
+		if cerr := rw.conn.Close(); err == nil {
+			err = cerr
+		}
+		rw.conn = nil
+	}
+	return err
+}
+
+// Connected returns true when ReconnectWriter has a connection that
+// has not failed.
+func (rw *ReconnectWriter) Connected() bool { return rw.conn != nil }
+
+// Pending returns the number of bytes held until a connection is
+// available.
+func (rw *ReconnectWriter) Pending() int { return len(rw.pending) }
+
+// Write sends p over the connection, dialing when necessary, or holds
+// p to be sent once connected. It returns len(p) in either case, or
+// fewer bytes along with ErrBufferFull when holding p would exceed
+// MaxPending.
+func (rw *ReconnectWriter) Write(p []byte) (int, error) {
+	rw.drain()
+
+	var n int
+	if rw.conn != nil && len(rw.pending) == 0 {
+		nw, err := rw.conn.Write(p)
+		if err == nil {
+			return len(p), nil
+		}
+		rw.disconnect(err)
+		n = lineStart(p, nw)
+	}
+
+	maxPending := rw.MaxPending
+	if maxPending <= 0 {
+		maxPending = DefaultMaxPending
+	}
+	if len(rw.pending)+len(p)-n > maxPending {
+		return n, ErrBufferFull
+	}
+	rw.pending = append(rw.pending, p[n:]...)
+	return len(p), nil
+}
+
+// drain dials when disconnected and not backing off, then sends any
+// pending data.
+func (rw *ReconnectWriter) drain() {
+	if rw.conn == nil {
+		if time.Now().Before(rw.nextDial) {
+			return
+		}
+		conn, err := rw.Dial()
+		if err != nil {
+			rw.dialFailed(err)
+			return
+		}
+		rw.conn = conn
+		rw.backoff = 0
+	}
+	if len(rw.pending) == 0 {
+		return
+	}
+	nw, err := rw.conn.Write(rw.pending)
+	if err != nil {
+		rw.disconnect(err)
+		rw.pending = rw.pending[:copy(rw.pending, rw.pending[lineStart(rw.pending, nw):])]
+		return
+	}
+	rw.pending = rw.pending[:0]
+}
+
+// dialFailed records a failure to dial, and begins the next backoff
+// period.
+func (rw *ReconnectWriter) dialFailed(err error) {
+	rw.report(err)
+	switch {
+	case rw.backoff == 0 && rw.MinBackoff > 0:
+		rw.backoff = rw.MinBackoff
+	case rw.backoff == 0:
+		rw.backoff = DefaultMinBackoff
+	default:
+		rw.backoff *= 2
+	}
+	maxBackoff := rw.MaxBackoff
+	if maxBackoff <= 0 {
+		maxBackoff = DefaultMaxBackoff
+	}
+	if rw.backoff > maxBackoff {
+		rw.backoff = maxBackoff
+	}
+	rw.nextDial = time.Now().Add(rw.backoff)
+}
+
+// disconnect closes the failed connection. The next Write dials again
+// without waiting.
+func (rw *ReconnectWriter) disconnect(err error) {
+	rw.report(err)
+	_ = rw.conn.Close()
+	rw.conn = nil
+}
+
+func (rw *ReconnectWriter) report(err error) {
+	rw.lastErr = err
+	if rw.OnError != nil {
+		rw.OnError(err)
+	}
+}
+
+// lineStart returns the index in p of the start of the line containing
+// the byte at index n, which is the index following the final newline
+// in p[:n], or 0 when there is none.
+func lineStart(p []byte, n int) int {
+	if n <= 0 {
+		return 0
+	}
+	if n > len(p) {
+		n = len(p)
+	}
+	return bytes.LastIndexByte(p[:n], '\n') + 1
+}
@@ -0,0 +1,117 @@
+package gonl
+
+import (
+	"errors"
+	"io"
+	"testing"
+	"time"
+)
+
+// testDialer returns connections from conns in order, returning an
+// error for each nil entry.
+type testDialer struct {
+	conns []io.WriteCloser
+	dials int
+}
+
+func (td *testDialer) dial() (io.WriteCloser, error) {
+	if len(td.conns) == 0 {
+		panic("unexpected dial")
+	}
+	conn := td.conns[0]
+	td.conns = td.conns[1:]
+	td.dials++
+	if conn == nil {
+		return nil, errors.New("test dial error")
+	}
+	return conn, nil
+}
+
+// failAfterWriter writes to WC until it has written n bytes, then
+// fails, writing only the bytes that fit.
+type failAfterWriter struct {
+	WC io.WriteCloser
+	n  int
+}
+
+func (fw *failAfterWriter) Close() error { return nil }
+
+func (fw *failAfterWriter) Write(p []byte) (int, error) {
+	if len(p) <= fw.n {
+		fw.n -= len(p)
+		return fw.WC.Write(p)
+	}
+	nw, _ := fw.WC.Write(p[:fw.n])
+	fw.n = 0
+	return nw, errors.New("test connection reset")
+}
+
+func TestReconnectWriter(t *testing.T) {
+	t.Run("reconnects and resends interrupted line", func(t *testing.T) {
+		first, second := new(testBuffer), new(testBuffer)
+		td := &testDialer{conns: []io.WriteCloser{&failAfterWriter{WC: first, n: 10}, second}}
+		var errs []error
+		rw := &ReconnectWriter{Dial: td.dial, OnError: func(err error) { errs = append(errs, err) }}
+
+		ensureWrite(t, rw, "line 1\n")
+		ensureWrite(t, rw, "line 2\nline 3\n") // fails after "lin"
+		if rw.Connected() {
+			t.Errorf("GOT: %v; WANT: %v", true, false)
+		}
+		if got, want := rw.Pending(), 14; got != want {
+			t.Errorf("GOT: %v; WANT: %v", got, want)
+		}
+		ensureWrite(t, rw, "line 4\n")
+		ensureErrorNil(t, rw.Close())
+
+		ensureStringer(t, first, "line 1\nlin")
+		ensureStringer(t, second, "line 2\nline 3\nline 4\n")
+		if got, want := len(errs), 1; got != want {
+			t.Errorf("GOT: %v; WANT: %v", got, want)
+		}
+	})
+
+	t.Run("backs off while dialing fails", func(t *testing.T) {
+		output := new(testBuffer)
+		td := &testDialer{conns: []io.WriteCloser{nil, nil, output}}
+		rw := &ReconnectWriter{Dial: td.dial, MinBackoff: time.Minute, MaxBackoff: 90 * time.Second}
+
+		ensureWrite(t, rw, "line 1\n") // first dial fails
+		ensureWrite(t, rw, "line 2\n") // backing off
+		if got, want := td.dials, 1; got != want {
+			t.Errorf("GOT: %v; WANT: %v", got, want)
+		}
+		if got, want := rw.backoff, time.Minute; got != want {
+			t.Errorf("GOT: %v; WANT: %v", got, want)
+		}
+
+		rw.nextDial = time.Time{}      // end the backoff period
+		ensureWrite(t, rw, "line 3\n") // second dial fails
+		if got, want := rw.backoff, 90*time.Second; got != want {
+			t.Errorf("GOT: %v; WANT: %v", got, want)
+		}
+
+		ensureErrorNil(t, rw.Close()) // dials regardless of backoff
+		ensureStringer(t, output, "line 1\nline 2\nline 3\n")
+	})
+
+	t.Run("bounded while disconnected", func(t *testing.T) {
+		td := &testDialer{conns: []io.WriteCloser{nil, nil}}
+		rw := &ReconnectWriter{Dial: td.dial, MinBackoff: time.Hour, MaxPending: 10}
+
+		ensureWrite(t, rw, "line 1\n")
+		n, err := rw.Write([]byte("line 2\n"))
+		if got, want := err, ErrBufferFull; got != want {
+			t.Errorf("GOT: %v; WANT: %v", got, want)
+		}
+		if got, want := n, 0; got != want {
+			t.Errorf("GOT: %v; WANT: %v", got, want)
+		}
+		ensureError(t, rw.Close(), "7 bytes not sent: test dial error")
+	})
+
+	t.Run("close without data does not dial", func(t *testing.T) {
+		rw := &ReconnectWriter{Dial: new(testDialer).dial}
+		ensureErrorNil(t, rw.Close())
+	})
+}
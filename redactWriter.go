@@ -0,0 +1,63 @@
+package gonl
+
+import (
+	"io"
+	"regexp"
+)
+
+// RedactWriter is an io.WriteCloser that replaces each match of
+// Pattern in every line with Replacement before writing the line to
+// the underlying io.WriteCloser, so sensitive values can be removed
+// from a stream without buffering more than a single line. Lines
+// split across multiple Write calls are reassembled before being
+// matched. The final line, when not terminated by a newline, is
+// redacted and written when the RedactWriter is closed.
+//
+// Pattern is matched against each line individually, excluding its
+// terminating newline. Redaction of a match that would span a line
+// boundary is intentionally not performed.
+//
+//	rw := &gonl.RedactWriter{
+//		WC:          os.Stdout,
+//		Pattern:     regexp.MustCompile(`\b(?:\d[ -]?){13,16}\b`),
+//		Replacement: []byte("[REDACTED]"),
+//	}
+type RedactWriter struct {
+	// WC is io.WriteCloser where data is ultimately written.
+	WC io.WriteCloser
+
+	// Pattern is the regular expression each line is searched for.
+	Pattern *regexp.Regexp
+
+	// Replacement is substituted for each match of Pattern. It is
+	// used literally, without expanding any `$` references.
+	Replacement []byte
+
+	la lineAssembler
+}
+
+// Close redacts and writes any remaining partial line, then closes the
+// underlying io.WriteCloser.
+func (rw *RedactWriter) Close() error {
+	if err := rw.la.close(rw.emit); err != nil {
+		_ = rw.WC.Close()
+		return err
+	}
+	return rw.WC.Close()
+}
+
+// Write redacts and writes each line completed by p to the underlying
+// io.WriteCloser, returning the number of bytes of p consumed.
+func (rw *RedactWriter) Write(p []byte) (int, error) {
+	return rw.la.write(p, rw.emit)
+}
+
+func (rw *RedactWriter) emit(line []byte) error {
+	body := trimNewline(line)
+	redacted := rw.Pattern.ReplaceAllLiteral(body, rw.Replacement)
+	if len(body) < len(line) {
+		redacted = append(redacted, '\n')
+	}
+	_, err := rw.WC.Write(redacted)
+	return err
+}
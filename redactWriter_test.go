@@ -0,0 +1,56 @@
+package gonl
+
+import (
+	"regexp"
+	"testing"
+)
+
+func TestRedactWriter(t *testing.T) {
+	newRedactWriter := func(output *testBuffer) *RedactWriter {
+		return &RedactWriter{
+			WC:          output,
+			Pattern:     regexp.MustCompile(`\d{4}-\d{4}`),
+			Replacement: []byte("[$0]"),
+		}
+	}
+
+	t.Run("redacts each line", func(t *testing.T) {
+		output := new(testBuffer)
+		rw := newRedactWriter(output)
+
+		ensureWrite(t, rw, "card 1234-5678 ok\nno match\n")
+		ensureStringer(t, output, "card [$0] ok\nno match\n")
+		ensureErrorNil(t, rw.Close())
+	})
+
+	t.Run("match split across writes", func(t *testing.T) {
+		output := new(testBuffer)
+		rw := newRedactWriter(output)
+
+		ensureWrite(t, rw, "card 12")
+		ensureWrite(t, rw, "34-5678\n4321-")
+		ensureStringer(t, output, "card [$0]\n")
+		ensureWrite(t, rw, "8765")
+		ensureErrorNil(t, rw.Close())
+		ensureStringer(t, output, "card [$0]\n[$0]")
+	})
+
+	t.Run("not across line boundaries", func(t *testing.T) {
+		output := new(testBuffer)
+		rw := newRedactWriter(output)
+
+		ensureWrite(t, rw, "1234\n-5678\n")
+		ensureErrorNil(t, rw.Close())
+		ensureStringer(t, output, "1234\n-5678\n")
+	})
+
+	t.Run("write error", func(t *testing.T) {
+		rw := &RedactWriter{WC: &errOnWrite{}, Pattern: regexp.MustCompile(`x`)}
+		n, err := rw.Write([]byte("a\n"))
+		ensureError(t, err, "test write error")
+		if got, want := n, 0; got != want {
+			t.Errorf("GOT: %v; WANT: %v", got, want)
+		}
+		ensureError(t, rw.Close(), "test close error")
+	})
+}
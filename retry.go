@@ -0,0 +1,93 @@
+package gonl
+
+import (
+	"errors"
+	"net"
+	"syscall"
+	"time"
+)
+
+// RetryPolicy determines how a BatchLineWriter retries a failed Write
+// to the underlying io.WriteCloser.
+type RetryPolicy struct {
+	// MaxRetries is the largest number of times a single flush is
+	// retried.
+	MaxRetries int
+
+	// MinBackoff is the delay before the first retry, which doubles
+	// before each further retry of the same flush, up to MaxBackoff.
+	// When zero, DefaultMinBackoff and DefaultMaxBackoff are used
+	// respectively.
+	MinBackoff, MaxBackoff time.Duration
+
+	// Retryable returns true when a Write that failed with err should
+	// be retried. When nil, IsTemporary is used.
+	Retryable func(err error) bool
+}
+
+// IsTemporary returns true when err is likely to be transient, so the
+// operation that returned it may succeed when retried: when it is
+// EAGAIN or EINTR, or an error whose Timeout or Temporary method
+// returns true, such as many errors from package net.
+// ErrWriteTimeout is also temporary, unless the BatchLineWriter has
+// stalled, in which case it is not retried.
+func IsTemporary(err error) bool {
+	if errors.Is(err, syscall.EAGAIN) || errors.Is(err, syscall.EINTR) {
+		return true
+	}
+	var ne interface {
+		Timeout() bool
+		Temporary() bool
+	}
+	if errors.As(err, &ne) {
+		return ne.Timeout() || ne.Temporary()
+	}
+	var te interface{ Timeout() bool }
+	return errors.As(err, &te) && te.Timeout()
+}
+
+// retry returns true when a Write that has failed with err after the
+// specified number of retries should be retried, after sleeping for the
+// backoff period.
+func (lw *BatchLineWriter) retry(attempt int, err error) bool {
+	p := lw.Retry
+	if p == nil || attempt >= p.MaxRetries || lw.stalled {
+		return false
+	}
+	retryable := p.Retryable
+	if retryable == nil {
+		retryable = IsTemporary
+	}
+	if !retryable(err) {
+		return false
+	}
+
+	backoff, maxBackoff := p.MinBackoff, p.MaxBackoff
+	if backoff <= 0 {
+		backoff = DefaultMinBackoff
+	}
+	if maxBackoff <= 0 {
+		maxBackoff = DefaultMaxBackoff
+	}
+	for i := 0; i < attempt && backoff < maxBackoff; i++ {
+		backoff *= 2
+	}
+	if backoff > maxBackoff {
+		backoff = maxBackoff
+	}
+	time.Sleep(backoff)
+	return true
+}
+
+// advanceBuffers returns what remains of bufs after its first n bytes
+// have been written.
+func advanceBuffers(bufs net.Buffers, n int64) net.Buffers {
+	for len(bufs) > 0 && n >= int64(len(bufs[0])) {
+		n -= int64(len(bufs[0]))
+		bufs = bufs[1:]
+	}
+	if len(bufs) > 0 && n > 0 {
+		bufs = append(net.Buffers{bufs[0][n:]}, bufs[1:]...)
+	}
+	return bufs
+}
@@ -0,0 +1,146 @@
+package gonl
+
+import (
+	"errors"
+	"net"
+	"os"
+	"syscall"
+	"testing"
+	"time"
+)
+
+// flakyWriter writes at most half of each of its first failures
+// Writes, then fails with err.
+type flakyWriter struct {
+	testBuffersWriter
+	failures int
+	err      error
+}
+
+func (fw *flakyWriter) Write(p []byte) (int, error) {
+	if fw.failures > 0 {
+		fw.failures--
+		fw.writes++
+		n, _ := fw.testBuffer.Write(p[:len(p)/2])
+		return n, fw.err
+	}
+	return fw.testBuffersWriter.Write(p)
+}
+
+func (fw *flakyWriter) WriteBuffers(bufs net.Buffers) (int64, error) {
+	if fw.failures > 0 {
+		fw.failures--
+		fw.bufferWrites++
+		n, _ := fw.testBuffer.Write(bufs[0][:len(bufs[0])/2])
+		return int64(n), fw.err
+	}
+	return fw.testBuffersWriter.WriteBuffers(bufs)
+}
+
+// flakyPlainWriter hides the WriteBuffers method of flakyWriter.
+type flakyPlainWriter struct {
+	fw *flakyWriter
+}
+
+func (pw flakyPlainWriter) Close() error                { return pw.fw.Close() }
+func (pw flakyPlainWriter) Write(p []byte) (int, error) { return pw.fw.Write(p) }
+
+func TestIsTemporary(t *testing.T) {
+	for _, err := range []error{syscall.EAGAIN, syscall.EINTR, ErrWriteTimeout, os.ErrDeadlineExceeded, &net.OpError{Op: "write", Err: syscall.EAGAIN}} {
+		if !IsTemporary(err) {
+			t.Errorf("GOT: false; WANT: true for %v", err)
+		}
+	}
+	for _, err := range []error{errors.New("permanent"), syscall.EPIPE, os.ErrClosed} {
+		if IsTemporary(err) {
+			t.Errorf("GOT: true; WANT: false for %v", err)
+		}
+	}
+}
+
+func TestRetry(t *testing.T) {
+	policy := RetryPolicy{MaxRetries: 3, MinBackoff: time.Nanosecond}
+
+	t.Run("partial write not duplicated", func(t *testing.T) {
+		output := &flakyWriter{failures: 2, err: syscall.EAGAIN}
+		lw, err := NewBatchLineWriterOptions(flakyPlainWriter{output}, WithFlushThreshold(1), WithRetry(policy))
+		ensureErrorNil(t, err)
+
+		ensureWrite(t, lw, "line 1\nline 2\n")
+		ensureStringer(t, output, "line 1\nline 2\n")
+		if got, want := output.writes, 3; got != want {
+			t.Errorf("GOT: %v; WANT: %v", got, want)
+		}
+		ensureErrorNil(t, lw.Close())
+	})
+
+	t.Run("vectored partial write not duplicated", func(t *testing.T) {
+		output := &flakyWriter{failures: 2, err: syscall.EAGAIN}
+		lw, err := NewBatchLineWriterOptions(output, WithFlushThreshold(8), WithRetry(policy))
+		ensureErrorNil(t, err)
+
+		ensureWrite(t, lw, "line ")
+		ensureWrite(t, lw, "1\nline 2\n")
+		ensureStringer(t, output, "line 1\nline 2\n")
+		if got, want := output.bufferWrites, 3; got != want {
+			t.Errorf("GOT: %v; WANT: %v", got, want)
+		}
+		ensureErrorNil(t, lw.Close())
+	})
+
+	t.Run("retries exhausted", func(t *testing.T) {
+		output := &flakyWriter{failures: 5, err: syscall.EAGAIN}
+		lw, err := NewBatchLineWriterOptions(flakyPlainWriter{output}, WithFlushThreshold(1), WithRetry(policy))
+		ensureErrorNil(t, err)
+
+		_, err = lw.Write([]byte("line 1\n"))
+		if !errors.Is(err, syscall.EAGAIN) {
+			t.Errorf("GOT: %v; WANT: %v", err, syscall.EAGAIN)
+		}
+		if got, want := output.writes, 4; got != want {
+			t.Errorf("GOT: %v; WANT: %v", got, want)
+		}
+	})
+
+	t.Run("not retryable", func(t *testing.T) {
+		output := &flakyWriter{failures: 1, err: syscall.EPIPE}
+		lw, err := NewBatchLineWriterOptions(flakyPlainWriter{output}, WithFlushThreshold(1), WithRetry(policy))
+		ensureErrorNil(t, err)
+
+		_, err = lw.Write([]byte("line 1\n"))
+		if !errors.Is(err, syscall.EPIPE) {
+			t.Errorf("GOT: %v; WANT: %v", err, syscall.EPIPE)
+		}
+		if got, want := output.writes, 1; got != want {
+			t.Errorf("GOT: %v; WANT: %v", got, want)
+		}
+	})
+
+	t.Run("custom predicate", func(t *testing.T) {
+		errBusy := errors.New("busy")
+		output := &flakyWriter{failures: 1, err: errBusy}
+		lw, err := NewBatchLineWriterOptions(flakyPlainWriter{output}, WithFlushThreshold(1), WithRetry(RetryPolicy{
+			MaxRetries: 1,
+			MinBackoff: time.Nanosecond,
+			Retryable:  func(err error) bool { return err == errBusy },
+		}))
+		ensureErrorNil(t, err)
+
+		ensureWrite(t, lw, "line 1\n")
+		ensureStringer(t, output, "line 1\n")
+		ensureErrorNil(t, lw.Close())
+	})
+}
+
+func TestAdvanceBuffers(t *testing.T) {
+	bufs := net.Buffers{[]byte("abc"), []byte("de"), []byte("f")}
+	for n, want := range []string{"abcdef", "bcdef", "cdef", "def", "ef", "f", ""} {
+		var got []byte
+		for _, buf := range advanceBuffers(bufs, int64(n)) {
+			got = append(got, buf...)
+		}
+		if string(got) != want {
+			t.Errorf("n=%d GOT: %q; WANT: %q", n, got, want)
+		}
+	}
+}
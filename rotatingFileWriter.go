@@ -0,0 +1,286 @@
+package gonl
+
+import (
+	"bytes"
+	"compress/gzip"
+	"io"
+	"os"
+	"time"
+)
+
+// RotatedTimeFormat is the layout of the timestamp appended to the name
+// of each file rotated by a RotatingFileWriter.
+const RotatedTimeFormat = "2006-01-02T15-04-05.000000000"
+
+// RotatingFileWriter is an io.WriteCloser that appends to the file at
+// Path, rotating it when it reaches MaxSize bytes, MaxLines lines, or
+// MaxAge. Rotation only ever occurs at a line boundary, so no file
+// starts or ends with a partial line: a trailing partial line written
+// to it is held until its newline arrives. A rotated file is renamed to
+// Path followed by a dot and the time of rotation formatted with
+// RotatedTimeFormat, and is optionally compressed.
+//
+// Each Write is checked against the limits, so writing to it through a
+// BatchLineWriter writes each batch with as few system calls as
+// possible. MaxAge is only checked by Write, so a file is not rotated
+// before more data is written to it. When the file at Path already
+// exists, it is appended to, with its existing size and lines counted
+// towards the limits.
+//
+//	rw := &gonl.RotatingFileWriter{Path: "app.log", MaxSize: 100 << 20, Compress: true}
+//	lw, err := gonl.NewBatchLineWriter(rw, 64*1024)
+type RotatingFileWriter struct {
+	// Path is the name of the file written to.
+	Path string
+
+	// MaxSize, when greater than 0, is the largest number of bytes
+	// written to a file before it is rotated. A single line longer than
+	// MaxSize is written to a file of its own.
+	MaxSize int64
+
+	// MaxLines, when greater than 0, is the largest number of lines
+	// written to a file before it is rotated.
+	MaxLines int64
+
+	// MaxAge, when greater than 0, is the longest duration after a
+	// file is opened before it is rotated.
+	MaxAge time.Duration
+
+	// Compress, when set, causes each rotated file to be compressed
+	// with gzip, adding a ".gz" extension to its name. Compression
+	// happens during the Write that causes the rotation.
+	Compress bool
+
+	// Perm is the permission bits of each file created. When zero,
+	// 0644 is used.
+	Perm os.FileMode
+
+	f       *os.File
+	size    int64
+	lines   int64
+	opened  time.Time
+	rotated time.Time // time of the previous rotation
+	partial []byte    // trailing partial line
+}
+
+// Close writes any partial line held, then closes the file.
+func (rw *RotatingFileWriter) Close() error {
+	if rw.f == nil {
+		if len(rw.partial) == 0 {
+			return nil
+		}
+		if err := rw.open(); err != nil {
+			return err
+		}
+	}
+	var err error
+	if len(rw.partial) > 0 {
+		_, err = rw.f.Write(rw.partial)
+		rw.partial = nil
+	}
+	if cerr := rw.f.Close(); err == nil {
+		err = cerr
+	}
+	rw.f = nil
+	return err
+}
+
+// Rotate rotates the file, such as upon receiving a signal from an
+// external log rotation tool. Unlike automatic rotation, the file is
+// rotated even when it is empty, but a partial line held remains held
+// for the new file.
+func (rw *RotatingFileWriter) Rotate() error {
+	if rw.f == nil {
+		if err := rw.open(); err != nil {
+			return err
+		}
+	}
+	return rw.rotate()
+}
+
+// Write writes the lines completed by p to the file, rotating as
+// needed, and holds any trailing partial line. It returns the number of
+// bytes of p consumed.
+func (rw *RotatingFileWriter) Write(p []byte) (int, error) {
+	if rw.f == nil {
+		if err := rw.open(); err != nil {
+			return 0, err
+		}
+	}
+	if rw.MaxAge > 0 && rw.size > 0 && time.Since(rw.opened) >= rw.MaxAge {
+		if err := rw.rotate(); err != nil {
+			return 0, err
+		}
+	}
+
+	var n int
+	if len(rw.partial) > 0 {
+		i := bytes.IndexByte(p, '\n')
+		if i < 0 {
+			rw.partial = append(rw.partial, p...)
+			return len(p), nil
+		}
+		rw.partial = append(rw.partial, p[:i+1]...)
+		if err := rw.writeLines(rw.partial); err != nil {
+			rw.partial = rw.partial[:len(rw.partial)-(i+1)]
+			return 0, err
+		}
+		rw.partial = rw.partial[:0]
+		n = i + 1
+	}
+
+	end := bytes.LastIndexByte(p[n:], '\n') + 1 + n
+	if err := rw.writeLines(p[n:end]); err != nil {
+		return n, err
+	}
+	rw.partial = append(rw.partial, p[end:]...)
+	return len(p), nil
+}
+
+// writeLines writes b, which consists of complete lines, to the file,
+// rotating before each line that would exceed a limit.
+func (rw *RotatingFileWriter) writeLines(b []byte) error {
+	for len(b) > 0 {
+		end := len(b)
+		var count int64
+		if rw.MaxLines > 0 || (rw.MaxSize > 0 && rw.size+int64(len(b)) > rw.MaxSize) {
+			end, count = rw.fit(b)
+			if end == 0 {
+				if err := rw.rotate(); err != nil {
+					return err
+				}
+				continue
+			}
+		}
+
+		nw, err := rw.f.Write(b[:end])
+		rw.size += int64(nw)
+		if err != nil {
+			return err
+		}
+		if rw.MaxLines > 0 {
+			rw.lines += count
+		}
+		b = b[end:]
+	}
+	return nil
+}
+
+// fit returns the number of bytes and lines from the start of b that
+// may be written to the file without exceeding a limit. The first line
+// written to an empty file always fits.
+func (rw *RotatingFileWriter) fit(b []byte) (int, int64) {
+	var end int
+	var count int64
+	for end < len(b) {
+		if rw.MaxLines > 0 && rw.lines+count >= rw.MaxLines {
+			break
+		}
+		next := end + bytes.IndexByte(b[end:], '\n') + 1
+		if rw.MaxSize > 0 && rw.size+int64(next) > rw.MaxSize && (rw.size > 0 || end > 0) {
+			break
+		}
+		end = next
+		count++
+	}
+	return end, count
+}
+
+// open opens the file at Path for appending, counting its existing
+// size and lines.
+func (rw *RotatingFileWriter) open() error {
+	perm := rw.Perm
+	if perm == 0 {
+		perm = 0644
+	}
+	f, err := os.OpenFile(rw.Path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, perm)
+	if err != nil {
+		return err
+	}
+	rw.f, rw.size, rw.lines, rw.opened = f, 0, 0, time.Now()
+
+	if fi, err := f.Stat(); err == nil {
+		rw.size = fi.Size()
+	}
+	if rw.MaxLines > 0 && rw.size > 0 {
+		lines, err := countFileLines(rw.Path)
+		if err != nil {
+			_ = f.Close()
+			rw.f = nil
+			return err
+		}
+		rw.lines = lines
+	}
+	return nil
+}
+
+// rotate closes and renames the file, compressing it when Compress is
+// set, then opens a new file at Path.
+func (rw *RotatingFileWriter) rotate() error {
+	if err := rw.f.Close(); err != nil {
+		return err
+	}
+	rw.f = nil
+
+	// Ensure each rotated file has a distinct name, even when the clock
+	// is coarse.
+	now := time.Now()
+	if !now.After(rw.rotated) {
+		now = rw.rotated.Add(time.Nanosecond)
+	}
+	rw.rotated = now
+
+	rotated := rw.Path + "." + now.Format(RotatedTimeFormat)
+	if err := os.Rename(rw.Path, rotated); err != nil {
+		return err
+	}
+	if rw.Compress {
+		if err := gzipFile(rotated, rw.Perm); err != nil {
+			return err
+		}
+	}
+	return rw.open()
+}
+
+// countFileLines returns the number of lines in the file at path.
+func countFileLines(path string) (int64, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return 0, err
+	}
+	defer f.Close()
+
+	lines, err := NewlineCounter(f)
+	return int64(lines), err
+}
+
+// gzipFile compresses the file at path to a new file with its name and
+// a ".gz" extension, then removes the original.
+func gzipFile(path string, perm os.FileMode) error {
+	if perm == 0 {
+		perm = 0644
+	}
+	src, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer src.Close()
+
+	dst, err := os.OpenFile(path+".gz", os.O_CREATE|os.O_WRONLY|os.O_TRUNC, perm)
+	if err != nil {
+		return err
+	}
+	zw := gzip.NewWriter(dst)
+	_, err = io.Copy(zw, src)
+	if cerr := zw.Close(); err == nil {
+		err = cerr
+	}
+	if cerr := dst.Close(); err == nil {
+		err = cerr
+	}
+	if err != nil {
+		_ = os.Remove(path + ".gz")
+		return err
+	}
+	return os.Remove(path)
+}
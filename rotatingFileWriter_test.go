@@ -0,0 +1,130 @@
+package gonl
+
+import (
+	"compress/gzip"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"testing"
+	"time"
+)
+
+// rotatedFiles returns the contents of the files rotated from path, in
+// order of rotation, decompressing those with a ".gz" extension.
+func rotatedFiles(t *testing.T, path string) []string {
+	t.Helper()
+	names, err := filepath.Glob(path + ".*")
+	ensureErrorNil(t, err)
+	sort.Strings(names)
+
+	var contents []string
+	for _, name := range names {
+		f, err := os.Open(name)
+		ensureErrorNil(t, err)
+		var buf []byte
+		if strings.HasSuffix(name, ".gz") {
+			zr, err := gzip.NewReader(f)
+			ensureErrorNil(t, err)
+			buf, err = ioutil.ReadAll(zr)
+			ensureErrorNil(t, err)
+		} else {
+			buf, err = ioutil.ReadAll(f)
+			ensureErrorNil(t, err)
+		}
+		ensureErrorNil(t, f.Close())
+		contents = append(contents, string(buf))
+	}
+	return contents
+}
+
+func ensureFile(t *testing.T, path, want string) {
+	t.Helper()
+	buf, err := ioutil.ReadFile(path)
+	ensureErrorNil(t, err)
+	if got := string(buf); got != want {
+		t.Errorf("GOT: %q; WANT: %q", got, want)
+	}
+}
+
+func TestRotatingFileWriter(t *testing.T) {
+	t.Run("max lines", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "app.log")
+		rw := &RotatingFileWriter{Path: path, MaxLines: 2}
+
+		ensureWrite(t, rw, "line 1\nline 2\nline 3\nli")
+		ensureWrite(t, rw, "ne 4\nline 5\nline 6")
+		ensureErrorNil(t, rw.Close())
+
+		ensureWrites(t, rotatedFiles(t, path), []string{"line 1\nline 2\n", "line 3\nline 4\n"})
+		ensureFile(t, path, "line 5\nline 6")
+	})
+
+	t.Run("max size", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "app.log")
+		rw := &RotatingFileWriter{Path: path, MaxSize: 16}
+
+		ensureWrite(t, rw, "line 1\nli")
+		ensureWrite(t, rw, "ne 2\nline 3\n")
+		ensureWrite(t, rw, "a line longer than MaxSize\nline 4\n")
+		ensureErrorNil(t, rw.Close())
+
+		ensureWrites(t, rotatedFiles(t, path), []string{"line 1\nline 2\n", "line 3\n", "a line longer than MaxSize\n"})
+		ensureFile(t, path, "line 4\n")
+	})
+
+	t.Run("max age", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "app.log")
+		rw := &RotatingFileWriter{Path: path, MaxAge: time.Hour}
+
+		ensureWrite(t, rw, "line 1\n")
+		rw.opened = rw.opened.Add(-time.Hour)
+		ensureWrite(t, rw, "line 2\n")
+		ensureErrorNil(t, rw.Close())
+
+		ensureWrites(t, rotatedFiles(t, path), []string{"line 1\n"})
+		ensureFile(t, path, "line 2\n")
+	})
+
+	t.Run("compress", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "app.log")
+		rw := &RotatingFileWriter{Path: path, MaxLines: 1, Compress: true}
+
+		ensureWrite(t, rw, "line 1\nline 2\n")
+		ensureErrorNil(t, rw.Close())
+
+		names, err := filepath.Glob(path + ".*.gz")
+		ensureErrorNil(t, err)
+		if got, want := len(names), 1; got != want {
+			t.Fatalf("GOT: %v; WANT: %v", got, want)
+		}
+		ensureWrites(t, rotatedFiles(t, path), []string{"line 1\n"})
+		ensureFile(t, path, "line 2\n")
+	})
+
+	t.Run("appends to existing file", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "app.log")
+		ensureErrorNil(t, ioutil.WriteFile(path, []byte("line 1\n"), 0644))
+		rw := &RotatingFileWriter{Path: path, MaxLines: 2}
+
+		ensureWrite(t, rw, "line 2\nline 3\n")
+		ensureErrorNil(t, rw.Close())
+
+		ensureWrites(t, rotatedFiles(t, path), []string{"line 1\nline 2\n"})
+		ensureFile(t, path, "line 3\n")
+	})
+
+	t.Run("rotate", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "app.log")
+		rw := &RotatingFileWriter{Path: path}
+
+		ensureWrite(t, rw, "line 1\nli")
+		ensureErrorNil(t, rw.Rotate())
+		ensureWrite(t, rw, "ne 2\n")
+		ensureErrorNil(t, rw.Close())
+
+		ensureWrites(t, rotatedFiles(t, path), []string{"line 1\n"})
+		ensureFile(t, path, "line 2\n")
+	})
+}
@@ -0,0 +1,77 @@
+package gonl
+
+import (
+	"io"
+)
+
+// SegmentWriter is an io.WriteCloser that splits the lines written to
+// it across a sequence of size bounded segments, such as the parts of
+// a chunked upload to an object store, without ever splitting a line
+// across segments.
+//
+// Each complete line is written to the current segment, unless adding
+// it would cause the segment to exceed MaxBytes, in which case the
+// current segment is closed and the next one is opened by calling
+// NewSegment before writing the line. A single line longer than
+// MaxBytes is written by itself to its own segment, which therefore
+// exceeds MaxBytes. Segments are opened lazily, so no empty segment is
+// ever created. When the SegmentWriter is closed, any final line not
+// terminated by a newline is written, and the final segment is
+// closed.
+type SegmentWriter struct {
+	// NewSegment is invoked to open each segment, with index
+	// starting at 0 and incremented for each segment.
+	NewSegment func(index int) (io.WriteCloser, error)
+
+	// MaxBytes is the maximum number of bytes written to each
+	// segment, except when a single line is longer.
+	MaxBytes int
+
+	la      lineAssembler
+	segment io.WriteCloser
+	index   int
+	written int
+}
+
+// Close writes any remaining partial line, then closes the final
+// segment.
+func (sw *SegmentWriter) Close() error {
+	err := sw.la.close(sw.emit)
+	if sw.segment != nil {
+		if cerr := sw.segment.Close(); err == nil {
+			err = cerr
+		}
+		sw.segment = nil
+	}
+	return err
+}
+
+// Write writes each line completed by p to the appropriate segment,
+// returning the number of bytes of p consumed.
+func (sw *SegmentWriter) Write(p []byte) (int, error) {
+	return sw.la.write(p, sw.emit)
+}
+
+func (sw *SegmentWriter) emit(line []byte) error {
+	if sw.segment != nil && sw.written > 0 && sw.written+len(line) > sw.MaxBytes {
+		err := sw.segment.Close()
+		sw.segment = nil
+		if err != nil {
+			return err
+		}
+	}
+
+	if sw.segment == nil {
+		segment, err := sw.NewSegment(sw.index)
+		if err != nil {
+			return err
+		}
+		sw.segment = segment
+		sw.index++
+		sw.written = 0
+	}
+
+	nw, err := sw.segment.Write(line)
+	sw.written += nw
+	return err
+}
@@ -0,0 +1,57 @@
+package gonl
+
+import (
+	"errors"
+	"io"
+	"testing"
+)
+
+func TestSegmentWriter(t *testing.T) {
+	var segments []*testBuffer
+	newSegment := func(index int) (io.WriteCloser, error) {
+		if got, want := index, len(segments); got != want {
+			t.Errorf("GOT: %v; WANT: %v", got, want)
+		}
+		segment := new(testBuffer)
+		segments = append(segments, segment)
+		return segment, nil
+	}
+
+	t.Run("splits on line boundaries", func(t *testing.T) {
+		segments = nil
+		sw := &SegmentWriter{NewSegment: newSegment, MaxBytes: 16}
+
+		ensureWrite(t, sw, "line 1\nline 2\nli")
+		ensureWrite(t, sw, "ne 3\nthis line is too long\nline 5")
+		ensureErrorNil(t, sw.Close())
+
+		want := []string{"line 1\nline 2\n", "line 3\n", "this line is too long\n", "line 5"}
+		if got, want := len(segments), len(want); got != want {
+			t.Fatalf("GOT: %v; WANT: %v", got, want)
+		}
+		for i := range want {
+			ensureStringer(t, segments[i], want[i])
+		}
+	})
+
+	t.Run("no segment when nothing written", func(t *testing.T) {
+		segments = nil
+		sw := &SegmentWriter{NewSegment: newSegment, MaxBytes: 16}
+		ensureErrorNil(t, sw.Close())
+		if got, want := len(segments), 0; got != want {
+			t.Errorf("GOT: %v; WANT: %v", got, want)
+		}
+	})
+
+	t.Run("NewSegment error", func(t *testing.T) {
+		sw := &SegmentWriter{
+			NewSegment: func(int) (io.WriteCloser, error) { return nil, errors.New("cannot open") },
+			MaxBytes:   16,
+		}
+		n, err := sw.Write([]byte("line 1\n"))
+		ensureError(t, err, "cannot open")
+		if got, want := n, 0; got != want {
+			t.Errorf("GOT: %v; WANT: %v", got, want)
+		}
+	})
+}
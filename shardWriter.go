@@ -0,0 +1,96 @@
+package gonl
+
+import (
+	"hash/fnv"
+	"io"
+)
+
+// ShardWriter is an io.WriteCloser that distributes complete lines
+// across multiple underlying io.WriteCloser instances, writing each
+// line to exactly one of them with a single Write call, such as when
+// spreading NDJSON ingest load across several downstream workers.
+// Lines split across multiple Write calls are reassembled before being
+// written. The final line, when not terminated by a newline, is
+// written when the ShardWriter is closed.
+type ShardWriter struct {
+	// WCs are the io.WriteCloser instances where lines are written.
+	// It must not be empty.
+	WCs []io.WriteCloser
+
+	// Shard, when not nil, is invoked with each line, excluding its
+	// terminating newline, and returns the index into WCs of the
+	// destination for that line. Negative results and results not
+	// less than len(WCs) are reduced modulo len(WCs). When nil, lines
+	// are distributed in round-robin fashion.
+	Shard func(line []byte) int
+
+	la   lineAssembler
+	next int // index of the next round-robin destination
+}
+
+// NewRoundRobinWriter returns a ShardWriter that writes each line to
+// the next one of wcs in turn, starting again with the first after the
+// last.
+func NewRoundRobinWriter(wcs ...io.WriteCloser) *ShardWriter {
+	return &ShardWriter{WCs: append([]io.WriteCloser(nil), wcs...)}
+}
+
+// NewHashShardWriter returns a ShardWriter that writes each line to
+// one of wcs chosen by the FNV-1a hash of its key, so all lines with
+// the same key land on the same destination. The key of a line is
+// returned by key, which is invoked with the line excluding its
+// terminating newline, and may return a subslice of it, such as a
+// field identifying the record. When key is nil the entire line is
+// used as its key.
+func NewHashShardWriter(key func(line []byte) []byte, wcs ...io.WriteCloser) *ShardWriter {
+	h := fnv.New32a()
+	return &ShardWriter{
+		WCs: append([]io.WriteCloser(nil), wcs...),
+		Shard: func(line []byte) int {
+			if key != nil {
+				line = key(line)
+			}
+			h.Reset()
+			_, _ = h.Write(line)
+			return int(h.Sum32() % uint32(len(wcs)))
+		},
+	}
+}
+
+// Close writes any remaining partial line, then closes every
+// underlying io.WriteCloser, returning the first error encountered.
+func (sw *ShardWriter) Close() error {
+	err := sw.la.close(sw.emit)
+	for _, wc := range sw.WCs {
+		if cerr := wc.Close(); err == nil {
+			err = cerr
+		}
+	}
+	return err
+}
+
+// Write writes each line completed by p to its destination. When a
+// destination returns an error, Write returns the number of bytes of p
+// preceding the line that could not be written along with that error.
+func (sw *ShardWriter) Write(p []byte) (int, error) {
+	return sw.la.write(p, sw.emit)
+}
+
+func (sw *ShardWriter) emit(line []byte) error {
+	var index int
+	if sw.Shard != nil {
+		index = sw.Shard(trimNewline(line)) % len(sw.WCs)
+		if index < 0 {
+			index += len(sw.WCs)
+		}
+	} else {
+		index = sw.next
+	}
+	if _, err := sw.WCs[index].Write(line); err != nil {
+		return err
+	}
+	if sw.Shard == nil {
+		sw.next = (index + 1) % len(sw.WCs)
+	}
+	return nil
+}
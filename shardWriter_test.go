@@ -0,0 +1,85 @@
+package gonl
+
+import (
+	"bytes"
+	"io"
+	"strings"
+	"testing"
+)
+
+func TestShardWriter(t *testing.T) {
+	t.Run("round robin", func(t *testing.T) {
+		shards := []*testBuffer{new(testBuffer), new(testBuffer), new(testBuffer)}
+		sw := NewRoundRobinWriter(shards[0], shards[1], shards[2])
+
+		ensureWrite(t, sw, "line 1\nline 2\nli")
+		ensureWrite(t, sw, "ne 3\nline 4\nline 5")
+		ensureErrorNil(t, sw.Close())
+
+		ensureStringer(t, shards[0], "line 1\nline 4\n")
+		ensureStringer(t, shards[1], "line 2\nline 5")
+		ensureStringer(t, shards[2], "line 3\n")
+	})
+
+	t.Run("shard function", func(t *testing.T) {
+		shards := []*testBuffer{new(testBuffer), new(testBuffer)}
+		sw := &ShardWriter{
+			WCs:   []io.WriteCloser{shards[0], shards[1]},
+			Shard: func(line []byte) int { return -len(line) },
+		}
+
+		ensureWrite(t, sw, "a\nbb\nccc\n")
+		ensureErrorNil(t, sw.Close())
+
+		ensureStringer(t, shards[0], "bb\n")
+		ensureStringer(t, shards[1], "a\nccc\n")
+	})
+
+	t.Run("write error retries same destination", func(t *testing.T) {
+		first := new(testBuffer)
+		sw := NewRoundRobinWriter(first, &errOnWrite{})
+
+		n, err := sw.Write([]byte("line 1\nline 2\n"))
+		ensureError(t, err, "test write error")
+		if got, want := n, 7; got != want {
+			t.Errorf("GOT: %v; WANT: %v", got, want)
+		}
+		if got, want := sw.next, 1; got != want {
+			t.Errorf("GOT: %v; WANT: %v", got, want)
+		}
+		ensureStringer(t, first, "line 1\n")
+		ensureError(t, sw.Close(), "test close error")
+	})
+}
+
+func TestNewHashShardWriter(t *testing.T) {
+	shards := []*testBuffer{new(testBuffer), new(testBuffer), new(testBuffer)}
+	key := func(line []byte) []byte {
+		if i := bytes.IndexByte(line, ' '); i >= 0 {
+			return line[:i]
+		}
+		return line
+	}
+	sw := NewHashShardWriter(key, shards[0], shards[1], shards[2])
+
+	ensureWrite(t, sw, "alpha 1\nbravo 1\ncharlie 1\nalpha 2\nbra")
+	ensureWrite(t, sw, "vo 2\ncharlie 2\n")
+	ensureErrorNil(t, sw.Close())
+
+	// Every line with the same key is written to the same shard.
+	for _, k := range []string{"alpha", "bravo", "charlie"} {
+		var found int
+		for _, shard := range shards {
+			lines := strings.Count(shard.String(), k+" ")
+			if lines > 0 {
+				found++
+				if got, want := lines, 2; got != want {
+					t.Errorf("%s: GOT: %v; WANT: %v", k, got, want)
+				}
+			}
+		}
+		if got, want := found, 1; got != want {
+			t.Errorf("%s: GOT: %v; WANT: %v", k, got, want)
+		}
+	}
+}
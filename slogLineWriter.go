@@ -0,0 +1,47 @@
+//go:build go1.21
+// +build go1.21
+
+package gonl
+
+import (
+	"context"
+	"log/slog"
+)
+
+// SlogLineWriter is an io.WriteCloser that logs each complete line
+// written to it as an individual log/slog record, with the line,
+// excluding its terminating newline, as the message. Lines split
+// across multiple Write calls are reassembled before being logged.
+// When closed, any final line not terminated by a newline is logged.
+//
+// This allows placing a line writer in front of code that writes text
+// and obtaining structured logs from it.
+//
+//	w := &gonl.SlogLineWriter{Logger: slog.Default(), Level: slog.LevelInfo}
+//	cmd.Stderr = w
+type SlogLineWriter struct {
+	// Logger is the logger to which records are emitted.
+	Logger *slog.Logger
+
+	// Level is the level of each emitted record.
+	Level slog.Level
+
+	la lineAssembler
+}
+
+// Close logs any remaining partial line. It does not close the
+// Logger.
+func (sw *SlogLineWriter) Close() error {
+	return sw.la.close(sw.emit)
+}
+
+// Write logs each line completed by p. It always returns len(p) and a
+// nil error.
+func (sw *SlogLineWriter) Write(p []byte) (int, error) {
+	return sw.la.write(p, sw.emit)
+}
+
+func (sw *SlogLineWriter) emit(line []byte) error {
+	sw.Logger.Log(context.Background(), sw.Level, string(trimNewline(line)))
+	return nil
+}
@@ -0,0 +1,35 @@
+//go:build go1.21
+// +build go1.21
+
+package gonl
+
+import (
+	"bytes"
+	"log/slog"
+	"testing"
+)
+
+func TestSlogLineWriter(t *testing.T) {
+	bb := new(bytes.Buffer)
+	handler := slog.NewTextHandler(bb, &slog.HandlerOptions{
+		Level: slog.LevelDebug,
+		ReplaceAttr: func(groups []string, a slog.Attr) slog.Attr {
+			if a.Key == slog.TimeKey && len(groups) == 0 {
+				return slog.Attr{} // omit time for deterministic output
+			}
+			return a
+		},
+	})
+	sw := &SlogLineWriter{Logger: slog.New(handler), Level: slog.LevelWarn}
+
+	ensureWrite(t, sw, "line 1\nli")
+	ensureWrite(t, sw, "ne 2\nline 3")
+	if got, want := bb.String(), "level=WARN msg=\"line 1\"\nlevel=WARN msg=\"line 2\"\n"; got != want {
+		t.Errorf("GOT: %q; WANT: %q", got, want)
+	}
+
+	ensureErrorNil(t, sw.Close())
+	if got, want := bb.String(), "level=WARN msg=\"line 1\"\nlevel=WARN msg=\"line 2\"\nlevel=WARN msg=\"line 3\"\n"; got != want {
+		t.Errorf("GOT: %q; WANT: %q", got, want)
+	}
+}
@@ -0,0 +1,128 @@
+package gonl
+
+import (
+	"bytes"
+	"sync/atomic"
+)
+
+// Stats describes the data a writer has emitted to its underlying
+// io.WriteCloser since it was created.
+type Stats struct {
+	// Bytes is the number of bytes written to the underlying
+	// io.WriteCloser.
+	Bytes int64
+
+	// Lines is the number of delimiter terminated lines written to
+	// the underlying io.WriteCloser. A final line written by Close
+	// without a delimiter is not counted.
+	Lines int64
+
+	// Flushes is the number of Write calls made to the underlying
+	// io.WriteCloser.
+	Flushes int64
+
+	// Errors is the number of errors returned by the underlying
+	// io.WriteCloser.
+	Errors int64
+
+	// MaxBuffered is the largest number of bytes the writer has held
+	// in its internal buffer at one time.
+	MaxBuffered int64
+}
+
+// writerCounters holds counters describing the data a writer has
+// emitted to its underlying io.WriteCloser. Its fields are accessed
+// atomically, because they may be read concurrently with writes.
+type writerCounters struct {
+	bytes       int64
+	lines       int64
+	flushes     int64
+	errors      int64
+	maxBuffered int64
+}
+
+// addError counts an error returned by the underlying io.WriteCloser.
+func (c *writerCounters) addError() { atomic.AddInt64(&c.errors, 1) }
+
+// addWrite counts a single Write to the underlying io.WriteCloser,
+// which wrote nw bytes completing the specified number of lines.
+func (c *writerCounters) addWrite(nw, lines int) {
+	atomic.AddInt64(&c.flushes, 1)
+	atomic.AddInt64(&c.bytes, int64(nw))
+	atomic.AddInt64(&c.lines, int64(lines))
+}
+
+// noteBuffered records that n bytes are held in the internal buffer.
+func (c *writerCounters) noteBuffered(n int) {
+	for {
+		max := atomic.LoadInt64(&c.maxBuffered)
+		if int64(n) <= max || atomic.CompareAndSwapInt64(&c.maxBuffered, max, int64(n)) {
+			return
+		}
+	}
+}
+
+// stats returns a snapshot of the counters.
+func (c *writerCounters) stats() Stats {
+	return Stats{
+		Bytes:       atomic.LoadInt64(&c.bytes),
+		Lines:       atomic.LoadInt64(&c.lines),
+		Flushes:     atomic.LoadInt64(&c.flushes),
+		Errors:      atomic.LoadInt64(&c.errors),
+		MaxBuffered: atomic.LoadInt64(&c.maxBuffered),
+	}
+}
+
+// Stats returns counters describing the data the BatchLineWriter has
+// written to the underlying io.WriteCloser. Counting is always
+// enabled, so the statistics are available without wrapping the
+// underlying io.WriteCloser, and Stats may be called concurrently with
+// writes. A BatchLineWriter obtained from a BatchLineWriterPool starts
+// with zero counters.
+func (lw *BatchLineWriter) Stats() Stats { return lw.counters.stats() }
+
+// Stats returns counters describing the data the PerLineWriter has
+// written to the underlying io.WriteCloser. Counting is always
+// enabled, so the statistics are available without wrapping the
+// underlying io.WriteCloser.
+func (lw *PerLineWriter) Stats() Stats { return lw.counters.stats() }
+
+// countWrite updates the counters, and invokes OnFlush when not nil,
+// after a single Write to the underlying io.WriteCloser of the bytes
+// in bufs, of which nw were written. It returns the number of lines
+// written.
+func (lw *BatchLineWriter) countWrite(nw int, bufs ...[]byte) int {
+	if nw < 0 {
+		return 0
+	}
+	n := nw
+	var lines int
+	var prev []byte
+	for _, buf := range bufs {
+		if nw < len(buf) {
+			buf = buf[:nw]
+		}
+		lines += bytes.Count(buf, lw.delim)
+		if len(lw.delim) > 1 && straddlesDelim(prev, buf, lw.delim) {
+			lines++
+		}
+		prev = buf
+		nw -= len(buf)
+	}
+	lw.counters.addWrite(n, lines)
+	if lw.OnFlush != nil {
+		lw.OnFlush(n, lines)
+	}
+	return lines
+}
+
+// straddlesDelim returns true when a multi-byte delim begins at the end
+// of a and ends at the start of b.
+func straddlesDelim(a, b, delim []byte) bool {
+	for k := 1; k < len(delim); k++ {
+		if bytes.HasSuffix(a, delim[:k]) && bytes.HasPrefix(b, delim[k:]) {
+			return true
+		}
+	}
+	return false
+}
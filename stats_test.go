@@ -0,0 +1,112 @@
+package gonl
+
+import (
+	"io"
+	"testing"
+)
+
+func ensureStats(t *testing.T, got, want Stats) {
+	t.Helper()
+	if got != want {
+		t.Errorf("GOT: %+v; WANT: %+v", got, want)
+	}
+}
+
+func TestStats(t *testing.T) {
+	t.Run("BatchLineWriter", func(t *testing.T) {
+		output := new(testBuffer)
+		lw, err := NewBatchLineWriter(output, 8)
+		ensureErrorNil(t, err)
+
+		ensureWrite(t, lw, "line 1\nline 2\nline 3")
+		ensureStats(t, lw.Stats(), Stats{Bytes: 14, Lines: 2, Flushes: 1, MaxBuffered: 20})
+
+		ensureErrorNil(t, lw.Close())
+		ensureStats(t, lw.Stats(), Stats{Bytes: 20, Lines: 2, Flushes: 2, MaxBuffered: 20})
+	})
+
+	t.Run("BatchLineWriter ReadFrom", func(t *testing.T) {
+		output := new(testBuffer)
+		lw, err := NewBatchLineWriter(output, 1)
+		ensureErrorNil(t, err)
+
+		_, err = lw.ReadFrom(&testReader{tuples: []tuple{
+			tuple{"line 1\nli", nil},
+			tuple{"ne 2\n", io.EOF},
+		}})
+		ensureErrorNil(t, err)
+		ensureErrorNil(t, lw.Close())
+		ensureStats(t, lw.Stats(), Stats{Bytes: 14, Lines: 2, Flushes: 2, MaxBuffered: 9})
+	})
+
+	t.Run("BatchLineWriter errors", func(t *testing.T) {
+		lw, err := NewBatchLineWriter(&errOnWrite{}, 1)
+		ensureErrorNil(t, err)
+
+		_, err = lw.Write([]byte("line 1\n"))
+		ensureError(t, err, "test write error")
+		ensureStats(t, lw.Stats(), Stats{Flushes: 1, Errors: 1, MaxBuffered: 7})
+	})
+
+	t.Run("PerLineWriter", func(t *testing.T) {
+		output := new(testBuffer)
+		lw := &PerLineWriter{WC: output, PrefixFunc: func() []byte { return []byte("> ") }}
+
+		ensureWrite(t, lw, "line 1\nli")
+		ensureWrite(t, lw, "ne 2\nline 3")
+		ensureStats(t, lw.Stats(), Stats{Bytes: 18, Lines: 2, Flushes: 2, MaxBuffered: 13})
+
+		ensureErrorNil(t, lw.Close())
+		ensureStats(t, lw.Stats(), Stats{Bytes: 26, Lines: 2, Flushes: 3, MaxBuffered: 13})
+	})
+
+	t.Run("PerLineWriter errors", func(t *testing.T) {
+		lw := &PerLineWriter{WC: &errOnWrite{}}
+
+		_, err := lw.Write([]byte("line 1\n"))
+		ensureError(t, err, "test write error")
+		ensureStats(t, lw.Stats(), Stats{Flushes: 1, Errors: 1, MaxBuffered: 7})
+	})
+}
+
+func TestOnFlush(t *testing.T) {
+	type flush struct{ bytes, lines int }
+	var flushes []flush
+	onFlush := func(bytes, lines int) { flushes = append(flushes, flush{bytes, lines}) }
+
+	t.Run("Write", func(t *testing.T) {
+		flushes = nil
+		lw, err := NewBatchLineWriterOptions(new(testBuffer), WithFlushThreshold(8), WithOnFlush(onFlush))
+		ensureErrorNil(t, err)
+
+		ensureWrite(t, lw, "line 1\nline 2\nline 3")
+		ensureErrorNil(t, lw.Close())
+
+		want := []flush{{14, 2}, {6, 0}}
+		if len(flushes) != len(want) {
+			t.Fatalf("GOT: %v; WANT: %v", flushes, want)
+		}
+		for i := range want {
+			if flushes[i] != want[i] {
+				t.Errorf("GOT: %v; WANT: %v", flushes[i], want[i])
+			}
+		}
+	})
+
+	t.Run("buffers writer", func(t *testing.T) {
+		flushes = nil
+		lw, err := NewBatchLineWriterOptions(&testBuffersWriter{}, WithFlushThreshold(8), WithOnFlush(onFlush))
+		ensureErrorNil(t, err)
+
+		ensureWrite(t, lw, "line 1")
+		ensureWrite(t, lw, "\nline 2\nline 3\n")
+		ensureErrorNil(t, lw.Close())
+
+		if got, want := len(flushes), 1; got != want {
+			t.Fatalf("GOT: %v; WANT: %v", got, want)
+		}
+		if got, want := flushes[0], (flush{21, 3}); got != want {
+			t.Errorf("GOT: %v; WANT: %v", got, want)
+		}
+	})
+}
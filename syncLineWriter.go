@@ -0,0 +1,181 @@
+package gonl
+
+import (
+	"io"
+	"sync"
+)
+
+// SyncLineWriter is an io.WriteCloser that may be shared by multiple
+// goroutines writing lines to the same underlying io.WriteCloser, such
+// as a BatchLineWriter, guaranteeing that the lines written by one
+// goroutine are never interleaved in the middle of a line written by
+// another.
+//
+// Each goroutine that writes lines in pieces should obtain its own
+// SyncLineWriterHandle from the Handle method. A handle holds back any
+// partial line until its newline arrives, then forwards only complete
+// lines to the underlying io.WriteCloser while holding a lock. Write
+// calls made directly on the SyncLineWriter are written contiguously,
+// but are not otherwise held back, and are therefore only suitable for
+// callers that write whole lines with each call.
+//
+//	sw := gonl.NewSyncLineWriter(lw)
+//	for i := 0; i < workers; i++ {
+//		go func(w io.WriteCloser) {
+//			defer w.Close()
+//			fmt.Fprintf(w, "worker ")
+//			fmt.Fprintf(w, "done\n")
+//		}(sw.Handle())
+//	}
+//	// After workers finish:
+//	err := sw.Close()
+type SyncLineWriter struct {
+	mu      sync.Mutex
+	wc      io.WriteCloser
+	handles map[*SyncLineWriterHandle]struct{}
+	closed  bool
+}
+
+// NewSyncLineWriter returns a new SyncLineWriter that writes to wc.
+func NewSyncLineWriter(wc io.WriteCloser) *SyncLineWriter {
+	return &SyncLineWriter{wc: wc, handles: make(map[*SyncLineWriterHandle]struct{})}
+}
+
+// Close writes the partial line held by each handle not yet closed,
+// terminated by a newline, then closes the underlying io.WriteCloser.
+// After Close, writes to the SyncLineWriter or any of its handles
+// return ErrClosed. Calling Close more than once returns ErrClosed.
+func (sw *SyncLineWriter) Close() error {
+	sw.mu.Lock()
+	defer sw.mu.Unlock()
+
+	if sw.closed {
+		return ErrClosed
+	}
+	sw.closed = true
+
+	var err error
+	for h := range sw.handles {
+		if werr := h.writePartial(); werr != nil && err == nil {
+			err = werr
+		}
+	}
+	sw.handles = nil
+
+	if cerr := sw.wc.Close(); err == nil {
+		err = cerr
+	}
+	return err
+}
+
+// Handle returns a new SyncLineWriterHandle, which a single goroutine
+// uses to write lines to the SyncLineWriter.
+func (sw *SyncLineWriter) Handle() *SyncLineWriterHandle {
+	sw.mu.Lock()
+	defer sw.mu.Unlock()
+
+	h := &SyncLineWriterHandle{sw: sw}
+	if !sw.closed {
+		sw.handles[h] = struct{}{}
+	}
+	return h
+}
+
+// Write writes p to the underlying io.WriteCloser without any other
+// write to the SyncLineWriter or its handles interleaving with it.
+func (sw *SyncLineWriter) Write(p []byte) (int, error) {
+	sw.mu.Lock()
+	defer sw.mu.Unlock()
+
+	if sw.closed {
+		return 0, ErrClosed
+	}
+	return sw.write(p)
+}
+
+// write writes p to the underlying io.WriteCloser, called with mu
+// held.
+func (sw *SyncLineWriter) write(p []byte) (int, error) {
+	nw, err := sw.wc.Write(p)
+	if err == nil && nw < len(p) {
+		err = io.ErrShortWrite
+	}
+	return nw, err
+}
+
+// SyncLineWriterHandle is an io.WriteCloser used by a single goroutine
+// to write lines to a SyncLineWriter. It is not itself safe for
+// concurrent use.
+type SyncLineWriterHandle struct {
+	sw *SyncLineWriter
+
+	// buf holds the partial line not yet terminated by a newline. It
+	// is protected by the mu of sw, because Close of sw writes it.
+	buf []byte
+
+	closed bool
+}
+
+// Close writes any partial line held by the handle, terminated by a
+// newline so that it cannot join a line written by another goroutine.
+// It does not close the SyncLineWriter.
+func (h *SyncLineWriterHandle) Close() error {
+	h.sw.mu.Lock()
+	defer h.sw.mu.Unlock()
+
+	if h.closed {
+		return ErrClosed
+	}
+	h.closed = true
+	if h.sw.closed {
+		return nil // partial line already written by SyncLineWriter.Close
+	}
+	delete(h.sw.handles, h)
+	return h.writePartial()
+}
+
+// Write forwards each line completed by p to the SyncLineWriter,
+// holding back any trailing partial line until its newline is
+// written. It returns len(p) on success. When the underlying
+// io.WriteCloser returns an error, it returns 0 and that error, and
+// none of p is retained.
+func (h *SyncLineWriterHandle) Write(p []byte) (int, error) {
+	h.sw.mu.Lock()
+	defer h.sw.mu.Unlock()
+
+	if h.closed || h.sw.closed {
+		return 0, ErrClosed
+	}
+
+	index := LastIndexDelim(p, '\n')
+	if index == -1 {
+		h.buf = append(h.buf, p...)
+		return len(p), nil
+	}
+
+	lines := p[:index+1]
+	if len(h.buf) > 0 {
+		h.buf = append(h.buf, lines...)
+		lines = h.buf
+	}
+	if _, err := h.sw.write(lines); err != nil {
+		if len(h.buf) > 0 {
+			h.buf = h.buf[:len(h.buf)-(index+1)] // restore partial line
+		}
+		return 0, err
+	}
+	h.buf = append(h.buf[:0], p[index+1:]...)
+	return len(p), nil
+}
+
+// writePartial writes the partial line held by the handle followed by
+// a newline, called with the mu of sw held.
+func (h *SyncLineWriterHandle) writePartial() error {
+	if len(h.buf) == 0 {
+		return nil
+	}
+	line := append(h.buf, '\n')
+	h.buf = nil
+	_, err := h.sw.write(line)
+	return err
+}
@@ -0,0 +1,95 @@
+package gonl
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+	"sync"
+	"testing"
+)
+
+func TestSyncLineWriter(t *testing.T) {
+	t.Run("handles do not interleave lines", func(t *testing.T) {
+		output := new(testBuffer)
+		lw, err := NewBatchLineWriter(output, 64)
+		ensureErrorNil(t, err)
+		sw := NewSyncLineWriter(lw)
+
+		const workers, lines = 8, 100
+		var wg sync.WaitGroup
+		for i := 0; i < workers; i++ {
+			wg.Add(1)
+			go func(i int, h *SyncLineWriterHandle) {
+				defer wg.Done()
+				for j := 0; j < lines; j++ {
+					// Write each line in several pieces.
+					fmt.Fprintf(h, "worker %d ", i)
+					fmt.Fprintf(h, "line %d", j)
+					fmt.Fprintf(h, "\n")
+				}
+				ensureErrorNil(t, h.Close())
+			}(i, sw.Handle())
+		}
+		wg.Wait()
+		ensureErrorNil(t, sw.Close())
+
+		got := strings.Split(strings.TrimSuffix(output.String(), "\n"), "\n")
+		if len(got) != workers*lines {
+			t.Fatalf("GOT: %v; WANT: %v", len(got), workers*lines)
+		}
+		for _, line := range got {
+			var i, j int
+			if _, err := fmt.Sscanf(line, "worker %d line %d", &i, &j); err != nil {
+				t.Fatalf("interleaved line: %q", line)
+			}
+		}
+	})
+
+	t.Run("partial line terminated on close", func(t *testing.T) {
+		output := new(testBuffer)
+		sw := NewSyncLineWriter(output)
+		h1 := sw.Handle()
+		h2 := sw.Handle()
+
+		ensureWrite(t, h1, "one ")
+		ensureWrite(t, h2, "two\nthree")
+		ensureStringer(t, output, "two\n")
+		ensureErrorNil(t, h1.Close())
+		ensureStringer(t, output, "two\none \n")
+		ensureErrorNil(t, sw.Close())
+		ensureStringer(t, output, "two\none \nthree\n")
+
+		_, err := h2.Write([]byte("four\n"))
+		ensureError(t, err, "already closed")
+		ensureErrorNil(t, h2.Close())
+		ensureError(t, h1.Close(), "already closed")
+		ensureError(t, sw.Close(), "already closed")
+	})
+
+	t.Run("Write", func(t *testing.T) {
+		output := new(testBuffer)
+		sw := NewSyncLineWriter(output)
+
+		ensureWrite(t, sw, "line 1\n")
+		ensureErrorNil(t, sw.Close())
+		ensureStringer(t, output, "line 1\n")
+
+		_, err := sw.Write([]byte("line 2\n"))
+		ensureError(t, err, "already closed")
+	})
+
+	t.Run("write error", func(t *testing.T) {
+		sw := NewSyncLineWriter(&errOnWrite{})
+		h := sw.Handle()
+
+		ensureWrite(t, h, "a")
+		n, err := h.Write([]byte("b\nc"))
+		ensureError(t, err, "test write error")
+		if got, want := n, 0; got != want {
+			t.Errorf("GOT: %v; WANT: %v", got, want)
+		}
+		if got, want := h.buf, []byte("a"); !bytes.Equal(got, want) {
+			t.Errorf("GOT: %q; WANT: %q", got, want)
+		}
+	})
+}
@@ -0,0 +1,39 @@
+package gonl
+
+// syncer is implemented by writers that can commit written data to
+// stable storage, such as *os.File.
+type syncer interface {
+	Sync() error
+}
+
+// syncOnFlush is invoked after each Write to the underlying
+// io.WriteCloser, which returned err. When SyncEvery is set and the
+// Write succeeded, it calls Sync once every SyncEvery flushes,
+// returning its error.
+func (lw *BatchLineWriter) syncOnFlush(err error) error {
+	if err != nil || lw.SyncEvery <= 0 {
+		return err
+	}
+	s, ok := underlying(lw.wc).(syncer)
+	if !ok {
+		return nil
+	}
+	if lw.unsynced++; lw.unsynced < lw.SyncEvery {
+		return nil
+	}
+	lw.unsynced = 0
+	return s.Sync()
+}
+
+// syncRemaining calls Sync when any flush has not yet been synced. It
+// is called by Close.
+func (lw *BatchLineWriter) syncRemaining() error {
+	if lw.unsynced == 0 {
+		return nil
+	}
+	lw.unsynced = 0
+	if s, ok := underlying(lw.wc).(syncer); ok {
+		return s.Sync()
+	}
+	return nil
+}
@@ -0,0 +1,86 @@
+package gonl
+
+import (
+	"errors"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// syncBuffer is a testBuffer that counts calls to Sync, returning err.
+type syncBuffer struct {
+	testBuffer
+	syncs int
+	err   error
+}
+
+func (sb *syncBuffer) Sync() error {
+	sb.syncs++
+	return sb.err
+}
+
+func TestBatchLineWriterSyncEvery(t *testing.T) {
+	t.Run("every flush", func(t *testing.T) {
+		output := new(syncBuffer)
+		lw, err := NewBatchLineWriterOptions(output, WithFlushThreshold(4), WithSyncOnFlush(1))
+		ensureErrorNil(t, err)
+
+		ensureWrite(t, lw, "line 1\n")
+		ensureWrite(t, lw, "line 2\n")
+		if got, want := output.syncs, 2; got != want {
+			t.Errorf("GOT: %v; WANT: %v", got, want)
+		}
+		ensureErrorNil(t, lw.Close())
+		if got, want := output.syncs, 2; got != want {
+			t.Errorf("GOT: %v; WANT: %v", got, want)
+		}
+	})
+
+	t.Run("every N flushes", func(t *testing.T) {
+		output := new(syncBuffer)
+		lw, err := NewBatchLineWriterOptions(output, WithFlushThreshold(4), WithSyncOnFlush(2))
+		ensureErrorNil(t, err)
+
+		ensureWrite(t, lw, "line 1\n")
+		ensureWrite(t, lw, "line 2\n")
+		ensureWrite(t, lw, "line 3\n")
+		if got, want := output.syncs, 1; got != want {
+			t.Errorf("GOT: %v; WANT: %v", got, want)
+		}
+		ensureErrorNil(t, lw.Close()) // syncs the third flush
+		if got, want := output.syncs, 2; got != want {
+			t.Errorf("GOT: %v; WANT: %v", got, want)
+		}
+		ensureStringer(t, output, "line 1\nline 2\nline 3\n")
+	})
+
+	t.Run("sync error", func(t *testing.T) {
+		output := &syncBuffer{err: errors.New("test sync error")}
+		lw, err := NewBatchLineWriterOptions(output, WithFlushThreshold(4), WithSyncOnFlush(1))
+		ensureErrorNil(t, err)
+
+		_, err = lw.Write([]byte("line 1\n"))
+		ensureError(t, err, "test sync error")
+		if got, want := lw.Stats().Errors, int64(1); got != want {
+			t.Errorf("GOT: %v; WANT: %v", got, want)
+		}
+		ensureErrorNil(t, lw.Close())
+	})
+
+	t.Run("file", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "audit.log")
+		f, err := os.Create(path)
+		ensureErrorNil(t, err)
+		lw, err := NewBatchLineWriterOptions(f, WithFlushThreshold(4), WithSyncOnFlush(1))
+		ensureErrorNil(t, err)
+
+		ensureWrite(t, lw, "line 1\n")
+		ensureErrorNil(t, lw.Close())
+		buf, err := ioutil.ReadFile(path)
+		ensureErrorNil(t, err)
+		if got, want := string(buf), "line 1\n"; got != want {
+			t.Errorf("GOT: %q; WANT: %q", got, want)
+		}
+	})
+}
@@ -0,0 +1,168 @@
+package gonl
+
+import (
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"time"
+)
+
+// SyslogFormat is the message format written by a SyslogLineWriter.
+type SyslogFormat int
+
+const (
+	// SyslogRFC5424 formats each message as described by RFC 5424,
+	// such as `<14>1 2006-01-02T15:04:05.000000Z host app 42 - - msg`.
+	SyslogRFC5424 SyslogFormat = iota
+
+	// SyslogRFC3164 formats each message in the older BSD format
+	// described by RFC 3164, such as `<14>Jan  2 15:04:05 host
+	// app[42]: msg`.
+	SyslogRFC3164
+)
+
+// SyslogLineWriter is an io.WriteCloser that wraps each complete line
+// written to it in a syslog message header before writing it,
+// terminated by a newline, to the underlying io.WriteCloser. Lines
+// split across multiple Write calls are reassembled before being
+// framed. The final line, when not terminated by a newline, is framed
+// and written when the SyslogLineWriter is closed.
+//
+// Unlike log/syslog, which makes a system call for each message,
+// SyslogLineWriter is meant to write to a BatchLineWriter, so many
+// messages are sent to the syslog daemon in each write, using the
+// newline terminated framing of RFC 6587 over a stream socket. Over a
+// datagram socket, such as one from NewUnixLineWriter with datagram
+// set, each message is instead sent as its own datagram.
+//
+//	lw, err := gonl.NewTCPLineWriter("syslog:601", gonl.WithFlushInterval(time.Second))
+//	if err != nil {
+//		return err
+//	}
+//	sw := &gonl.SyslogLineWriter{WC: lw, Facility: 16, Severity: 6} // local0.info
+type SyslogLineWriter struct {
+	// WC is io.WriteCloser where data is ultimately written.
+	WC io.WriteCloser
+
+	// Format is the message format.
+	Format SyslogFormat
+
+	// Facility and Severity, as numbered by RFC 5424, determine the
+	// priority of each message. For instance, facility 1 is user-level
+	// messages, and severity 6 is informational.
+	Facility, Severity int
+
+	// Hostname identifies the machine sending the messages. When
+	// empty, the result of os.Hostname is used.
+	Hostname string
+
+	// AppName identifies the application sending the messages. When
+	// empty, the base name of the program is used.
+	AppName string
+
+	// ProcID identifies the process sending the messages. When empty,
+	// the process ID is used.
+	ProcID string
+
+	// MsgID identifies the type of the messages in RFC 5424 format. It
+	// is written as "-" when empty, and ignored in RFC 3164 format.
+	MsgID string
+
+	la lineAssembler
+
+	// header and fields are the parts of the header of each message
+	// that precede and follow its timestamp.
+	header, fields []byte
+
+	scratch []byte
+}
+
+// Close frames and writes any remaining partial line, then closes the
+// underlying io.WriteCloser.
+func (sw *SyslogLineWriter) Close() error {
+	err := sw.la.close(sw.emit)
+	sw.scratch = nil
+	if err != nil {
+		_ = sw.WC.Close()
+		return err
+	}
+	return sw.WC.Close()
+}
+
+// Write frames and writes each line completed by p to the underlying
+// io.WriteCloser, returning the number of bytes of p consumed.
+func (sw *SyslogLineWriter) Write(p []byte) (int, error) {
+	return sw.la.write(p, sw.emit)
+}
+
+func (sw *SyslogLineWriter) emit(line []byte) error {
+	if sw.header == nil {
+		sw.header, sw.fields = sw.frame()
+	}
+	layout := "2006-01-02T15:04:05.000000Z07:00"
+	if sw.Format == SyslogRFC3164 {
+		layout = time.Stamp
+	}
+	buf := append(sw.scratch[:0], sw.header...)
+	buf = time.Now().AppendFormat(buf, layout)
+	buf = append(buf, sw.fields...)
+	buf = append(append(buf, trimNewline(line)...), '\n')
+	sw.scratch = buf
+
+	_, err := sw.WC.Write(buf)
+	return err
+}
+
+// frame returns the parts of the header of each message that precede
+// and follow its timestamp, which are the same for every message.
+func (sw *SyslogLineWriter) frame() (header, fields []byte) {
+	header = append(header, '<')
+	header = strconv.AppendInt(header, int64(sw.Facility*8+sw.Severity), 10)
+	header = append(header, '>')
+
+	hostname := syslogField(sw.Hostname, syslogHostname)
+	appName := syslogField(sw.AppName, syslogAppName)
+	procID := syslogField(sw.ProcID, syslogProcID)
+
+	if sw.Format == SyslogRFC3164 {
+		fields = append(fields, ' ')
+		fields = append(fields, hostname...)
+		fields = append(fields, ' ')
+		fields = append(fields, appName...)
+		fields = append(fields, '[')
+		fields = append(fields, procID...)
+		fields = append(fields, "]: "...)
+		return header, fields
+	}
+
+	header = append(header, "1 "...) // version
+
+	// The final "-" is the empty structured data.
+	for _, field := range []string{hostname, appName, procID, syslogField(sw.MsgID, nil), "-"} {
+		fields = append(append(fields, ' '), field...)
+	}
+	return header, append(fields, ' ')
+}
+
+// syslogField returns value, or when it is empty, the result of
+// fallback, or "-" when there is no fallback or it returns an empty
+// string.
+func syslogField(value string, fallback func() string) string {
+	if value == "" && fallback != nil {
+		value = fallback()
+	}
+	if value == "" {
+		return "-"
+	}
+	return value
+}
+
+func syslogHostname() string {
+	hostname, _ := os.Hostname()
+	return hostname
+}
+
+func syslogAppName() string { return filepath.Base(os.Args[0]) }
+
+func syslogProcID() string { return strconv.Itoa(os.Getpid()) }
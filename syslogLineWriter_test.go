@@ -0,0 +1,59 @@
+package gonl
+
+import (
+	"os"
+	"regexp"
+	"strconv"
+	"testing"
+)
+
+func TestSyslogLineWriter(t *testing.T) {
+	t.Run("RFC 5424", func(t *testing.T) {
+		output := new(testBuffer)
+		sw := &SyslogLineWriter{WC: output, Facility: 16, Severity: 6, Hostname: "host", AppName: "app", MsgID: "ID47"}
+
+		ensureWrite(t, sw, "line 1\nli")
+		ensureWrite(t, sw, "ne 2")
+		ensureErrorNil(t, sw.Close())
+
+		pid := strconv.Itoa(os.Getpid())
+		re := regexp.MustCompile(`^<134>1 \d{4}-\d\d-\d\dT\d\d:\d\d:\d\d\.\d{6}(Z|[+-]\d\d:\d\d) host app ` + pid + ` ID47 - line 1\n` +
+			`<134>1 \S+ host app ` + pid + ` ID47 - line 2\n$`)
+		if got := output.String(); !re.MatchString(got) {
+			t.Errorf("GOT: %q; WANT: match %v", got, re)
+		}
+	})
+
+	t.Run("RFC 3164", func(t *testing.T) {
+		output := new(testBuffer)
+		sw := &SyslogLineWriter{WC: output, Format: SyslogRFC3164, Facility: 1, Severity: 3, Hostname: "host", AppName: "app", ProcID: "42"}
+
+		ensureWrite(t, sw, "line 1\n")
+		ensureErrorNil(t, sw.Close())
+
+		re := regexp.MustCompile(`^<11>[A-Z][a-z]{2} [ \d]\d \d\d:\d\d:\d\d host app\[42\]: line 1\n$`)
+		if got := output.String(); !re.MatchString(got) {
+			t.Errorf("GOT: %q; WANT: match %v", got, re)
+		}
+	})
+
+	t.Run("batched", func(t *testing.T) {
+		output := new(recordingWriter)
+		lw, err := NewBatchLineWriter(NopCloseWriter(output), 1024)
+		ensureErrorNil(t, err)
+		sw := &SyslogLineWriter{WC: lw}
+
+		ensureWrite(t, sw, "line 1\nline 2\nline 3\n")
+		ensureErrorNil(t, sw.Close())
+		if got, want := len(output.writes), 1; got != want {
+			t.Errorf("GOT: %v; WANT: %v", got, want)
+		}
+	})
+
+	t.Run("write error", func(t *testing.T) {
+		sw := &SyslogLineWriter{WC: &errOnWrite{}}
+		_, err := sw.Write([]byte("line\n"))
+		ensureError(t, err, "test write error")
+		ensureError(t, sw.Close(), "test close error")
+	})
+}
@@ -0,0 +1,54 @@
+package gonl
+
+import (
+	"io"
+)
+
+// TeeLineWriter is an io.WriteCloser that writes each complete line to
+// the underlying io.WriteCloser, and then duplicates it to Tee. Unlike
+// io.MultiWriter, which passes along whatever chunks of bytes it is
+// given, both destinations only ever see whole lines, each with a
+// single Write call. The final line, when not terminated by a newline,
+// is written to both when the TeeLineWriter is closed.
+//
+//	tw := &gonl.TeeLineWriter{WC: logFile, Tee: os.Stderr}
+type TeeLineWriter struct {
+	// WC is the primary io.WriteCloser where data is written.
+	WC io.WriteCloser
+
+	// Tee is the secondary io.WriteCloser where each line is written
+	// after it has been written to WC.
+	Tee io.WriteCloser
+
+	la lineAssembler
+}
+
+// Close writes any remaining partial line to both destinations, then
+// closes both of them, returning the first error encountered.
+func (tw *TeeLineWriter) Close() error {
+	err := tw.la.close(tw.emit)
+	if cerr := tw.WC.Close(); err == nil {
+		err = cerr
+	}
+	if cerr := tw.Tee.Close(); err == nil {
+		err = cerr
+	}
+	return err
+}
+
+// Write writes each line completed by p to both destinations. When
+// either destination returns an error, Write returns the number of
+// bytes of p preceding the line that could not be written along with
+// that error. Note the line may already have been written to WC when
+// the error is returned by Tee.
+func (tw *TeeLineWriter) Write(p []byte) (int, error) {
+	return tw.la.write(p, tw.emit)
+}
+
+func (tw *TeeLineWriter) emit(line []byte) error {
+	if _, err := tw.WC.Write(line); err != nil {
+		return err
+	}
+	_, err := tw.Tee.Write(line)
+	return err
+}
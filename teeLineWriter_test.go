@@ -0,0 +1,37 @@
+package gonl
+
+import (
+	"testing"
+)
+
+func TestTeeLineWriter(t *testing.T) {
+	t.Run("duplicates whole lines", func(t *testing.T) {
+		primary := new(testBuffer)
+		secondary := new(testBuffersWriter)
+		tw := &TeeLineWriter{WC: primary, Tee: secondary}
+
+		ensureWrite(t, tw, "line 1\nli")
+		ensureWrite(t, tw, "ne 2\nline 3")
+		ensureStringer(t, primary, "line 1\nline 2\n")
+		ensureErrorNil(t, tw.Close())
+		ensureStringer(t, primary, "line 1\nline 2\nline 3")
+
+		ensureStringer(t, secondary, "line 1\nline 2\nline 3")
+		if got, want := secondary.writes, 3; got != want {
+			t.Errorf("GOT: %v; WANT: %v", got, want)
+		}
+	})
+
+	t.Run("tee error", func(t *testing.T) {
+		primary := new(testBuffer)
+		tw := &TeeLineWriter{WC: primary, Tee: &errOnWrite{}}
+
+		n, err := tw.Write([]byte("line 1\nline 2\n"))
+		ensureError(t, err, "test write error")
+		if got, want := n, 0; got != want {
+			t.Errorf("GOT: %v; WANT: %v", got, want)
+		}
+		ensureStringer(t, primary, "line 1\n")
+		ensureError(t, tw.Close(), "test close error")
+	})
+}
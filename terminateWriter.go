@@ -0,0 +1,48 @@
+package gonl
+
+import (
+	"io"
+)
+
+// TerminateWriter is an io.WriteCloser that ensures the content of
+// each Write call ends with a newline, appending one when the
+// provided slice does not already end with one. Only the final line
+// of each Write call is affected; a newline is never inserted between
+// the lines of a single Write call.
+//
+// The content of each Write call, including any synthesized newline,
+// is written to the underlying io.WriteCloser with a single Write
+// call.
+type TerminateWriter struct {
+	// WC is io.WriteCloser where data is ultimately written.
+	WC io.WriteCloser
+
+	buf []byte
+}
+
+// Close closes the underlying io.WriteCloser.
+func (tw *TerminateWriter) Close() error {
+	tw.buf = nil
+	return tw.WC.Close()
+}
+
+// Write writes p to the underlying io.WriteCloser, appending a newline
+// when p does not end with one. The synthesized newline is not
+// included in the returned byte count, so after a successful write
+// the return value is len(p).
+func (tw *TerminateWriter) Write(p []byte) (int, error) {
+	l := len(p)
+	if l == 0 {
+		return 0, nil
+	}
+	if p[l-1] == '\n' {
+		return tw.WC.Write(p)
+	}
+
+	tw.buf = append(append(tw.buf[:0], p...), '\n')
+	nw, err := tw.WC.Write(tw.buf)
+	if nw > l {
+		nw = l // do not count the synthesized newline
+	}
+	return nw, err
+}
@@ -0,0 +1,55 @@
+package gonl
+
+import (
+	"io"
+	"testing"
+)
+
+func TestTerminateWriter(t *testing.T) {
+	t.Run("empty write", func(t *testing.T) {
+		output := new(testBuffer)
+		tw := &TerminateWriter{WC: output}
+
+		n, err := tw.Write(nil)
+		ensureErrorNil(t, err)
+		if got, want := n, 0; got != want {
+			t.Errorf("GOT: %v; WANT: %v", got, want)
+		}
+		ensureStringer(t, output, "")
+	})
+
+	t.Run("already terminated", func(t *testing.T) {
+		output := new(testBuffer)
+		tw := &TerminateWriter{WC: output}
+
+		ensureWrite(t, tw, "line 1\nline 2\n")
+		ensureStringer(t, output, "line 1\nline 2\n")
+	})
+
+	t.Run("not terminated", func(t *testing.T) {
+		output := new(testBuffer)
+		tw := &TerminateWriter{WC: output}
+
+		ensureWrite(t, tw, "line 1\nline 2")
+		ensureWrite(t, tw, "line 3")
+		ensureStringer(t, output, "line 1\nline 2\nline 3\n")
+		ensureErrorNil(t, tw.Close())
+	})
+
+	t.Run("short write", func(t *testing.T) {
+		output := new(testBuffer)
+		tw := &TerminateWriter{WC: NopCloseWriter(ShortWriter(output, 6))}
+
+		n, err := tw.Write([]byte("line 1"))
+		ensureError(t, err, io.ErrShortWrite.Error())
+		if got, want := n, 6; got != want {
+			t.Errorf("GOT: %v; WANT: %v", got, want)
+		}
+		ensureStringer(t, output, "line 1")
+	})
+
+	t.Run("close error", func(t *testing.T) {
+		tw := &TerminateWriter{WC: &errOnClose{}}
+		ensureError(t, tw.Close(), "test close error")
+	})
+}
@@ -89,3 +89,58 @@ func TestReader(t *testing.T) {
 		})
 	})
 }
+
+// overReader is an io.Reader that misbehaves by reporting that it read
+// more bytes than the length of the slice it was given.
+type overReader struct{}
+
+func (overReader) Read(p []byte) (int, error) { return len(p) + 1, nil }
+
+// repeatReader is an io.Reader that fills each slice it is given with
+// consecutive bytes of line, repeated until limit bytes have been
+// read, without allocating the data it returns.
+type repeatReader struct {
+	line  []byte
+	limit int64
+	read  int64
+}
+
+func (rr *repeatReader) Read(p []byte) (int, error) {
+	remaining := rr.limit - rr.read
+	if remaining <= 0 {
+		return 0, io.EOF
+	}
+	if int64(len(p)) > remaining {
+		p = p[:remaining]
+	}
+	offset := int(rr.read % int64(len(rr.line)))
+	var n int
+	for n < len(p) {
+		n += copy(p[n:], rr.line[offset:])
+		offset = 0
+	}
+	rr.read += int64(n)
+	return n, nil
+}
+
+// hugeReader reports reading limit bytes in total, each read filling p
+// entirely, except the final read. Rather than copying data into p, it
+// only writes a newline to the final byte of each read, so that it may
+// report reading far more bytes than could be allocated.
+type hugeReader struct {
+	limit int64
+	read  int64
+}
+
+func (hr *hugeReader) Read(p []byte) (int, error) {
+	remaining := hr.limit - hr.read
+	if remaining <= 0 {
+		return 0, io.EOF
+	}
+	if int64(len(p)) > remaining {
+		p = p[:remaining]
+	}
+	p[len(p)-1] = '\n'
+	hr.read += int64(len(p))
+	return len(p), nil
+}
@@ -0,0 +1,68 @@
+package gonl
+
+import (
+	"io"
+
+	"golang.org/x/text/encoding"
+)
+
+// TranscodeWriter is an io.WriteCloser that converts each complete line
+// written to it from the character set of Encoding to UTF-8 before
+// writing it to the underlying io.WriteCloser, such as when ingesting
+// Latin-1 or Shift JIS log files into a UTF-8 pipeline. It is the
+// inverse of EncodingWriter. Lines split across multiple Write calls,
+// including lines with a multibyte sequence split between calls, are
+// reassembled before being converted, so each line written to the
+// underlying io.WriteCloser is complete. The final line, when not
+// terminated by a newline, is converted and written when the
+// TranscodeWriter is closed.
+//
+// Lines are found by looking for the newline byte before conversion,
+// so Encoding must encode a newline as the single byte 0x0A, and never
+// use that byte within a multibyte sequence, as is the case for ASCII
+// compatible encodings. To convert UTF-16 text, use
+// transform.NewWriter with a decoder from golang.org/x/text instead.
+//
+//	tw := &gonl.TranscodeWriter{WC: os.Stdout, Encoding: charmap.ISO8859_1}
+type TranscodeWriter struct {
+	// WC is io.WriteCloser where data is ultimately written.
+	WC io.WriteCloser
+
+	// Encoding is the character set of the bytes written to the
+	// TranscodeWriter.
+	Encoding encoding.Encoding
+
+	la lineAssembler
+
+	decoder *encoding.Decoder
+}
+
+// Close converts and writes any remaining partial line, then closes
+// the underlying io.WriteCloser.
+func (tw *TranscodeWriter) Close() error {
+	if err := tw.la.close(tw.emit); err != nil {
+		_ = tw.WC.Close()
+		return err
+	}
+	return tw.WC.Close()
+}
+
+// Write converts and writes each line completed by p to the underlying
+// io.WriteCloser, returning the number of bytes of p consumed. When a
+// line cannot be converted, Write returns the error from the decoder
+// of Encoding.
+func (tw *TranscodeWriter) Write(p []byte) (int, error) {
+	return tw.la.write(p, tw.emit)
+}
+
+func (tw *TranscodeWriter) emit(line []byte) error {
+	if tw.decoder == nil {
+		tw.decoder = tw.Encoding.NewDecoder()
+	}
+	decoded, err := tw.decoder.Bytes(line)
+	if err != nil {
+		return err
+	}
+	_, err = tw.WC.Write(decoded)
+	return err
+}
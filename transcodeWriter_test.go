@@ -0,0 +1,38 @@
+package gonl
+
+import (
+	"testing"
+
+	"golang.org/x/text/encoding/charmap"
+	"golang.org/x/text/encoding/japanese"
+)
+
+func TestTranscodeWriter(t *testing.T) {
+	t.Run("Latin-1", func(t *testing.T) {
+		output := new(testBuffer)
+		tw := &TranscodeWriter{WC: output, Encoding: charmap.ISO8859_1}
+
+		ensureWrite(t, tw, "caf\xe9\nna")
+		ensureStringer(t, output, "caf\xc3\xa9\n")
+		ensureWrite(t, tw, "\xefve")
+		ensureErrorNil(t, tw.Close())
+		ensureStringer(t, output, "caf\xc3\xa9\nna\xc3\xafve")
+	})
+
+	t.Run("multibyte sequence split across writes", func(t *testing.T) {
+		output := new(testBuffer)
+		tw := &TranscodeWriter{WC: output, Encoding: japanese.ShiftJIS}
+
+		ensureWrite(t, tw, "\x82")   // first byte of あ
+		ensureWrite(t, tw, "\xa0\n") // remainder of あ
+		ensureErrorNil(t, tw.Close())
+		ensureStringer(t, output, "あ\n")
+	})
+
+	t.Run("write error", func(t *testing.T) {
+		tw := &TranscodeWriter{WC: &errOnWrite{}, Encoding: charmap.ISO8859_1}
+		_, err := tw.Write([]byte("line\n"))
+		ensureError(t, err, "test write error")
+		ensureError(t, tw.Close(), "test close error")
+	})
+}
@@ -0,0 +1,101 @@
+package gonl
+
+import (
+	"bytes"
+	"io"
+	"strconv"
+)
+
+// UniqWriter is an io.WriteCloser that suppresses each line identical
+// to the line immediately preceding it, like the uniq command, as
+// data streams to the underlying io.WriteCloser. Lines are compared
+// without their terminating newlines, and the comparison continues
+// across Write call boundaries. The final line, when not terminated
+// by a newline, is processed when the UniqWriter is closed.
+//
+// Only the previous line is retained in memory, along with any
+// partial line not yet terminated by a newline.
+type UniqWriter struct {
+	// WC is io.WriteCloser where data is ultimately written.
+	WC io.WriteCloser
+
+	// Count, when set, causes each emitted line to be prefixed with
+	// the number of times it was repeated, in the same format as
+	// `uniq -c`. Because the count is not known until a different line
+	// arrives, each line is held until either the following
+	// different line is written, or the UniqWriter is closed.
+	Count bool
+
+	la lineAssembler
+
+	// prev is the previous line, including its newline if it had
+	// one.
+	prev    []byte
+	hasPrev bool
+	repeats int
+
+	scratch []byte
+}
+
+// Close processes any remaining partial line, writes any line held
+// while counting repeats, then closes the underlying io.WriteCloser.
+func (uw *UniqWriter) Close() error {
+	err := uw.la.close(uw.emit)
+	if err == nil && uw.Count && uw.hasPrev {
+		err = uw.writeCounted()
+	}
+	uw.prev = nil
+	uw.hasPrev = false
+	uw.scratch = nil
+	if err != nil {
+		_ = uw.WC.Close()
+		return err
+	}
+	return uw.WC.Close()
+}
+
+// Write writes each line completed by p that differs from the line
+// preceding it to the underlying io.WriteCloser, returning the number
+// of bytes of p consumed.
+func (uw *UniqWriter) Write(p []byte) (int, error) {
+	return uw.la.write(p, uw.emit)
+}
+
+func (uw *UniqWriter) emit(line []byte) error {
+	if uw.hasPrev && bytes.Equal(trimNewline(line), trimNewline(uw.prev)) {
+		uw.repeats++
+		if uw.Count {
+			// Retain the terminator of the final repeat.
+			uw.prev = append(uw.prev[:0], line...)
+		}
+		return nil
+	}
+
+	if uw.Count && uw.hasPrev {
+		if err := uw.writeCounted(); err != nil {
+			return err
+		}
+	} else if !uw.Count {
+		if _, err := uw.WC.Write(line); err != nil {
+			return err
+		}
+	}
+
+	uw.prev = append(uw.prev[:0], line...)
+	uw.hasPrev = true
+	uw.repeats = 1
+	return nil
+}
+
+// writeCounted writes the previous line prefixed by its repeat count.
+func (uw *UniqWriter) writeCounted() error {
+	count := strconv.Itoa(uw.repeats)
+	buf := uw.scratch[:0]
+	for i := len(count); i < 7; i++ {
+		buf = append(buf, ' ')
+	}
+	buf = append(append(append(buf, count...), ' '), uw.prev...)
+	uw.scratch = buf
+	_, err := uw.WC.Write(buf)
+	return err
+}
@@ -0,0 +1,59 @@
+package gonl
+
+import (
+	"testing"
+)
+
+func TestUniqWriter(t *testing.T) {
+	t.Run("suppresses consecutive duplicates", func(t *testing.T) {
+		output := new(testBuffer)
+		uw := &UniqWriter{WC: output}
+
+		ensureWrite(t, uw, "a\na\nb\n")
+		ensureWrite(t, uw, "b\na\n")
+		ensureStringer(t, output, "a\nb\na\n")
+		ensureErrorNil(t, uw.Close())
+		ensureStringer(t, output, "a\nb\na\n")
+	})
+
+	t.Run("duplicate split across writes", func(t *testing.T) {
+		output := new(testBuffer)
+		uw := &UniqWriter{WC: output}
+
+		ensureWrite(t, uw, "line 1\nli")
+		ensureWrite(t, uw, "ne 1\nline 2")
+		ensureStringer(t, output, "line 1\n")
+		ensureErrorNil(t, uw.Close())
+		ensureStringer(t, output, "line 1\nline 2")
+	})
+
+	t.Run("final partial line duplicate", func(t *testing.T) {
+		output := new(testBuffer)
+		uw := &UniqWriter{WC: output}
+
+		ensureWrite(t, uw, "a\na")
+		ensureErrorNil(t, uw.Close())
+		ensureStringer(t, output, "a\n")
+	})
+
+	t.Run("count", func(t *testing.T) {
+		output := new(testBuffer)
+		uw := &UniqWriter{WC: output, Count: true}
+
+		ensureWrite(t, uw, "a\na\nb\n")
+		ensureStringer(t, output, "      2 a\n")
+		ensureWrite(t, uw, "c\nc")
+		ensureErrorNil(t, uw.Close())
+		ensureStringer(t, output, "      2 a\n      1 b\n      2 c")
+	})
+
+	t.Run("write error", func(t *testing.T) {
+		uw := &UniqWriter{WC: &errOnWrite{}}
+		n, err := uw.Write([]byte("a\n"))
+		ensureError(t, err, "test write error")
+		if got, want := n, 0; got != want {
+			t.Errorf("GOT: %v; WANT: %v", got, want)
+		}
+		ensureError(t, uw.Close(), "test close error")
+	})
+}
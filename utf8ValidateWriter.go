@@ -0,0 +1,114 @@
+package gonl
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"unicode/utf8"
+)
+
+// UTF8Policy determines what a UTF8ValidateWriter does with a line
+// that is not valid UTF-8.
+type UTF8Policy int
+
+const (
+	// UTF8Error causes Write to return an *InvalidUTF8Error without
+	// writing the line.
+	UTF8Error UTF8Policy = iota
+
+	// UTF8Replace causes each run of invalid bytes to be replaced by
+	// the Unicode replacement character, U+FFFD, before the line is
+	// written.
+	UTF8Replace
+
+	// UTF8Drop causes the line to be consumed without being written.
+	UTF8Drop
+)
+
+// InvalidUTF8Error is returned by a UTF8ValidateWriter whose Policy is
+// UTF8Error when a line is not valid UTF-8.
+type InvalidUTF8Error struct {
+	// Line is the number of the line, counting from 1.
+	Line int64
+
+	// Offset is the offset of the first invalid byte within the line.
+	Offset int
+}
+
+func (e *InvalidUTF8Error) Error() string {
+	return fmt.Sprintf("gonl: line %d invalid UTF-8 at offset %d", e.Line, e.Offset)
+}
+
+// UTF8ValidateWriter is an io.WriteCloser that validates that each
+// complete line written to it is valid UTF-8 before writing it to the
+// underlying io.WriteCloser, handling invalid lines according to
+// Policy. Lines split across multiple Write calls, including lines
+// with a multibyte rune split between calls, are reassembled before
+// being validated. The final line, when not terminated by a newline,
+// is validated and written when the UTF8ValidateWriter is closed.
+//
+// When Policy is UTF8Error, Write returns the number of bytes of p
+// preceding the invalid line, which remains unconsumed. With other
+// policies, Write returns len(p) on success.
+type UTF8ValidateWriter struct {
+	// WC is io.WriteCloser where data is ultimately written.
+	WC io.WriteCloser
+
+	// Policy determines how lines that are not valid UTF-8 are
+	// handled.
+	Policy UTF8Policy
+
+	la lineAssembler
+
+	lines int64
+}
+
+// Close validates and writes any remaining partial line, then closes
+// the underlying io.WriteCloser.
+func (uw *UTF8ValidateWriter) Close() error {
+	if err := uw.la.close(uw.emit); err != nil {
+		_ = uw.WC.Close()
+		return err
+	}
+	return uw.WC.Close()
+}
+
+// Write validates and writes each line completed by p to the
+// underlying io.WriteCloser, returning the number of bytes of p
+// consumed.
+func (uw *UTF8ValidateWriter) Write(p []byte) (int, error) {
+	return uw.la.write(p, uw.emit)
+}
+
+func (uw *UTF8ValidateWriter) emit(line []byte) error {
+	if !utf8.Valid(line) {
+		switch uw.Policy {
+		case UTF8Replace:
+			line = bytes.ToValidUTF8(line, replacementChar)
+		case UTF8Drop:
+			uw.lines++
+			return nil
+		default:
+			return &InvalidUTF8Error{Line: uw.lines + 1, Offset: invalidUTF8Offset(line)}
+		}
+	}
+	uw.lines++
+	_, err := uw.WC.Write(line)
+	return err
+}
+
+// replacementChar is U+FFFD encoded as UTF-8.
+var replacementChar = []byte(string(utf8.RuneError))
+
+// invalidUTF8Offset returns the offset of the first byte of line that
+// does not begin a valid UTF-8 encoded rune, or -1 when line is valid.
+func invalidUTF8Offset(line []byte) int {
+	for i := 0; i < len(line); {
+		r, size := utf8.DecodeRune(line[i:])
+		if r == utf8.RuneError && size == 1 {
+			return i
+		}
+		i += size
+	}
+	return -1
+}
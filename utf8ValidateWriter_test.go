@@ -0,0 +1,64 @@
+package gonl
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestUTF8ValidateWriter(t *testing.T) {
+	t.Run("rune split across writes", func(t *testing.T) {
+		output := new(testBuffer)
+		uw := &UTF8ValidateWriter{WC: output}
+
+		euro := "€"
+		ensureWrite(t, uw, "price: "+euro[:1])
+		ensureWrite(t, uw, euro[1:2])
+		ensureWrite(t, uw, euro[2:]+"5\n")
+		ensureErrorNil(t, uw.Close())
+		ensureStringer(t, output, "price: €5\n")
+	})
+
+	t.Run("error", func(t *testing.T) {
+		output := new(testBuffer)
+		uw := &UTF8ValidateWriter{WC: output}
+
+		n, err := uw.Write([]byte("line 1\nli\xffne 2\nline 3\n"))
+		if got, want := n, 7; got != want {
+			t.Errorf("GOT: %v; WANT: %v", got, want)
+		}
+		var ie *InvalidUTF8Error
+		if !errors.As(err, &ie) {
+			t.Fatalf("GOT: %v; WANT: %T", err, ie)
+		}
+		if got, want := *ie, (InvalidUTF8Error{Line: 2, Offset: 2}); got != want {
+			t.Errorf("GOT: %+v; WANT: %+v", got, want)
+		}
+		ensureError(t, err, "line 2 invalid UTF-8 at offset 2")
+		ensureStringer(t, output, "line 1\n")
+	})
+
+	t.Run("replace", func(t *testing.T) {
+		output := new(testBuffer)
+		uw := &UTF8ValidateWriter{WC: output, Policy: UTF8Replace}
+
+		ensureWrite(t, uw, "a\xff\xfeb\nc\xe2\x82")
+		ensureErrorNil(t, uw.Close())
+		ensureStringer(t, output, "a�b\nc�")
+	})
+
+	t.Run("drop", func(t *testing.T) {
+		output := new(testBuffer)
+		uw := &UTF8ValidateWriter{WC: output, Policy: UTF8Drop}
+
+		ensureWrite(t, uw, "line 1\nli\xffne 2\nline 3\n")
+		ensureErrorNil(t, uw.Close())
+		ensureStringer(t, output, "line 1\nline 3\n")
+	})
+
+	t.Run("write error", func(t *testing.T) {
+		uw := &UTF8ValidateWriter{WC: &errOnWrite{}}
+		_, err := uw.Write([]byte("line\n"))
+		ensureError(t, err, "test write error")
+		ensureError(t, uw.Close(), "test close error")
+	})
+}
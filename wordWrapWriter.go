@@ -0,0 +1,93 @@
+package gonl
+
+import (
+	"io"
+	"unicode"
+	"unicode/utf8"
+)
+
+// WordWrapWriter is an io.WriteCloser that reflows each line written to
+// it to at most Width columns, breaking lines at whitespace, such as
+// when formatting streaming text for display on a terminal. Columns are
+// counted in runes rather than bytes, and each rune, including a tab,
+// counts as one column. The whitespace at which a line is broken is
+// removed, while other whitespace is preserved. A word longer than
+// Width is itself broken after Width runes. Only one line is buffered
+// at a time, so a document of any size may be streamed through it.
+// The final line, when not terminated by a newline, is reflowed and
+// written when the WordWrapWriter is closed.
+//
+//	ww := &gonl.WordWrapWriter{WC: os.Stdout, Width: 80}
+type WordWrapWriter struct {
+	// WC is io.WriteCloser where data is ultimately written.
+	WC io.WriteCloser
+
+	// Width is the largest number of runes of each line written,
+	// excluding its newline. Lines are not wrapped when Width is less
+	// than 1.
+	Width int
+
+	la lineAssembler
+
+	scratch []byte
+}
+
+// Close reflows and writes any remaining partial line, then closes the
+// underlying io.WriteCloser.
+func (ww *WordWrapWriter) Close() error {
+	err := ww.la.close(ww.emit)
+	ww.scratch = nil
+	if err != nil {
+		_ = ww.WC.Close()
+		return err
+	}
+	return ww.WC.Close()
+}
+
+// Write reflows and writes each line completed by p to the underlying
+// io.WriteCloser, returning the number of bytes of p consumed.
+func (ww *WordWrapWriter) Write(p []byte) (int, error) {
+	return ww.la.write(p, ww.emit)
+}
+
+func (ww *WordWrapWriter) emit(line []byte) error {
+	body := trimNewline(line)
+	if ww.Width < 1 || utf8.RuneCount(body) <= ww.Width {
+		_, err := ww.WC.Write(line)
+		return err
+	}
+	newline := line[len(body):] // empty for the final line
+
+	out := ww.scratch[:0]
+	var start, col int             // start of the current output line, and its width
+	spaceStart, spaceEnd := -1, -1 // most recent run of whitespace after a word
+	for i := 0; i < len(body); {
+		r, size := utf8.DecodeRune(body[i:])
+		if unicode.IsSpace(r) {
+			if col > 0 && spaceEnd != i {
+				spaceStart = i // first space after a word
+			}
+			spaceEnd = i + size
+		} else if col >= ww.Width {
+			if spaceStart > start {
+				// Break at the preceding whitespace.
+				out = append(append(out, body[start:spaceStart]...), '\n')
+				start = spaceEnd
+				col = utf8.RuneCount(body[start:i])
+			} else {
+				// The word is longer than Width.
+				out = append(append(out, body[start:i]...), '\n')
+				start = i
+				col = 0
+			}
+			spaceStart = -1
+		}
+		col++
+		i += size
+	}
+	out = append(append(out, body[start:]...), newline...)
+	ww.scratch = out
+
+	_, err := ww.WC.Write(out)
+	return err
+}
@@ -0,0 +1,44 @@
+package gonl
+
+import (
+	"testing"
+)
+
+func TestWordWrapWriter(t *testing.T) {
+	t.Run("breaks at whitespace", func(t *testing.T) {
+		output := new(testBuffer)
+		ww := &WordWrapWriter{WC: output, Width: 10}
+
+		ensureWrite(t, ww, "the quick brown fox jum")
+		ensureWrite(t, ww, "ps over the lazy dog\nshort\n")
+		ensureErrorNil(t, ww.Close())
+		ensureStringer(t, output, "the quick\nbrown fox\njumps over\nthe lazy\ndog\nshort\n")
+	})
+
+	t.Run("counts runes", func(t *testing.T) {
+		output := new(testBuffer)
+		ww := &WordWrapWriter{WC: output, Width: 5}
+
+		ensureWrite(t, ww, "héllo wörld")
+		ensureErrorNil(t, ww.Close())
+		ensureStringer(t, output, "héllo\nwörld")
+	})
+
+	t.Run("long word", func(t *testing.T) {
+		output := new(testBuffer)
+		ww := &WordWrapWriter{WC: output, Width: 4}
+
+		ensureWrite(t, ww, "ab abcdefghij cd\n")
+		ensureErrorNil(t, ww.Close())
+		ensureStringer(t, output, "ab\nabcd\nefgh\nij\ncd\n")
+	})
+
+	t.Run("preserves interior whitespace", func(t *testing.T) {
+		output := new(testBuffer)
+		ww := &WordWrapWriter{WC: output, Width: 8}
+
+		ensureWrite(t, ww, "  a  b    cdefgh\n")
+		ensureErrorNil(t, ww.Close())
+		ensureStringer(t, output, "  a  b\ncdefgh\n")
+	})
+}
@@ -0,0 +1,93 @@
+package gonl
+
+import (
+	"io"
+	"unicode/utf8"
+)
+
+// WrapWriter is an io.WriteCloser that splits each line longer than
+// Width bytes into multiple lines by inserting newlines, such as when
+// the destination silently drops long lines. Each line written to it
+// is written to the underlying io.WriteCloser with a single Write
+// call, after being split. Lines split across multiple Write calls are
+// reassembled before being wrapped. The final line, when not
+// terminated by a newline, is wrapped and written when the WrapWriter
+// is closed.
+//
+//	ww := &gonl.WrapWriter{WC: conn, Width: 1024, RuneSafe: true}
+type WrapWriter struct {
+	// WC is io.WriteCloser where data is ultimately written.
+	WC io.WriteCloser
+
+	// Width is the largest number of bytes of each line written,
+	// excluding its newline. Lines are not wrapped when Width is less
+	// than 1.
+	Width int
+
+	// RuneSafe, when set, causes lines to be split only at the start
+	// of a UTF-8 encoded rune, so that no rune is split across two
+	// lines. When a single rune is longer than Width, the line is
+	// split after that rune instead.
+	RuneSafe bool
+
+	la lineAssembler
+
+	scratch []byte
+}
+
+// Close wraps and writes any remaining partial line, then closes the
+// underlying io.WriteCloser.
+func (ww *WrapWriter) Close() error {
+	err := ww.la.close(ww.emit)
+	ww.scratch = nil
+	if err != nil {
+		_ = ww.WC.Close()
+		return err
+	}
+	return ww.WC.Close()
+}
+
+// Write wraps and writes each line completed by p to the underlying
+// io.WriteCloser, returning the number of bytes of p consumed.
+func (ww *WrapWriter) Write(p []byte) (int, error) {
+	return ww.la.write(p, ww.emit)
+}
+
+func (ww *WrapWriter) emit(line []byte) error {
+	body := trimNewline(line)
+	if ww.Width < 1 || len(body) <= ww.Width {
+		_, err := ww.WC.Write(line)
+		return err
+	}
+	newline := line[len(body):] // empty for the final line
+
+	out := ww.scratch[:0]
+	for len(body) > ww.Width {
+		end := ww.splitIndex(body)
+		out = append(append(out, body[:end]...), '\n')
+		body = body[end:]
+	}
+	out = append(append(out, body...), newline...)
+	ww.scratch = out
+
+	_, err := ww.WC.Write(out)
+	return err
+}
+
+// splitIndex returns the index at which to split body, which is longer
+// than Width.
+func (ww *WrapWriter) splitIndex(body []byte) int {
+	end := ww.Width
+	if !ww.RuneSafe {
+		return end
+	}
+	for i := end; i > 0; i-- {
+		if utf8.RuneStart(body[i]) {
+			return i
+		}
+	}
+	// A single rune is longer than Width: split after it.
+	for end = 1; end < len(body) && !utf8.RuneStart(body[end]); end++ {
+	}
+	return end
+}
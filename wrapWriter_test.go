@@ -0,0 +1,55 @@
+package gonl
+
+import (
+	"testing"
+)
+
+func TestWrapWriter(t *testing.T) {
+	t.Run("bytes", func(t *testing.T) {
+		output := new(testBuffer)
+		ww := &WrapWriter{WC: output, Width: 4}
+
+		ensureWrite(t, ww, "0123\n01234")
+		ensureWrite(t, ww, "56789\nab\n0123456")
+		ensureStringer(t, output, "0123\n0123\n4567\n89\nab\n")
+		ensureErrorNil(t, ww.Close())
+		ensureStringer(t, output, "0123\n0123\n4567\n89\nab\n0123\n456")
+	})
+
+	t.Run("exact multiple", func(t *testing.T) {
+		output := new(testBuffer)
+		ww := &WrapWriter{WC: output, Width: 2}
+
+		ensureWrite(t, ww, "abcd\n")
+		ensureErrorNil(t, ww.Close())
+		ensureStringer(t, output, "ab\ncd\n")
+	})
+
+	t.Run("rune safe", func(t *testing.T) {
+		output := new(testBuffer)
+		ww := &WrapWriter{WC: output, Width: 4, RuneSafe: true}
+
+		// "é" is two bytes, "世" is three bytes.
+		ensureWrite(t, ww, "abcé世界\n")
+		ensureErrorNil(t, ww.Close())
+		ensureStringer(t, output, "abc\né\n世\n界\n")
+	})
+
+	t.Run("rune longer than width", func(t *testing.T) {
+		output := new(testBuffer)
+		ww := &WrapWriter{WC: output, Width: 2, RuneSafe: true}
+
+		ensureWrite(t, ww, "世a\n")
+		ensureErrorNil(t, ww.Close())
+		ensureStringer(t, output, "世\na\n")
+	})
+
+	t.Run("disabled", func(t *testing.T) {
+		output := new(testBuffer)
+		ww := &WrapWriter{WC: output}
+
+		ensureWrite(t, ww, "0123456789\n")
+		ensureErrorNil(t, ww.Close())
+		ensureStringer(t, output, "0123456789\n")
+	})
+}
@@ -0,0 +1,124 @@
+package gonl
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"time"
+)
+
+// ErrWriteTimeout is returned when a Write to the underlying
+// io.WriteCloser does not complete within the WriteTimeout of a
+// BatchLineWriter. It wraps os.ErrDeadlineExceeded.
+var ErrWriteTimeout = fmt.Errorf("gonl: write timed out: %w", os.ErrDeadlineExceeded)
+
+// writeDeadliner is implemented by writers whose Write calls may be
+// bounded by setting a deadline, such as net.Conn.
+type writeDeadliner interface {
+	SetWriteDeadline(t time.Time) error
+}
+
+// wcWrite writes p to the underlying io.WriteCloser, retrying
+// according to Retry, with each attempt bounded by WriteTimeout when
+// it is set.
+func (lw *BatchLineWriter) wcWrite(p []byte) (int, error) {
+	var total int
+	for attempt := 0; ; attempt++ {
+		nw, err := lw.wcWriteOnce(p[total:])
+		if nw < 0 {
+			return nw, err
+		}
+		total += nw
+		if err == nil || !lw.retry(attempt, err) {
+			return total, lw.syncOnFlush(err)
+		}
+	}
+}
+
+// wcWriteOnce writes p to the underlying io.WriteCloser, bounded by
+// WriteTimeout when it is set.
+func (lw *BatchLineWriter) wcWriteOnce(p []byte) (int, error) {
+	if lw.WriteTimeout <= 0 {
+		return lw.wc.Write(p)
+	}
+	wc := lw.wc // the closure may outlive the BatchLineWriter using wc
+	nw, err := lw.timedWrite(func() (int64, error) {
+		nw, err := wc.Write(p)
+		return int64(nw), err
+	})
+	return int(nw), err
+}
+
+// wcWriteBuffers writes bufs to the underlying io.WriteCloser with a
+// single call, retrying according to Retry, with each attempt bounded
+// by WriteTimeout when it is set.
+func (lw *BatchLineWriter) wcWriteBuffers(bufs net.Buffers) (int64, error) {
+	var total int64
+	for attempt := 0; ; attempt++ {
+		nw, err := lw.wcWriteBuffersOnce(bufs)
+		if nw < 0 {
+			return nw, err
+		}
+		total += nw
+		if err == nil || !lw.retry(attempt, err) {
+			return total, lw.syncOnFlush(err)
+		}
+		bufs = advanceBuffers(bufs, nw)
+	}
+}
+
+// wcWriteBuffersOnce writes bufs to the underlying io.WriteCloser with
+// a single call, bounded by WriteTimeout when it is set.
+func (lw *BatchLineWriter) wcWriteBuffersOnce(bufs net.Buffers) (int64, error) {
+	if lw.WriteTimeout <= 0 {
+		return lw.writeBuffers(bufs)
+	}
+	writeBuffers := lw.writeBuffers
+	bufs = append(net.Buffers(nil), bufs...) // bufs refers to lw.vectors
+	return lw.timedWrite(func() (int64, error) { return writeBuffers(bufs) })
+}
+
+// timedWrite invokes write, returning ErrWriteTimeout when it does not
+// complete within WriteTimeout. When the underlying io.WriteCloser
+// supports write deadlines, the deadline aborts the write. Otherwise a
+// watchdog stops waiting for it, but because the abandoned write may
+// still be using the buffer, the BatchLineWriter is marked as stalled
+// and all later writes to the underlying io.WriteCloser fail with
+// ErrWriteTimeout.
+func (lw *BatchLineWriter) timedWrite(write func() (int64, error)) (int64, error) {
+	if lw.stalled {
+		return 0, ErrWriteTimeout
+	}
+
+	if wd, ok := underlying(lw.wc).(writeDeadliner); ok {
+		if err := wd.SetWriteDeadline(time.Now().Add(lw.WriteTimeout)); err == nil {
+			nw, err := write()
+			_ = wd.SetWriteDeadline(time.Time{})
+			if errors.Is(err, os.ErrDeadlineExceeded) {
+				err = ErrWriteTimeout
+			}
+			return nw, err
+		}
+	}
+
+	type result struct {
+		nw  int64
+		err error
+	}
+	results := make(chan result, 1)
+	go func() {
+		nw, err := write()
+		results <- result{nw, err}
+	}()
+
+	timer := time.NewTimer(lw.WriteTimeout)
+	defer timer.Stop()
+	select {
+	case r := <-results:
+		return r.nw, r.err
+	case <-timer.C:
+		lw.stalled = true
+		return 0, ErrWriteTimeout
+	}
+}
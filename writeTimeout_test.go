@@ -0,0 +1,71 @@
+package gonl
+
+import (
+	"errors"
+	"net"
+	"os"
+	"testing"
+	"time"
+)
+
+// blockingWriter blocks each Write until release is closed.
+type blockingWriter struct {
+	release chan struct{}
+}
+
+func (bw *blockingWriter) Close() error { return nil }
+
+func (bw *blockingWriter) Write(p []byte) (int, error) {
+	<-bw.release
+	return len(p), nil
+}
+
+func TestWriteTimeout(t *testing.T) {
+	t.Run("watchdog", func(t *testing.T) {
+		output := &blockingWriter{release: make(chan struct{})}
+		defer close(output.release)
+		lw, err := NewBatchLineWriterOptions(output, WithFlushThreshold(1), WithWriteTimeout(10*time.Millisecond))
+		ensureErrorNil(t, err)
+
+		_, err = lw.Write([]byte("line 1\n"))
+		ensureError(t, err, ErrWriteTimeout.Error())
+		if !errors.Is(err, os.ErrDeadlineExceeded) {
+			t.Errorf("GOT: %v; WANT: %v", err, os.ErrDeadlineExceeded)
+		}
+
+		// The abandoned write may still be in progress.
+		_, err = lw.Write([]byte("line 2\n"))
+		ensureError(t, err, ErrWriteTimeout.Error())
+		ensureErrorNil(t, lw.Close())
+	})
+
+	t.Run("write deadline", func(t *testing.T) {
+		client, server := net.Pipe()
+		defer client.Close()
+		defer server.Close()
+
+		lw, err := NewBatchLineWriterOptions(client, WithFlushThreshold(1), WithWriteTimeout(10*time.Millisecond))
+		ensureErrorNil(t, err)
+
+		// Nothing reads from the other end of the pipe.
+		_, err = lw.Write([]byte("line 1\n"))
+		ensureError(t, err, ErrWriteTimeout.Error())
+
+		// A deadline aborts the write, so later writes may succeed.
+		go func() {
+			buf := make([]byte, 64)
+			_, _ = server.Read(buf)
+		}()
+		ensureWrite(t, lw, "line 2\n")
+	})
+
+	t.Run("completes in time", func(t *testing.T) {
+		output := new(testBuffer)
+		lw, err := NewBatchLineWriterOptions(output, WithFlushThreshold(1), WithWriteTimeout(time.Minute))
+		ensureErrorNil(t, err)
+
+		ensureWrite(t, lw, "line 1\n")
+		ensureStringer(t, output, "line 1\n")
+		ensureErrorNil(t, lw.Close())
+	})
+}
@@ -7,22 +7,6 @@ import (
 	"io"
 )
 
-// NopCloseWriter returns a structure that implements io.WriteCloser,
-// but provides a no-op Close method.  It is useful when you have an
-// io.Writer that needs to be passed to a method that requires an
-// io.WriteCloser.  It is the counter-part to ioutil.NopCloser, but
-// for io.Writer.
-//
-//   wc := gonl.NopCloseWriter(w)
-//   _ = wc.Close() // does nothing; always returns nil
-func NopCloseWriter(wc io.Writer) io.WriteCloser {
-	return nopCloseWriter{wc}
-}
-
-func (nopCloseWriter) Close() error { return nil }
-
-type nopCloseWriter struct{ io.Writer }
-
 // ShortWriter returns a structure that wraps an io.Writer, but
 // returns io.ErrShortWrite when the number of bytes to write exceeds
 // a preset limit.